@@ -0,0 +1,36 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+// InvalidArgumentsError is returned by tools when the arguments in JSON can't be parsed,
+// so that the caller, e.g. ToolsNode, can tell it from the errors of the tool execution,
+// and let the ChatModel correct the arguments.
+// The tools created by components/tool/utils return this error when unmarshalling the arguments fails.
+type InvalidArgumentsError struct {
+	Err error
+}
+
+func (e *InvalidArgumentsError) Error() string {
+	if e.Err == nil {
+		return "invalid tool arguments"
+	}
+	return e.Err.Error()
+}
+
+func (e *InvalidArgumentsError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mcp adapts the tools of Model Context Protocol (MCP) servers to eino tools.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/internal/mcp"
+)
+
+const (
+	defaultClientName    = "eino"
+	defaultClientVersion = "v0.0.1"
+)
+
+// ClientConfig is the config for the MCP client.
+type ClientConfig struct {
+	// Transport connects the client to the MCP server, created by NewStdioTransport, NewIOTransport or NewHTTPTransport.
+	Transport Transport
+	// Name and Version identify the client to the server.
+	// Optional. Default is "eino".
+	Name    string
+	Version string
+}
+
+// Client is a session with an MCP server.
+type Client struct {
+	transport Transport
+	nextID    int64
+
+	serverInfo   mcp.Implementation
+	instructions string
+}
+
+// NewClient creates an MCP client and runs the initialize handshake with the server.
+// e.g.
+//
+//	transport, err := mcp.NewStdioTransport(exec.Command("npx", "-y", "@modelcontextprotocol/server-filesystem", "/tmp"))
+//	if err != nil {...}
+//	cli, err := mcp.NewClient(ctx, &mcp.ClientConfig{Transport: transport})
+//	if err != nil {...}
+//	defer cli.Close()
+//	tools, err := mcp.GetTools(ctx, &mcp.Config{Client: cli})
+func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
+	if config == nil || config.Transport == nil {
+		return nil, fmt.Errorf("mcp client transport is nil")
+	}
+
+	c := &Client{
+		transport: config.Transport,
+	}
+
+	name, version := config.Name, config.Version
+	if len(name) == 0 {
+		name = defaultClientName
+	}
+	if len(version) == 0 {
+		version = defaultClientVersion
+	}
+
+	result := &mcp.InitializeResult{}
+	err := c.call(ctx, mcp.MethodInitialize, &mcp.InitializeParams{
+		ProtocolVersion: mcp.ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      mcp.Implementation{Name: name, Version: version},
+	}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mcp session: %w", err)
+	}
+
+	c.serverInfo = result.ServerInfo
+	c.instructions = result.Instructions
+
+	if err = c.notify(ctx, mcp.NotifyInitialized, nil); err != nil {
+		return nil, fmt.Errorf("failed to notify mcp server of initialization: %w", err)
+	}
+
+	return c, nil
+}
+
+// ServerName returns the name of the connected server.
+func (c *Client) ServerName() string {
+	return c.serverInfo.Name
+}
+
+// Instructions returns the usage instructions the server sent during initialization, if any.
+func (c *Client) Instructions() string {
+	return c.instructions
+}
+
+// Close closes the session and its transport.
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+func (c *Client) listTools(ctx context.Context) ([]*mcp.Tool, error) {
+	var (
+		tools  []*mcp.Tool
+		cursor string
+	)
+
+	for {
+		result := &mcp.ListToolsResult{}
+		if err := c.call(ctx, mcp.MethodToolsList, &mcp.ListToolsParams{Cursor: cursor}, result); err != nil {
+			return nil, err
+		}
+
+		tools = append(tools, result.Tools...)
+		if len(result.NextCursor) == 0 || result.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+func (c *Client) callTool(ctx context.Context, name string, arguments []byte) (*mcp.CallToolResult, error) {
+	result := &mcp.CallToolResult{}
+	if err := c.call(ctx, mcp.MethodToolsCall, &mcp.CallToolParams{Name: name, Arguments: arguments}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	req := &mcp.Message{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      []byte(strconv.FormatInt(atomic.AddInt64(&c.nextID, 1), 10)),
+		Method:  method,
+	}
+
+	if params != nil {
+		data, err := sonic.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params of %s: %w", method, err)
+		}
+		req.Params = data
+	}
+
+	data, err := sonic.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request of %s: %w", method, err)
+	}
+
+	respData, err := c.transport.Call(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	resp := &mcp.Message{}
+	if err = sonic.Unmarshal(respData, resp); err != nil {
+		return fmt.Errorf("failed to unmarshal response of %s: %w", method, err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("mcp server failed to handle %s: %w", method, resp.Error)
+	}
+
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+
+	if err = sonic.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal result of %s: %w", method, err)
+	}
+
+	return nil
+}
+
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	msg := &mcp.Message{
+		JSONRPC: mcp.JSONRPCVersion,
+		Method:  method,
+	}
+
+	if params != nil {
+		data, err := sonic.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = data
+	}
+
+	data, err := sonic.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return c.transport.Notify(ctx, data)
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/internal/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Config is the config for GetTools.
+type Config struct {
+	// Client is the session with the MCP server.
+	Client *Client
+	// ToolNameList only keeps the tools with these names.
+	// Optional. By default, all tools of the server are returned.
+	ToolNameList []string
+}
+
+// ToolError is returned by the tools when the MCP server reports the tool call as failed,
+// Content is the text the server sent to describe the failure.
+type ToolError struct {
+	ToolName string
+	Content  string
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("mcp tool %s returned error: %s", e.ToolName, e.Content)
+}
+
+// GetTools lists the tools of the MCP server, and converts each of them to a tool.InvokableTool,
+// which can be used in compose.ToolsNodeConfig directly.
+// The ToolInfo of each tool is built from the input JSON schema of the server.
+func GetTools(ctx context.Context, config *Config) ([]tool.BaseTool, error) {
+	if config == nil || config.Client == nil {
+		return nil, errors.New("mcp client is nil")
+	}
+
+	mcpTools, err := config.Client.listTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mcp tools: %w", err)
+	}
+
+	var wanted map[string]bool
+	if len(config.ToolNameList) > 0 {
+		wanted = make(map[string]bool, len(config.ToolNameList))
+		for _, name := range config.ToolNameList {
+			wanted[name] = true
+		}
+	}
+
+	ret := make([]tool.BaseTool, 0, len(mcpTools))
+	for _, t := range mcpTools {
+		if t == nil || (wanted != nil && !wanted[t.Name]) {
+			continue
+		}
+
+		info, err := toToolInfo(t)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, &mcpTool{cli: config.Client, info: info})
+	}
+
+	return ret, nil
+}
+
+func toToolInfo(t *mcp.Tool) (*schema.ToolInfo, error) {
+	info := &schema.ToolInfo{
+		Name: t.Name,
+		Desc: t.Description,
+	}
+
+	if len(t.InputSchema) == 0 || string(t.InputSchema) == "null" {
+		return info, nil
+	}
+
+	sc := &openapi3.Schema{}
+	if err := sonic.Unmarshal(t.InputSchema, sc); err != nil {
+		return nil, fmt.Errorf("failed to convert input schema of mcp tool %s: %w", t.Name, err)
+	}
+	info.ParamsOneOf = schema.NewParamsOneOfByOpenAPIV3(sc)
+
+	return info, nil
+}
+
+type mcpTool struct {
+	cli  *Client
+	info *schema.ToolInfo
+}
+
+func (m *mcpTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return m.info, nil
+}
+
+func (m *mcpTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var arguments []byte
+	if len(strings.TrimSpace(argumentsInJSON)) > 0 {
+		arguments = []byte(argumentsInJSON)
+	}
+
+	result, err := m.cli.callTool(ctx, m.info.Name, arguments)
+	if err != nil {
+		return "", fmt.Errorf("failed to call mcp tool %s: %w", m.info.Name, err)
+	}
+
+	content, err := contentToString(result.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert result of mcp tool %s: %w", m.info.Name, err)
+	}
+
+	if result.IsError {
+		return "", &ToolError{ToolName: m.info.Name, Content: content}
+	}
+
+	return content, nil
+}
+
+func (m *mcpTool) GetType() string {
+	return "MCP"
+}
+
+// contentToString joins the text blocks of the result, a result with other kinds of blocks is marshaled as a whole.
+func contentToString(content []*mcp.Content) (string, error) {
+	texts := make([]string, 0, len(content))
+	for _, c := range content {
+		if c == nil {
+			continue
+		}
+		if c.Type != "text" {
+			data, err := sonic.MarshalString(content)
+			if err != nil {
+				return "", err
+			}
+			return data, nil
+		}
+		texts = append(texts, c.Text)
+	}
+
+	return strings.Join(texts, "\n"), nil
+}
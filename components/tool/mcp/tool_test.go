@@ -0,0 +1,226 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/internal/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeServer is an in-process stand-in of an MCP server with two tools, 'echo' and 'fail'.
+type fakeServer struct {
+	initialized bool
+}
+
+func (s *fakeServer) handle(msg *mcp.Message) *mcp.Message {
+	if msg.IsNotification() {
+		if msg.Method == mcp.NotifyInitialized {
+			s.initialized = true
+		}
+		return nil
+	}
+
+	resp := &mcp.Message{JSONRPC: mcp.JSONRPCVersion, ID: msg.ID}
+	var result any
+	switch msg.Method {
+	case mcp.MethodInitialize:
+		result = &mcp.InitializeResult{
+			ProtocolVersion: mcp.ProtocolVersion,
+			ServerInfo:      mcp.Implementation{Name: "fake", Version: "1"},
+			Instructions:    "be careful",
+		}
+	case mcp.MethodToolsList:
+		params := &mcp.ListToolsParams{}
+		_ = sonic.Unmarshal(msg.Params, params)
+		if params.Cursor == "" {
+			result = &mcp.ListToolsResult{
+				Tools: []*mcp.Tool{{
+					Name:        "echo",
+					Description: "echo the text",
+					InputSchema: []byte(`{"type":"object","properties":{"text":{"type":"string","description":"text to echo"}},"required":["text"]}`),
+				}},
+				NextCursor: "page2",
+			}
+		} else {
+			result = &mcp.ListToolsResult{
+				Tools: []*mcp.Tool{{Name: "fail", InputSchema: []byte(`{"type":"object"}`)}},
+			}
+		}
+	case mcp.MethodToolsCall:
+		params := &mcp.CallToolParams{}
+		_ = sonic.Unmarshal(msg.Params, params)
+		if params.Name == "fail" {
+			result = &mcp.CallToolResult{IsError: true, Content: []*mcp.Content{{Type: "text", Text: "boom"}}}
+			break
+		}
+		args := map[string]string{}
+		_ = sonic.Unmarshal(params.Arguments, &args)
+		result = &mcp.CallToolResult{Content: []*mcp.Content{{Type: "text", Text: "echo: " + args["text"]}}}
+	default:
+		resp.Error = &mcp.Error{Code: mcp.CodeMethodNotFound, Message: "not found"}
+		return resp
+	}
+
+	resp.Result, _ = sonic.Marshal(result)
+	return resp
+}
+
+func (s *fakeServer) serveIO(r io.Reader, w io.WriteCloser) {
+	defer w.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		msg := &mcp.Message{}
+		if err := sonic.Unmarshal(scanner.Bytes(), msg); err != nil {
+			continue
+		}
+		if resp := s.handle(msg); resp != nil {
+			data, _ := sonic.Marshal(resp)
+			_, _ = w.Write(append(data, '\n'))
+		}
+	}
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	msg := &mcp.Message{}
+	body, _ := io.ReadAll(r.Body)
+	if err := sonic.Unmarshal(body, msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	resp := s.handle(msg)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	data, _ := sonic.Marshal(resp)
+	if msg.Method == mcp.MethodInitialize {
+		w.Header().Set(mcp.SessionIDHeader, "session-1")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+		return
+	}
+
+	if r.Header.Get(mcp.SessionIDHeader) != "session-1" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// answer in event stream, with a progress notification ahead of the response
+	w.Header().Set("Content-Type", "text/event-stream")
+	_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":1,"progress":1}}`)
+	_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+}
+
+func testTools(t *testing.T, cli *Client) {
+	ctx := context.Background()
+
+	tools, err := GetTools(ctx, &Config{Client: cli})
+	assert.NoError(t, err)
+	assert.Len(t, tools, 2)
+
+	info, err := tools[0].Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "echo", info.Name)
+	assert.Equal(t, "echo the text", info.Desc)
+	sc, err := info.ToOpenAPIV3()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"text"}, sc.Required)
+	assert.Equal(t, "text to echo", sc.Properties["text"].Value.Description)
+
+	out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"text":"hi"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: hi", out)
+
+	_, err = tools[1].(tool.InvokableTool).InvokableRun(ctx, `{}`)
+	var toolErr *ToolError
+	assert.ErrorAs(t, err, &toolErr)
+	assert.Equal(t, "boom", toolErr.Content)
+
+	filtered, err := GetTools(ctx, &Config{Client: cli, ToolNameList: []string{"fail"}})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+
+	// plugs into ToolsNode
+	tn, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: tools})
+	assert.NoError(t, err)
+	msgs, err := tn.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "1",
+		Function: schema.FunctionCall{Name: "echo", Arguments: `{"text":"node"}`},
+	}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: node", msgs[0].Content)
+}
+
+func TestIOTransport(t *testing.T) {
+	ctx := context.Background()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	srv := &fakeServer{}
+	go srv.serveIO(serverR, serverW)
+
+	cli, err := NewClient(ctx, &ClientConfig{Transport: NewIOTransport(clientR, clientW)})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", cli.ServerName())
+	assert.Equal(t, "be careful", cli.Instructions())
+
+	testTools(t, cli)
+	assert.True(t, srv.initialized)
+
+	assert.NoError(t, cli.Close())
+	_, err = GetTools(ctx, &Config{Client: cli})
+	assert.Error(t, err)
+}
+
+func TestHTTPTransport(t *testing.T) {
+	ctx := context.Background()
+
+	srv := &fakeServer{}
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	transport, err := NewHTTPTransport(&HTTPTransportConfig{Endpoint: httpSrv.URL})
+	assert.NoError(t, err)
+
+	cli, err := NewClient(ctx, &ClientConfig{Transport: transport})
+	assert.NoError(t, err)
+
+	testTools(t, cli)
+	assert.True(t, srv.initialized)
+	assert.NoError(t, cli.Close())
+}
@@ -0,0 +1,414 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/internal/mcp"
+)
+
+// Transport carries JSON-RPC messages between the client and an MCP server.
+type Transport interface {
+	// Call sends a JSON-RPC request and returns the matching JSON-RPC response.
+	Call(ctx context.Context, request []byte) (response []byte, err error)
+	// Notify sends a JSON-RPC notification, which has no response.
+	Notify(ctx context.Context, notification []byte) error
+	// Close releases the resources of the transport.
+	Close() error
+}
+
+// ErrTransportClosed is returned when calling a closed transport.
+var ErrTransportClosed = errors.New("mcp transport is closed")
+
+// NewStdioTransport starts the command of a local MCP server and talks to it over its stdin and stdout.
+// The command must not have been started, and its Stdin and Stdout must not be set.
+// Close closes stdin of the server and waits for the command to exit.
+func NewStdioTransport(cmd *exec.Cmd) (Transport, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdin pipe of mcp server: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout pipe of mcp server: %w", err)
+	}
+
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start mcp server: %w", err)
+	}
+
+	t := newIOTransport(stdout, stdin)
+	t.onClose = cmd.Wait
+
+	return t, nil
+}
+
+// NewIOTransport talks to an MCP server with newline delimited JSON-RPC messages,
+// reading from r and writing to w, e.g. the two ends of io.Pipe for an in-process server.
+// Close closes w.
+func NewIOTransport(r io.Reader, w io.WriteCloser) Transport {
+	return newIOTransport(r, w)
+}
+
+func newIOTransport(r io.Reader, w io.WriteCloser) *ioTransport {
+	t := &ioTransport{
+		w:       w,
+		pending: make(map[string]chan []byte),
+		done:    make(chan struct{}),
+	}
+
+	go t.readLoop(r)
+
+	return t
+}
+
+type ioTransport struct {
+	writeMu sync.Mutex
+	w       io.WriteCloser
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	readErr error
+	closed  bool
+
+	done    chan struct{}
+	onClose func() error
+}
+
+func (t *ioTransport) Call(ctx context.Context, request []byte) ([]byte, error) {
+	msg := &mcp.Message{}
+	if err := sonic.Unmarshal(request, msg); err != nil {
+		return nil, fmt.Errorf("invalid jsonrpc request: %w", err)
+	}
+	id := string(msg.ID)
+
+	ch := make(chan []byte, 1)
+	t.mu.Lock()
+	if t.closed || t.readErr != nil {
+		err := t.readErr
+		t.mu.Unlock()
+		if err == nil {
+			err = ErrTransportClosed
+		}
+		return nil, err
+	}
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-t.done:
+		t.mu.Lock()
+		err := t.readErr
+		t.mu.Unlock()
+		if err == nil {
+			err = ErrTransportClosed
+		}
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *ioTransport) Notify(_ context.Context, notification []byte) error {
+	return t.write(notification)
+}
+
+func (t *ioTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	err := t.w.Close()
+	if t.onClose != nil {
+		if wErr := t.onClose(); wErr != nil && err == nil {
+			err = wErr
+		}
+	}
+
+	return err
+}
+
+func (t *ioTransport) write(data []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if _, err := t.w.Write(append(bytes.TrimSpace(data), '\n')); err != nil {
+		return fmt.Errorf("failed to write to mcp server: %w", err)
+	}
+
+	return nil
+}
+
+func (t *ioTransport) readLoop(r io.Reader) {
+	reader := bufio.NewReader(r)
+
+	var err error
+	for {
+		var line []byte
+		line, err = reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			t.dispatch(bytes.TrimSpace(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if err == io.EOF {
+		err = ErrTransportClosed
+	}
+
+	t.mu.Lock()
+	t.readErr = err
+	t.mu.Unlock()
+	close(t.done)
+}
+
+func (t *ioTransport) dispatch(data []byte) {
+	msg := &mcp.Message{}
+	if err := sonic.Unmarshal(data, msg); err != nil {
+		return // not a jsonrpc message, e.g. a log line of the server
+	}
+
+	if msg.IsRequest() {
+		// requests from server, only ping is supported
+		resp := &mcp.Message{JSONRPC: mcp.JSONRPCVersion, ID: msg.ID}
+		if msg.Method == mcp.MethodPing {
+			resp.Result = []byte("{}")
+		} else {
+			resp.Error = &mcp.Error{Code: mcp.CodeMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+		if out, err := sonic.Marshal(resp); err == nil {
+			_ = t.write(out)
+		}
+		return
+	}
+
+	if !msg.IsResponse() {
+		return // notifications are ignored
+	}
+
+	t.mu.Lock()
+	ch, ok := t.pending[string(msg.ID)]
+	t.mu.Unlock()
+	if ok {
+		ch <- data
+	}
+}
+
+// HTTPTransportConfig is the config for the streamable HTTP transport.
+type HTTPTransportConfig struct {
+	// Endpoint is the MCP endpoint of the server, e.g. "https://example.com/mcp".
+	Endpoint string
+	// Client is the http client to send requests.
+	// Optional. Default is http.DefaultClient.
+	Client *http.Client
+	// Header is added to every request, e.g. for authorization.
+	// Optional.
+	Header http.Header
+}
+
+// NewHTTPTransport creates a transport talking to a remote MCP server over the streamable HTTP transport.
+// Every message is POSTed to the endpoint, and the response is read from either a JSON body or a Server-Sent Events stream.
+func NewHTTPTransport(config *HTTPTransportConfig) (Transport, error) {
+	if config == nil || len(config.Endpoint) == 0 {
+		return nil, errors.New("mcp http transport endpoint is empty")
+	}
+
+	cli := config.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+
+	return &httpTransport{
+		endpoint: config.Endpoint,
+		cli:      cli,
+		header:   config.Header,
+	}, nil
+}
+
+type httpTransport struct {
+	endpoint string
+	cli      *http.Client
+	header   http.Header
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func (t *httpTransport) Call(ctx context.Context, request []byte) ([]byte, error) {
+	msg := &mcp.Message{}
+	if err := sonic.Unmarshal(request, msg); err != nil {
+		return nil, fmt.Errorf("invalid jsonrpc request: %w", err)
+	}
+
+	resp, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return io.ReadAll(resp.Body)
+	}
+
+	// read events until the response of the request
+	return readSSEResponse(resp.Body, string(msg.ID))
+}
+
+func (t *httpTransport) Notify(ctx context.Context, notification []byte) error {
+	resp, err := t.post(ctx, notification)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+func (t *httpTransport) Close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if len(sessionID) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeader(req)
+
+	resp, err := t.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (t *httpTransport) post(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeader(req)
+
+	resp, err := t.cli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post to mcp server: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("mcp server responded with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	if sessionID := resp.Header.Get(mcp.SessionIDHeader); len(sessionID) > 0 {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+func (t *httpTransport) setHeader(req *http.Request) {
+	for k, vs := range t.header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	t.mu.Lock()
+	if len(t.sessionID) > 0 {
+		req.Header.Set(mcp.SessionIDHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+}
+
+func readSSEResponse(r io.Reader, id string) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var data bytes.Buffer
+	flush := func() []byte {
+		defer data.Reset()
+		if data.Len() == 0 {
+			return nil
+		}
+		msg := &mcp.Message{}
+		if err := sonic.Unmarshal(data.Bytes(), msg); err != nil || !msg.IsResponse() || string(msg.ID) != id {
+			return nil
+		}
+		return append([]byte(nil), data.Bytes()...)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			if resp := flush(); resp != nil {
+				return resp, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "data:") {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event stream of mcp server: %w", err)
+	}
+	if resp := flush(); resp != nil {
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("mcp server closed the event stream without responding to request %s", id)
+}
@@ -0,0 +1,485 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openapi generates tools from OpenAPI 3 documents, one tool.InvokableTool per operation.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// BodyParamName is the name of the tool argument which carries the request body of the operation.
+	BodyParamName = "body"
+
+	defaultMaxResponseBytes = 1 << 20
+)
+
+// ErrResponseTooLarge is returned when the response body exceeds Config.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("openapi tool response too large")
+
+// Config is the config for NewTools.
+type Config struct {
+	// Doc is the parsed OpenAPI 3 document.
+	// Either Doc or Spec is required.
+	Doc *openapi3.T
+	// Spec is the raw OpenAPI 3 document, in JSON or YAML.
+	Spec []byte
+
+	// BaseURL is the URL the operation paths are appended to.
+	// Optional. By default, the first server of the document is used, with the server variables set to their defaults.
+	BaseURL string
+	// OperationIDs only keeps the operations with these operation ids.
+	// Optional. By default, all operations are converted.
+	OperationIDs []string
+
+	// Client sends the requests.
+	// Optional. By default, http.DefaultClient is used.
+	Client *http.Client
+	// Auth is called on every request before it is sent, e.g. to inject the authorization header.
+	// Optional.
+	Auth func(ctx context.Context, req *http.Request) error
+	// MaxResponseBytes is the max size of the response body, ErrResponseTooLarge is returned when exceeded.
+	// Optional. Default is 1MB.
+	MaxResponseBytes int64
+	// StatusToError maps the response to an error, returning nil means the body is the result of the tool.
+	// Optional. By default, a *StatusError is returned for any status code >= 400.
+	StatusToError func(ctx context.Context, toolName string, statusCode int, body []byte) error
+}
+
+// StatusError is the default error for the responses with status code >= 400.
+type StatusError struct {
+	ToolName   string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("openapi tool %s got status code %d: %s", e.ToolName, e.StatusCode, e.Body)
+}
+
+// NewTools loads the OpenAPI document, and converts each operation to a tool.InvokableTool.
+// The tool is named after the operation id, or the method and path if there is no operation id.
+// The path, query and header parameters of the operation become the top level arguments of the tool,
+// while the JSON request body, if any, is passed in the argument named BodyParamName.
+func NewTools(ctx context.Context, config *Config) ([]tool.InvokableTool, error) {
+	if config == nil {
+		return nil, errors.New("openapi tool config is nil")
+	}
+
+	doc := config.Doc
+	if doc == nil {
+		if len(config.Spec) == 0 {
+			return nil, errors.New("openapi document is required")
+		}
+
+		loader := openapi3.NewLoader()
+		loader.Context = ctx
+		var err error
+		doc, err = loader.LoadFromData(config.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load openapi document: %w", err)
+		}
+	}
+
+	baseURL, err := getBaseURL(doc, config.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var wanted map[string]bool
+	if len(config.OperationIDs) > 0 {
+		wanted = make(map[string]bool, len(config.OperationIDs))
+		for _, id := range config.OperationIDs {
+			wanted[id] = true
+		}
+	}
+
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxResponseBytes := config.MaxResponseBytes
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = defaultMaxResponseBytes
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ret []tool.InvokableTool
+	names := make(map[string]bool)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+
+		ops := item.Operations()
+		methods := make([]string, 0, len(ops))
+		for method := range ops {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := ops[method]
+			if wanted != nil && !wanted[op.OperationID] {
+				continue
+			}
+
+			t, err := newOperationTool(path, method, item.Parameters, op)
+			if err != nil {
+				return nil, err
+			}
+			if names[t.info.Name] {
+				return nil, fmt.Errorf("duplicate openapi tool name: %s", t.info.Name)
+			}
+			names[t.info.Name] = true
+
+			t.baseURL = baseURL
+			t.client = client
+			t.auth = config.Auth
+			t.maxResponseBytes = maxResponseBytes
+			t.statusToError = config.StatusToError
+
+			ret = append(ret, t)
+		}
+	}
+
+	return ret, nil
+}
+
+func getBaseURL(doc *openapi3.T, baseURL string) (string, error) {
+	if baseURL == "" {
+		if len(doc.Servers) == 0 || doc.Servers[0] == nil {
+			return "", errors.New("base url is required when there is no server in openapi document")
+		}
+
+		server := doc.Servers[0]
+		baseURL = server.URL
+		for name, v := range server.Variables {
+			if v != nil {
+				baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", v.Default)
+			}
+		}
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %s: %w", baseURL, err)
+	}
+	if !u.IsAbs() {
+		return "", fmt.Errorf("base url must be absolute: %s", baseURL)
+	}
+
+	return strings.TrimSuffix(baseURL, "/"), nil
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+type operationTool struct {
+	info *schema.ToolInfo
+
+	method string
+	path   string
+	params []*openapi3.Parameter
+	body   bool
+
+	baseURL          string
+	client           *http.Client
+	auth             func(ctx context.Context, req *http.Request) error
+	maxResponseBytes int64
+	statusToError    func(ctx context.Context, toolName string, statusCode int, body []byte) error
+}
+
+func newOperationTool(path, method string, pathParams openapi3.Parameters, op *openapi3.Operation) (*operationTool, error) {
+	name := op.OperationID
+	if name == "" {
+		name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(method)+path, "_"), "_")
+	}
+
+	desc := op.Summary
+	if op.Description != "" {
+		if desc != "" {
+			desc += "\n"
+		}
+		desc += op.Description
+	}
+
+	t := &operationTool{
+		method: method,
+		path:   path,
+	}
+
+	sc := openapi3.NewObjectSchema()
+
+	// the parameters of the operation override the ones of the path item with the same name and location
+	params := make(map[string]*openapi3.Parameter)
+	var order []string
+	for _, ps := range []openapi3.Parameters{pathParams, op.Parameters} {
+		for _, ref := range ps {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			p := ref.Value
+			if p.In == openapi3.ParameterInCookie {
+				continue
+			}
+			key := p.In + ":" + p.Name
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = p
+		}
+	}
+
+	for _, key := range order {
+		p := params[key]
+		if _, ok := sc.Properties[p.Name]; ok || p.Name == BodyParamName {
+			return nil, fmt.Errorf("conflicting parameter name %s in openapi operation %s", p.Name, name)
+		}
+
+		sc.Properties[p.Name] = &openapi3.SchemaRef{Value: paramSchema(p)}
+		if p.Required || p.In == openapi3.ParameterInPath {
+			sc.Required = append(sc.Required, p.Name)
+		}
+		t.params = append(t.params, p)
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		rb := op.RequestBody.Value
+		mt := jsonMediaType(rb.Content)
+		if mt == nil {
+			return nil, fmt.Errorf("openapi operation %s only supports json request body", name)
+		}
+
+		bodySchema := openapi3.NewObjectSchema()
+		if mt.Schema != nil && mt.Schema.Value != nil {
+			copied := *mt.Schema.Value
+			bodySchema = &copied
+		}
+		if bodySchema.Description == "" {
+			bodySchema.Description = rb.Description
+		}
+
+		sc.Properties[BodyParamName] = &openapi3.SchemaRef{Value: bodySchema}
+		if rb.Required {
+			sc.Required = append(sc.Required, BodyParamName)
+		}
+		t.body = true
+	}
+
+	t.info = &schema.ToolInfo{
+		Name:        name,
+		Desc:        desc,
+		ParamsOneOf: schema.NewParamsOneOfByOpenAPIV3(sc),
+	}
+
+	return t, nil
+}
+
+func paramSchema(p *openapi3.Parameter) *openapi3.Schema {
+	var sc *openapi3.Schema
+	if p.Schema != nil && p.Schema.Value != nil {
+		copied := *p.Schema.Value
+		sc = &copied
+	} else if mt := jsonMediaType(p.Content); mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
+		copied := *mt.Schema.Value
+		sc = &copied
+	} else {
+		sc = openapi3.NewStringSchema()
+	}
+
+	if sc.Description == "" {
+		sc.Description = p.Description
+	}
+
+	return sc
+}
+
+func jsonMediaType(content openapi3.Content) *openapi3.MediaType {
+	if mt := content.Get("application/json"); mt != nil {
+		return mt
+	}
+
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		if strings.HasSuffix(t, "+json") {
+			return content[t]
+		}
+	}
+
+	return nil
+}
+
+func (o *operationTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return o.info, nil
+}
+
+func (o *operationTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	args := make(map[string]any)
+	if len(strings.TrimSpace(argumentsInJSON)) > 0 {
+		if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+			return "", &tool.InvalidArgumentsError{Err: fmt.Errorf("failed to unmarshal arguments of openapi tool %s: %w", o.info.Name, err)}
+		}
+	}
+
+	req, err := o.buildRequest(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	if o.auth != nil {
+		if err = o.auth(ctx, req); err != nil {
+			return "", fmt.Errorf("failed to authorize request of openapi tool %s: %w", o.info.Name, err)
+		}
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request of openapi tool %s: %w", o.info.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, o.maxResponseBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response of openapi tool %s: %w", o.info.Name, err)
+	}
+	if int64(len(body)) > o.maxResponseBytes {
+		return "", fmt.Errorf("%w: openapi tool %s, limit=%d bytes", ErrResponseTooLarge, o.info.Name, o.maxResponseBytes)
+	}
+
+	if o.statusToError != nil {
+		err = o.statusToError(ctx, o.info.Name, resp.StatusCode, body)
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		err = &StatusError{ToolName: o.info.Name, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func (o *operationTool) buildRequest(ctx context.Context, args map[string]any) (*http.Request, error) {
+	path := o.path
+	query := url.Values{}
+	header := http.Header{}
+
+	for _, p := range o.params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Required || p.In == openapi3.ParameterInPath {
+				return nil, fmt.Errorf("missing required parameter %s of openapi tool %s", p.Name, o.info.Name)
+			}
+			continue
+		}
+
+		switch p.In {
+		case openapi3.ParameterInPath:
+			s, err := formatValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter %s of openapi tool %s: %w", p.Name, o.info.Name, err)
+			}
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(s))
+		case openapi3.ParameterInQuery, openapi3.ParameterInHeader:
+			values := []any{v}
+			if vs, isSlice := v.([]any); isSlice {
+				values = vs
+			}
+			for _, item := range values {
+				s, err := formatValue(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid parameter %s of openapi tool %s: %w", p.Name, o.info.Name, err)
+				}
+				if p.In == openapi3.ParameterInQuery {
+					query.Add(p.Name, s)
+				} else {
+					header.Add(p.Name, s)
+				}
+			}
+		}
+	}
+
+	var body io.Reader
+	if o.body {
+		if v, ok := args[BodyParamName]; ok && v != nil {
+			data, err := sonic.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal request body of openapi tool %s: %w", o.info.Name, err)
+			}
+			body = bytes.NewReader(data)
+			header.Set("Content-Type", "application/json")
+		}
+	}
+
+	u := o.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(o.method), u, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request of openapi tool %s: %w", o.info.Name, err)
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
+
+func formatValue(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		return sonic.MarshalString(val)
+	}
+}
+
+func (o *operationTool) GetType() string {
+	return "OpenAPI"
+}
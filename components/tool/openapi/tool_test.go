@@ -0,0 +1,226 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+const petSpec = `
+openapi: 3.0.0
+info:
+  title: pets
+  version: "1.0"
+servers:
+  - url: http://{host}/v1
+    variables:
+      host:
+        default: localhost
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      operationId: getPet
+      summary: get a pet by id
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+    put:
+      summary: update a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`unauthorized`))
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/pets/1":
+			assert.Equal(t, []string{"name", "age"}, r.URL.Query()["fields"])
+			assert.Equal(t, "abc", r.Header.Get("X-Trace"))
+			_, _ = w.Write([]byte(`{"id":1,"name":"kitty"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/pets/1":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(body)
+		case r.URL.Path == "/v1/pets/2":
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`not found`))
+		}
+	}))
+}
+
+func TestNewTools(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	auth := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	}
+
+	tools, err := NewTools(ctx, &Config{
+		Spec:             []byte(petSpec),
+		BaseURL:          srv.URL + "/v1",
+		Auth:             auth,
+		MaxResponseBytes: 50,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tools, 2)
+
+	getPet, putPet := tools[0], tools[1]
+
+	t.Run("info", func(t *testing.T) {
+		info, err := getPet.Info(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "getPet", info.Name)
+		assert.Equal(t, "get a pet by id", info.Desc)
+
+		sc, err := info.ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"petId"}, sc.Required)
+		assert.Equal(t, "integer", sc.Properties["petId"].Value.Type)
+		assert.Equal(t, "array", sc.Properties["fields"].Value.Type)
+		assert.Contains(t, sc.Properties, "X-Trace")
+
+		info, err = putPet.Info(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "put_pets_petId", info.Name)
+		sc, err = info.ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"petId", BodyParamName}, sc.Required)
+		assert.Contains(t, sc.Properties[BodyParamName].Value.Properties, "name")
+	})
+
+	t.Run("path query and header", func(t *testing.T) {
+		out, err := getPet.InvokableRun(ctx, `{"petId":1,"fields":["name","age"],"X-Trace":"abc"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"id":1,"name":"kitty"}`, out)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		out, err := putPet.InvokableRun(ctx, `{"petId":1,"body":{"name":"doggy"}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"doggy"}`, out)
+	})
+
+	t.Run("missing path param", func(t *testing.T) {
+		_, err := getPet.InvokableRun(ctx, `{}`)
+		assert.ErrorContains(t, err, "missing required parameter petId")
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		_, err := getPet.InvokableRun(ctx, `{"petId":3}`)
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.Equal(t, "not found", statusErr.Body)
+	})
+
+	t.Run("response too large", func(t *testing.T) {
+		_, err := getPet.InvokableRun(ctx, `{"petId":2}`)
+		assert.True(t, errors.Is(err, ErrResponseTooLarge))
+	})
+}
+
+func TestConfig(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	t.Run("default base url", func(t *testing.T) {
+		tools, err := NewTools(ctx, &Config{Spec: []byte(petSpec), OperationIDs: []string{"getPet"}})
+		assert.NoError(t, err)
+		assert.Len(t, tools, 1)
+		assert.Equal(t, "http://localhost/v1", tools[0].(*operationTool).baseURL)
+	})
+
+	t.Run("status to error", func(t *testing.T) {
+		tools, err := NewTools(ctx, &Config{
+			Spec:         []byte(petSpec),
+			BaseURL:      srv.URL + "/v1",
+			OperationIDs: []string{"getPet"},
+			StatusToError: func(ctx context.Context, toolName string, statusCode int, body []byte) error {
+				if statusCode == http.StatusUnauthorized {
+					return errors.New("need login")
+				}
+				return nil
+			},
+		})
+		assert.NoError(t, err)
+
+		var it tool.InvokableTool = tools[0]
+		_, err = it.InvokableRun(ctx, `{"petId":1}`)
+		assert.EqualError(t, err, "need login")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewTools(ctx, &Config{})
+		assert.Error(t, err)
+
+		_, err = NewTools(ctx, &Config{Spec: []byte(petSpec), BaseURL: "/v1"})
+		assert.ErrorContains(t, err, "absolute")
+	})
+}
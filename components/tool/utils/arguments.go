@@ -0,0 +1,245 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ArgumentsIssue is a single violation of the tool's parameters schema.
+type ArgumentsIssue struct {
+	// Path is the JSON pointer of the invalid value, e.g. /items/0/name, and / for the root.
+	Path string
+	// Message describes why the value is invalid.
+	Message string
+}
+
+// ArgumentsValidationError is returned when the arguments don't match the parameters schema of the tool.
+// The message lists the field path of every issue, so that the ChatModel can correct the arguments and retry.
+type ArgumentsValidationError struct {
+	ToolName string
+	Issues   []*ArgumentsIssue
+}
+
+func (e *ArgumentsValidationError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("invalid arguments for tool %s:", e.ToolName))
+	for _, issue := range e.Issues {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", issue.Path, issue.Message))
+	}
+	return sb.String()
+}
+
+// ValidateArguments validates the arguments in JSON against the parameters schema of the tool.
+// It returns *ArgumentsValidationError if the arguments are not a valid JSON or violate the schema,
+// and nil if the tool has no parameters schema.
+func ValidateArguments(info *schema.ToolInfo, argumentsInJSON string) error {
+	if info == nil || info.ParamsOneOf == nil {
+		return nil
+	}
+
+	sc, err := info.ToOpenAPIV3()
+	if err != nil {
+		return fmt.Errorf("failed to get parameters schema of tool %s: %w", info.Name, err)
+	}
+
+	return validateArguments(info.Name, sc, argumentsInJSON)
+}
+
+func validateArguments(toolName string, sc *openapi3.Schema, argumentsInJSON string) error {
+	if sc == nil {
+		return nil
+	}
+
+	if len(strings.TrimSpace(argumentsInJSON)) == 0 {
+		argumentsInJSON = "{}"
+	}
+
+	var value any
+	if err := sonic.UnmarshalString(argumentsInJSON, &value); err != nil {
+		return &ArgumentsValidationError{
+			ToolName: toolName,
+			Issues:   []*ArgumentsIssue{{Path: "/", Message: fmt.Sprintf("not a valid JSON: %v", err)}},
+		}
+	}
+
+	err := sc.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsRequest())
+	if err == nil {
+		return nil
+	}
+
+	return &ArgumentsValidationError{ToolName: toolName, Issues: toArgumentsIssues(err)}
+}
+
+func toArgumentsIssues(err error) []*ArgumentsIssue {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var issues []*ArgumentsIssue
+		for _, e := range multi {
+			issues = append(issues, toArgumentsIssues(e)...)
+		}
+		return issues
+	}
+
+	var scErr *openapi3.SchemaError
+	if errors.As(err, &scErr) {
+		return []*ArgumentsIssue{{
+			Path:    "/" + strings.Join(scErr.JSONPointer(), "/"),
+			Message: scErr.Reason,
+		}}
+	}
+
+	return []*ArgumentsIssue{{Path: "/", Message: err.Error()}}
+}
+
+// RepairJSON fixes the common mistakes of the JSON generated by ChatModels, including:
+// wrapping in markdown code fences, trailing commas, and unclosed strings, objects or arrays.
+// The result is not guaranteed to be a valid JSON, the input is returned as is if nothing needs to be fixed.
+func RepairJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+			// drop the language tag, e.g. ```json
+			s = s[idx+1:]
+		} else {
+			s = strings.TrimLeft(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+		}
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+	}
+
+	if len(s) == 0 {
+		return "{}"
+	}
+
+	var (
+		sb       = strings.Builder{}
+		closers  []byte
+		inString bool
+		escaped  bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if inString {
+			sb.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			closers = append(closers, '}')
+		case '[':
+			closers = append(closers, ']')
+		case '}', ']':
+			trimTrailingComma(&sb)
+			if len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+		}
+		sb.WriteByte(c)
+	}
+
+	if inString {
+		if escaped {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte('"')
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		trimTrailingComma(&sb)
+		if closers[i] == '}' && strings.HasSuffix(strings.TrimRight(sb.String(), " \t\r\n"), ":") {
+			// a key without value
+			sb.WriteString("null")
+		}
+		sb.WriteByte(closers[i])
+	}
+
+	return sb.String()
+}
+
+func trimTrailingComma(sb *strings.Builder) {
+	s := sb.String()
+	trimmed := strings.TrimRight(s, " \t\r\n")
+	if strings.HasSuffix(trimmed, ",") {
+		sb.Reset()
+		sb.WriteString(trimmed[:len(trimmed)-1])
+	}
+}
+
+// argumentsPreprocessor repairs and validates the arguments before they are unmarshalled.
+type argumentsPreprocessor struct {
+	info     *schema.ToolInfo
+	validate bool
+	repair   bool
+
+	once  sync.Once
+	sc    *openapi3.Schema
+	scErr error
+}
+
+func newArgumentsPreprocessor(info *schema.ToolInfo, to *toolOptions) *argumentsPreprocessor {
+	return &argumentsPreprocessor{
+		info:     info,
+		validate: to.validate,
+		repair:   to.repair,
+	}
+}
+
+func (a *argumentsPreprocessor) process(arguments string) (string, error) {
+	if a.repair && !sonic.ValidString(arguments) {
+		arguments = RepairJSON(arguments)
+	}
+
+	if !a.validate || a.info == nil || a.info.ParamsOneOf == nil {
+		return arguments, nil
+	}
+
+	a.once.Do(func() {
+		a.sc, a.scErr = a.info.ToOpenAPIV3()
+	})
+	if a.scErr != nil {
+		return "", fmt.Errorf("failed to get parameters schema of tool %s: %w", a.info.Name, a.scErr)
+	}
+
+	if err := validateArguments(a.info.Name, a.sc, arguments); err != nil {
+		return "", &tool.InvalidArgumentsError{Err: err}
+	}
+
+	return arguments, nil
+}
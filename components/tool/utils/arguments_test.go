@@ -0,0 +1,165 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+type orderItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type order struct {
+	ID    string       `json:"id"`
+	Items []*orderItem `json:"items"`
+	Note  string       `json:"note,omitempty"`
+}
+
+func TestRepairJSON(t *testing.T) {
+	cases := map[string]string{
+		"":                                      `{}`,
+		`{"a":1}`:                               `{"a":1}`,
+		"```json\n{\"a\":1}\n```":               `{"a":1}`,
+		"```{\"a\":1}```":                       `{"a":1}`,
+		`{"a":1,}`:                              `{"a":1}`,
+		`{"a":[1,2,],}`:                         `{"a":[1,2]}`,
+		`{"a":{"b":[1`:                          `{"a":{"b":[1]}}`,
+		`{"a":"x,}`:                             `{"a":"x,}"}`,
+		`{"a":`:                                 `{"a":null}`,
+		`{"a":"b\"c", "d":[{"e":1},{"e":2},], `: `{"a":"b\"c", "d":[{"e":1},{"e":2}]}`,
+	}
+
+	for in, expected := range cases {
+		out := RepairJSON(in)
+		assert.Equal(t, expected, out, in)
+		assert.True(t, sonic.ValidString(out), in)
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	info, err := GoStruct2ToolInfo[order]("create_order", "create an order")
+	assert.NoError(t, err)
+
+	assert.NoError(t, ValidateArguments(info, `{"id":"1","items":[{"name":"apple","count":1}]}`))
+	assert.NoError(t, ValidateArguments(&schema.ToolInfo{Name: "no_params"}, `anything`))
+
+	err = ValidateArguments(info, `{"id":1,"items":[{"name":"apple","count":"one"}]}`)
+	var vErr *ArgumentsValidationError
+	assert.True(t, errors.As(err, &vErr))
+
+	paths := make([]string, 0, len(vErr.Issues))
+	for _, issue := range vErr.Issues {
+		paths = append(paths, issue.Path)
+	}
+	assert.ElementsMatch(t, []string{"/id", "/items/0/count"}, paths)
+	assert.Contains(t, err.Error(), "/items/0/count")
+
+	err = ValidateArguments(info, `{"id":"1"}`)
+	assert.True(t, errors.As(err, &vErr))
+	assert.Equal(t, "/items", vErr.Issues[0].Path)
+
+	err = ValidateArguments(info, `{"id":`)
+	assert.True(t, errors.As(err, &vErr))
+	assert.Equal(t, "/", vErr.Issues[0].Path)
+}
+
+func TestToolArgumentsPreprocess(t *testing.T) {
+	ctx := context.Background()
+
+	createOrder := func(ctx context.Context, in *order) (string, error) {
+		return in.ID, nil
+	}
+
+	t.Run("invokable", func(t *testing.T) {
+		it, err := InferTool("create_order", "create an order", createOrder, WithArgumentsRepair())
+		assert.NoError(t, err)
+
+		out, err := it.InvokableRun(ctx, "```json\n{\"id\":\"1\",\"items\":[{\"name\":\"apple\",\"count\":1},],\n```")
+		assert.NoError(t, err)
+		assert.Equal(t, `"1"`, out)
+
+		_, err = it.InvokableRun(ctx, `{"id":"1"}`)
+		var argErr *tool.InvalidArgumentsError
+		assert.True(t, errors.As(err, &argErr))
+		assert.Contains(t, err.Error(), "/items")
+	})
+
+	t.Run("without options", func(t *testing.T) {
+		it, err := InferTool("create_order", "create an order", createOrder)
+		assert.NoError(t, err)
+
+		// validated by default, but not repaired
+		_, err = it.InvokableRun(ctx, `{"id":"1"}`)
+		var argErr *tool.InvalidArgumentsError
+		assert.True(t, errors.As(err, &argErr))
+		assert.Contains(t, err.Error(), "/items")
+
+		_, err = it.InvokableRun(ctx, `{"id":"1","items":[],}`)
+		assert.True(t, errors.As(err, &argErr))
+	})
+
+	t.Run("new tool", func(t *testing.T) {
+		info, err := GoStruct2ToolInfo[order]("create_order", "create an order")
+		assert.NoError(t, err)
+
+		it := NewTool(info, createOrder)
+		_, err = it.InvokableRun(ctx, `{"id":1,"items":[]}`)
+		var argErr *tool.InvalidArgumentsError
+		assert.True(t, errors.As(err, &argErr))
+		assert.Contains(t, err.Error(), "/id")
+	})
+
+	t.Run("without validation", func(t *testing.T) {
+		it, err := InferTool("create_order", "create an order", createOrder, WithoutArgumentsValidation())
+		assert.NoError(t, err)
+
+		out, err := it.InvokableRun(ctx, `{"id":"1"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `"1"`, out)
+	})
+
+	t.Run("streamable", func(t *testing.T) {
+		st, err := InferStreamTool("create_order", "create an order",
+			func(ctx context.Context, in *order) (*schema.StreamReader[string], error) {
+				return schema.StreamReaderFromArray([]string{in.ID}), nil
+			}, WithArgumentsRepair())
+		assert.NoError(t, err)
+
+		sr, err := st.StreamableRun(ctx, `{"id":"1","items":[]`)
+		assert.NoError(t, err)
+		chunk, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, `"1"`, chunk)
+		_, err = sr.Recv()
+		assert.Equal(t, io.EOF, err)
+
+		_, err = st.StreamableRun(ctx, `{"items":[{"name":1}]}`)
+		var vErr *ArgumentsValidationError
+		assert.True(t, errors.As(err, &vErr))
+	})
+}
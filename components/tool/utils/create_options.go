@@ -36,6 +36,9 @@ type toolOptions struct {
 	um UnmarshalArguments
 	m  MarshalOutput
 	sc SchemaCustomizerFn
+
+	validate bool
+	repair   bool
 }
 
 // Option is the option func for the tool.
@@ -57,6 +60,27 @@ func WithMarshalOutput(m MarshalOutput) Option {
 	}
 }
 
+// WithoutArgumentsValidation disables the validation of the arguments against the parameters schema of the tool,
+// e.g. for the tools relying on the arguments the schema doesn't describe exactly.
+// By default, the arguments are validated before they are unmarshalled, and when they are invalid,
+// *ArgumentsValidationError wrapped in *tool.InvalidArgumentsError is returned,
+// with the paths of the invalid fields, so that the ChatModel can correct the arguments.
+// The arguments are never validated when WithUnmarshalArguments is set.
+func WithoutArgumentsValidation() Option {
+	return func(o *toolOptions) {
+		o.validate = false
+	}
+}
+
+// WithArgumentsRepair enables RepairJSON on the arguments which are not a valid JSON,
+// e.g. the arguments wrapped in markdown code fences, or with trailing commas or unclosed braces.
+// It takes no effect when WithUnmarshalArguments is set.
+func WithArgumentsRepair() Option {
+	return func(o *toolOptions) {
+		o.repair = true
+	}
+}
+
 // SchemaCustomizerFn is the schema customizer function for inferring tool parameter from tagged go struct.
 // Within this function, end-user can parse custom go struct tags into corresponding openapi schema field.
 // Parameters:
@@ -76,8 +100,9 @@ func WithSchemaCustomizer(sc SchemaCustomizerFn) Option {
 
 func getToolOptions(opt ...Option) *toolOptions {
 	opts := &toolOptions{
-		um: nil,
-		m:  nil,
+		um:       nil,
+		m:        nil,
+		validate: true,
 	}
 	for _, o := range opt {
 		o(opts)
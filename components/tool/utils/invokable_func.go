@@ -113,6 +113,7 @@ func newOptionableTool[T, D any](desc *schema.ToolInfo, i OptionableInvokeFunc[T
 		info: desc,
 		um:   to.um,
 		m:    to.m,
+		ap:   newArgumentsPreprocessor(desc, to),
 		Fn:   i,
 	}
 }
@@ -122,6 +123,7 @@ type invokableTool[T, D any] struct {
 
 	um UnmarshalArguments
 	m  MarshalOutput
+	ap *argumentsPreprocessor
 
 	Fn OptionableInvokeFunc[T, D]
 }
@@ -138,7 +140,7 @@ func (i *invokableTool[T, D]) InvokableRun(ctx context.Context, arguments string
 		var val interface{}
 		val, err = i.um(ctx, arguments)
 		if err != nil {
-			return "", fmt.Errorf("[LocalFunc] failed to unmarshal arguments, toolName=%s, err=%w", i.getToolName(), err)
+			return "", &tool.InvalidArgumentsError{Err: fmt.Errorf("[LocalFunc] failed to unmarshal arguments, toolName=%s, err=%w", i.getToolName(), err)}
 		}
 		gt, ok := val.(T)
 		if !ok {
@@ -148,9 +150,14 @@ func (i *invokableTool[T, D]) InvokableRun(ctx context.Context, arguments string
 	} else {
 		inst = generic.NewInstance[T]()
 
+		arguments, err = i.ap.process(arguments)
+		if err != nil {
+			return "", err
+		}
+
 		err = sonic.UnmarshalString(arguments, &inst)
 		if err != nil {
-			return "", fmt.Errorf("[LocalFunc] failed to unmarshal arguments in json, toolName=%s, err=%w", i.getToolName(), err)
+			return "", &tool.InvalidArgumentsError{Err: fmt.Errorf("[LocalFunc] failed to unmarshal arguments in json, toolName=%s, err=%w", i.getToolName(), err)}
 		}
 	}
 
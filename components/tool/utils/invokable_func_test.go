@@ -213,7 +213,7 @@ func TestInferTool(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, toolInfo, info)
 
-		content, err := tl.InvokableRun(ctx, `{"name": "bruce lee"}`)
+		content, err := tl.InvokableRun(ctx, `{"name": "bruce lee", "age": 32, "incomes": []}`)
 		assert.NoError(t, err)
 		assert.JSONEq(t, `{"code":200,"msg":"update bruce lee success"}`, content)
 	})
@@ -228,7 +228,7 @@ func TestInferOptionableTool(t *testing.T) {
 		tl, err := InferOptionableTool("invoke_infer_optionable_tool", "full update user info", updateUserInfoWithOption)
 		assert.NoError(t, err)
 
-		content, err := tl.InvokableRun(ctx, `{"name": "bruce lee"}`, WithUserInfoOption("hello world"))
+		content, err := tl.InvokableRun(ctx, `{"name": "bruce lee", "age": 32, "incomes": []}`, WithUserInfoOption("hello world"))
 		assert.NoError(t, err)
 		assert.JSONEq(t, `{"code":200,"msg":"hello world"}`, content)
 	})
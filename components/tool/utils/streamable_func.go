@@ -73,6 +73,7 @@ func newOptionableStreamTool[T, D any](desc *schema.ToolInfo, s OptionableStream
 
 		um: to.um,
 		m:  to.m,
+		ap: newArgumentsPreprocessor(desc, to),
 		Fn: s,
 	}
 }
@@ -82,6 +83,7 @@ type streamableTool[T, D any] struct {
 
 	um UnmarshalArguments
 	m  MarshalOutput
+	ap *argumentsPreprocessor
 
 	Fn OptionableStreamFunc[T, D]
 }
@@ -100,7 +102,7 @@ func (s *streamableTool[T, D]) StreamableRun(ctx context.Context, argumentsInJSO
 		var val interface{}
 		val, err = s.um(ctx, argumentsInJSON)
 		if err != nil {
-			return nil, fmt.Errorf("[LocalStreamFunc] failed to unmarshal arguments, toolName=%s, err=%w", s.getToolName(), err)
+			return nil, &tool.InvalidArgumentsError{Err: fmt.Errorf("[LocalStreamFunc] failed to unmarshal arguments, toolName=%s, err=%w", s.getToolName(), err)}
 		}
 
 		gt, ok := val.(T)
@@ -112,9 +114,14 @@ func (s *streamableTool[T, D]) StreamableRun(ctx context.Context, argumentsInJSO
 
 		inst = generic.NewInstance[T]()
 
+		argumentsInJSON, err = s.ap.process(argumentsInJSON)
+		if err != nil {
+			return nil, err
+		}
+
 		err = sonic.UnmarshalString(argumentsInJSON, &inst)
 		if err != nil {
-			return nil, fmt.Errorf("[LocalStreamFunc] failed to unmarshal arguments in json, toolName=%s, err=%w", s.getToolName(), err)
+			return nil, &tool.InvalidArgumentsError{Err: fmt.Errorf("[LocalStreamFunc] failed to unmarshal arguments in json, toolName=%s, err=%w", s.getToolName(), err)}
 		}
 	}
 
@@ -58,12 +58,105 @@ func WithToolList(tool ...tool.BaseTool) ToolsNodeOption {
 //	Stream(ctx context.Context, input *schema.Message, opts ...ToolsNodeOption) (*schema.StreamReader[[]*schema.Message], error)
 type ToolsNode struct {
 	tuple *toolsTuple
+
+	recoverToolErrors  bool
+	toolErrorFormatter func(ctx context.Context, err *ToolCallError) string
+	onToolCallError    func(ctx context.Context, err *ToolCallError)
+
+	executionMode   ToolsExecutionMode
+	toolGroups      map[string]string
+	toolConcurrency map[string]int
 }
 
+// ToolsExecutionMode is the mode of running the tool calls in one message.
+type ToolsExecutionMode string
+
+const (
+	// ToolsExecutionModeParallel runs the tool calls concurrently, it's the default mode.
+	ToolsExecutionModeParallel ToolsExecutionMode = "parallel"
+	// ToolsExecutionModeSequential runs the tool calls one by one, in the order given by the ChatModel.
+	ToolsExecutionModeSequential ToolsExecutionMode = "sequential"
+)
+
 // ToolsNodeConfig is the config for ToolsNode. It requires a list of tools.
 // Tools are BaseTool but must implement InvokableTool or StreamableTool.
 type ToolsNodeConfig struct {
 	Tools []tool.BaseTool
+
+	// RecoverToolErrors turns the failed tool calls into tool messages instead of failing the whole ToolsNode,
+	// so that the ChatModel can read the error and correct itself, e.g. in a ReAct agent.
+	// It covers calling an unknown tool, invalid arguments, the error returned by the tool and the panic of the tool,
+	// while the results of the other tool calls are kept.
+	// The interrupt of the tool and the error after the context is done are never recovered.
+	// Each recovered error is reported to the OnError of the callbacks as *ToolCallError, with the RunInfo of the tool,
+	// including an unknown tool, whose RunInfo has the name called only.
+	// The error returned by a tool that has been run is reported by its own OnError callback before that, as it is.
+	// Optional. Default is false.
+	RecoverToolErrors bool
+	// ToolErrorFormatter formats the recovered error as the content of the tool message.
+	// Optional. By default, a short description of the error is used, without the panic stack.
+	ToolErrorFormatter func(ctx context.Context, err *ToolCallError) string
+	// OnToolCallError is called with each recovered error, e.g. for logging or metrics without callbacks.
+	// Optional.
+	OnToolCallError func(ctx context.Context, err *ToolCallError)
+
+	// ExecutionMode decides how the tool calls in one message are run.
+	// Optional. Default is ToolsExecutionModeParallel.
+	// In any mode, the order of the output messages is the same as the order of the tool calls.
+	// When streaming, the mode applies to calling StreamableRun, the output streams are still read concurrently.
+	ExecutionMode ToolsExecutionMode
+	// ToolGroups maps tool names to mutual exclusion groups in parallel mode.
+	// The calls of the tools in the same group never run at the same time, and are run in the order given by the ChatModel,
+	// e.g. put the tools editing the same files into one group.
+	// Optional. The tools not in any group run concurrently.
+	ToolGroups map[string]string
+	// ToolConcurrency limits the max number of concurrent calls of each tool in parallel mode.
+	// Optional. The tools not in the map, or with a limit <= 0, are not limited.
+	ToolConcurrency map[string]int
+}
+
+// ToolCallErrorKind is the kind of ToolCallError.
+type ToolCallErrorKind string
+
+const (
+	// ToolCallErrorUnknownTool means the tool called is not in the tools of ToolsNode.
+	ToolCallErrorUnknownTool ToolCallErrorKind = "unknown_tool"
+	// ToolCallErrorInvalidArguments means the tool returns *tool.InvalidArgumentsError.
+	ToolCallErrorInvalidArguments ToolCallErrorKind = "invalid_arguments"
+	// ToolCallErrorExecution means the tool returns any other error.
+	ToolCallErrorExecution ToolCallErrorKind = "execution"
+	// ToolCallErrorPanic means the tool panics.
+	ToolCallErrorPanic ToolCallErrorKind = "panic"
+)
+
+// ToolCallError is the error of a single tool call, recovered by ToolsNode when ToolsNodeConfig.RecoverToolErrors is set.
+type ToolCallError struct {
+	Kind      ToolCallErrorKind
+	ToolName  string
+	CallID    string
+	Arguments string
+	Err       error
+}
+
+func (e *ToolCallError) Error() string {
+	return fmt.Sprintf("tool call %s failed, tool=%s, kind=%s: %v", e.CallID, e.ToolName, e.Kind, e.Err)
+}
+
+func (e *ToolCallError) Unwrap() error {
+	return e.Err
+}
+
+func defaultToolErrorFormatter(_ context.Context, err *ToolCallError) string {
+	switch err.Kind {
+	case ToolCallErrorUnknownTool:
+		return fmt.Sprintf("error: tool %s does not exist, please call one of the available tools", err.ToolName)
+	case ToolCallErrorInvalidArguments:
+		return fmt.Sprintf("error: invalid arguments for tool %s: %v, please fix the arguments and retry", err.ToolName, err.Err)
+	case ToolCallErrorPanic:
+		return fmt.Sprintf("error: tool %s failed unexpectedly", err.ToolName)
+	default:
+		return fmt.Sprintf("error: failed to call tool %s: %v", err.ToolName, err.Err)
+	}
 }
 
 // NewToolNode creates a new ToolsNode.
@@ -79,8 +172,25 @@ func NewToolNode(ctx context.Context, conf *ToolsNodeConfig) (*ToolsNode, error)
 		return nil, err
 	}
 
+	formatter := conf.ToolErrorFormatter
+	if formatter == nil {
+		formatter = defaultToolErrorFormatter
+	}
+
+	switch conf.ExecutionMode {
+	case "", ToolsExecutionModeParallel, ToolsExecutionModeSequential:
+	default:
+		return nil, fmt.Errorf("unknown tools execution mode: %s", conf.ExecutionMode)
+	}
+
 	return &ToolsNode{
-		tuple: tuple,
+		tuple:              tuple,
+		recoverToolErrors:  conf.RecoverToolErrors,
+		toolErrorFormatter: formatter,
+		onToolCallError:    conf.OnToolCallError,
+		executionMode:      conf.ExecutionMode,
+		toolGroups:         conf.ToolGroups,
+		toolConcurrency:    conf.ToolConcurrency,
 	}, nil
 }
 
@@ -150,12 +260,13 @@ type toolCallTask struct {
 	callID string
 
 	// out
-	output  string
-	sOutput *schema.StreamReader[string]
-	err     error
+	output   string
+	sOutput  *schema.StreamReader[string]
+	err      error
+	panicked bool
 }
 
-func genToolCallTasks(tuple *toolsTuple, input *schema.Message) ([]toolCallTask, error) {
+func genToolCallTasks(tuple *toolsTuple, input *schema.Message, recoverUnknown bool) ([]toolCallTask, error) {
 	if input.Role != schema.Assistant {
 		return nil, fmt.Errorf("expected message role is Assistant, got %s", input.Role)
 	}
@@ -169,16 +280,23 @@ func genToolCallTasks(tuple *toolsTuple, input *schema.Message) ([]toolCallTask,
 
 	for i := 0; i < n; i++ {
 		toolCall := input.ToolCalls[i]
+		toolCallTasks[i].name = toolCall.Function.Name
+		toolCallTasks[i].arg = toolCall.Function.Arguments
+		toolCallTasks[i].callID = toolCall.ID
+
 		index, ok := tuple.indexes[toolCall.Function.Name]
 		if !ok {
-			return nil, fmt.Errorf("tool %s not found in toolsNode indexes", toolCall.Function.Name)
+			err := fmt.Errorf("tool %s not found in toolsNode indexes", toolCall.Function.Name)
+			if !recoverUnknown {
+				return nil, err
+			}
+			// the task is left without runnable, and is skipped when running
+			toolCallTasks[i].err = err
+			continue
 		}
 
 		toolCallTasks[i].r = tuple.rps[index]
 		toolCallTasks[i].meta = tuple.meta[index]
-		toolCallTasks[i].name = toolCall.Function.Name
-		toolCallTasks[i].arg = toolCall.Function.Arguments
-		toolCallTasks[i].callID = toolCall.ID
 	}
 
 	return toolCallTasks, nil
@@ -208,11 +326,29 @@ func runToolCallTaskByStream(ctx context.Context, task *toolCallTask, opts ...to
 	task.sOutput, task.err = task.r.Stream(ctx, task.arg, opts...) // nolint: byted_returned_err_should_do_check
 }
 
+func safeRunToolCall(ctx context.Context,
+	run func(ctx2 context.Context, callTask *toolCallTask, opts ...tool.Option), t *toolCallTask, opts ...tool.Option) {
+
+	if t.r == nil {
+		return
+	}
+
+	defer func() {
+		panicErr := recover()
+		if panicErr != nil {
+			t.err = safe.NewPanicErr(panicErr, debug.Stack()) // nolint: byted_returned_err_should_do_check
+			t.panicked = true
+		}
+	}()
+
+	run(ctx, t, opts...)
+}
+
 func parallelRunToolCall(ctx context.Context,
 	run func(ctx2 context.Context, callTask *toolCallTask, opts ...tool.Option), tasks []toolCallTask, opts ...tool.Option) {
 
 	if len(tasks) == 1 {
-		run(ctx, &tasks[0], opts...)
+		safeRunToolCall(ctx, run, &tasks[0], opts...)
 		return
 	}
 
@@ -221,22 +357,140 @@ func parallelRunToolCall(ctx context.Context,
 		wg.Add(1)
 		go func(ctx_ context.Context, t *toolCallTask, opts ...tool.Option) {
 			defer wg.Done()
-			defer func() {
-				panicErr := recover()
-				if panicErr != nil {
-					t.err = safe.NewPanicErr(panicErr, debug.Stack()) // nolint: byted_returned_err_should_do_check
-				}
-			}()
-			run(ctx_, t, opts...)
+			safeRunToolCall(ctx_, run, t, opts...)
 		}(ctx, &tasks[i], opts...)
 	}
 
-	run(ctx, &tasks[0], opts...)
+	safeRunToolCall(ctx, run, &tasks[0], opts...)
+	wg.Wait()
+}
+
+func sequentialRunToolCall(ctx context.Context,
+	run func(ctx2 context.Context, callTask *toolCallTask, opts ...tool.Option), tasks []toolCallTask, opts ...tool.Option) {
+
+	for i := range tasks {
+		safeRunToolCall(ctx, run, &tasks[i], opts...)
+	}
+}
+
+// groupedRunToolCall runs the tasks of the same group one by one, and the groups concurrently,
+// each task not in any group is a group of its own.
+// The concurrent calls of the same tool are limited by the concurrency.
+func groupedRunToolCall(ctx context.Context,
+	run func(ctx2 context.Context, callTask *toolCallTask, opts ...tool.Option), tasks []toolCallTask,
+	toolGroups map[string]string, toolConcurrency map[string]int, opts ...tool.Option) {
+
+	var (
+		units      [][]*toolCallTask
+		groupIndex = make(map[string]int)
+		semaphores = make(map[string]chan struct{})
+	)
+	for i := range tasks {
+		t := &tasks[i]
+		if limit := toolConcurrency[t.name]; limit > 0 && semaphores[t.name] == nil {
+			semaphores[t.name] = make(chan struct{}, limit)
+		}
+
+		group, ok := toolGroups[t.name]
+		if !ok {
+			units = append(units, []*toolCallTask{t})
+			continue
+		}
+
+		if idx, ok := groupIndex[group]; ok {
+			units[idx] = append(units[idx], t)
+			continue
+		}
+		groupIndex[group] = len(units)
+		units = append(units, []*toolCallTask{t})
+	}
+
+	runUnit := func(ctx context.Context, unit []*toolCallTask) {
+		for _, t := range unit {
+			sem := semaphores[t.name]
+			if sem != nil {
+				sem <- struct{}{}
+			}
+			safeRunToolCall(ctx, run, t, opts...)
+			if sem != nil {
+				<-sem
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i < len(units); i++ {
+		wg.Add(1)
+		go func(ctx_ context.Context, unit []*toolCallTask) {
+			defer wg.Done()
+			runUnit(ctx_, unit)
+		}(ctx, units[i])
+	}
+
+	runUnit(ctx, units[0])
 	wg.Wait()
 }
 
+func (tn *ToolsNode) runToolCalls(ctx context.Context,
+	run func(ctx2 context.Context, callTask *toolCallTask, opts ...tool.Option), tasks []toolCallTask, opts ...tool.Option) {
+
+	switch {
+	case tn.executionMode == ToolsExecutionModeSequential:
+		sequentialRunToolCall(ctx, run, tasks, opts...)
+	case len(tn.toolGroups) > 0 || len(tn.toolConcurrency) > 0:
+		groupedRunToolCall(ctx, run, tasks, tn.toolGroups, tn.toolConcurrency, opts...)
+	default:
+		parallelRunToolCall(ctx, run, tasks, opts...)
+	}
+}
+
+// recoverToolCallError converts the error of the task to the content of a tool message if it's recoverable.
+func (tn *ToolsNode) recoverToolCallError(ctx context.Context, task *toolCallTask) (string, bool) {
+	if !tn.recoverToolErrors || ctx.Err() != nil {
+		return "", false
+	}
+
+	if _, ok := ExtractInterruptInfo(task.err); ok || isSubGraphInterrupt(task.err) != nil {
+		return "", false
+	}
+
+	tcErr := &ToolCallError{
+		Kind:      ToolCallErrorExecution,
+		ToolName:  task.name,
+		CallID:    task.callID,
+		Arguments: task.arg,
+		Err:       task.err,
+	}
+
+	var argErr *tool.InvalidArgumentsError
+	switch {
+	case task.r == nil:
+		tcErr.Kind = ToolCallErrorUnknownTool
+	case task.panicked:
+		tcErr.Kind = ToolCallErrorPanic
+	case errors.As(task.err, &argErr):
+		tcErr.Kind = ToolCallErrorInvalidArguments
+	}
+
+	// report the recovered error to the callbacks as an error of the tool call,
+	// including the unknown tool, which is never run
+	info := &callbacks.RunInfo{Name: task.name, Component: components.ComponentOfTool}
+	if task.meta != nil {
+		info.Type, info.Component = task.meta.componentImplType, task.meta.component
+	}
+	ctx = callbacks.ReuseHandlers(ctx, info)
+	ctx = setToolCallInfo(ctx, &toolCallInfo{toolCallID: task.callID})
+	ctx = callbacks.OnError(ctx, tcErr)
+
+	if tn.onToolCallError != nil {
+		tn.onToolCallError(ctx, tcErr)
+	}
+
+	return tn.toolErrorFormatter(ctx, tcErr), true
+}
+
 // Invoke calls the tools and collects the results of invokable tools.
-// it's parallel if there are multiple tool calls in the input message.
+// it's parallel if there are multiple tool calls in the input message, unless configured otherwise by ToolsNodeConfig.ExecutionMode.
 func (tn *ToolsNode) Invoke(ctx context.Context, input *schema.Message,
 	opts ...ToolsNodeOption) ([]*schema.Message, error) {
 
@@ -250,18 +504,24 @@ func (tn *ToolsNode) Invoke(ctx context.Context, input *schema.Message,
 		}
 	}
 
-	tasks, err := genToolCallTasks(tuple, input)
+	tasks, err := genToolCallTasks(tuple, input, tn.recoverToolErrors)
 	if err != nil {
 		return nil, err
 	}
 
-	parallelRunToolCall(ctx, runToolCallTaskByInvoke, tasks, opt.ToolOptions...)
+	tn.runToolCalls(ctx, runToolCallTaskByInvoke, tasks, opt.ToolOptions...)
 
 	n := len(tasks)
 	output := make([]*schema.Message, n)
 	for i := 0; i < n; i++ {
 		if tasks[i].err != nil {
-			return nil, fmt.Errorf("failed to invoke tool call %s: %w", tasks[i].callID, tasks[i].err)
+			content, ok := tn.recoverToolCallError(ctx, &tasks[i])
+			if !ok {
+				return nil, fmt.Errorf("failed to invoke tool call %s: %w", tasks[i].callID, tasks[i].err)
+			}
+
+			output[i] = schema.ToolMessage(content, tasks[i].callID)
+			continue
 		}
 
 		output[i] = schema.ToolMessage(tasks[i].output, tasks[i].callID)
@@ -271,7 +531,7 @@ func (tn *ToolsNode) Invoke(ctx context.Context, input *schema.Message,
 }
 
 // Stream calls the tools and collects the results of stream readers.
-// it's parallel if there are multiple tool calls in the input message.
+// it's parallel if there are multiple tool calls in the input message, unless configured otherwise by ToolsNodeConfig.ExecutionMode.
 func (tn *ToolsNode) Stream(ctx context.Context, input *schema.Message,
 	opts ...ToolsNodeOption) (*schema.StreamReader[[]*schema.Message], error) {
 
@@ -285,19 +545,35 @@ func (tn *ToolsNode) Stream(ctx context.Context, input *schema.Message,
 		}
 	}
 
-	tasks, err := genToolCallTasks(tuple, input)
+	tasks, err := genToolCallTasks(tuple, input, tn.recoverToolErrors)
 	if err != nil {
 		return nil, err
 	}
 
-	parallelRunToolCall(ctx, runToolCallTaskByStream, tasks, opt.ToolOptions...)
+	tn.runToolCalls(ctx, runToolCallTaskByStream, tasks, opt.ToolOptions...)
 
 	n := len(tasks)
 	sOutput := make([]*schema.StreamReader[[]*schema.Message], n)
 
 	for i := 0; i < n; i++ {
 		if tasks[i].err != nil {
-			return nil, fmt.Errorf("failed to stream tool call %s: %w", tasks[i].callID, tasks[i].err)
+			content, ok := tn.recoverToolCallError(ctx, &tasks[i])
+			if !ok {
+				for j := 0; j < i; j++ {
+					sOutput[j].Close()
+				}
+				for j := i + 1; j < n; j++ {
+					if tasks[j].sOutput != nil {
+						tasks[j].sOutput.Close()
+					}
+				}
+				return nil, fmt.Errorf("failed to stream tool call %s: %w", tasks[i].callID, tasks[i].err)
+			}
+
+			ret := make([]*schema.Message, n)
+			ret[i] = schema.ToolMessage(content, tasks[i].callID)
+			sOutput[i] = schema.StreamReaderFromArray([][]*schema.Message{ret})
+			continue
 		}
 
 		index := i
@@ -18,13 +18,18 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -592,3 +597,275 @@ func (m *mockTool) StreamableRun(ctx context.Context, argumentsInJSON string, op
 
 	return sr, nil
 }
+
+func TestToolsNodeRecoverToolErrors(t *testing.T) {
+	ctx := context.Background()
+
+	type args struct {
+		Name string `json:"name"`
+	}
+
+	hello, err := utils.InferTool("hello", "say hello", func(ctx context.Context, in *args) (string, error) {
+		switch in.Name {
+		case "panic":
+			panic("boom")
+		case "error":
+			return "", errors.New("bad name")
+		}
+		return "hello " + in.Name, nil
+	})
+	assert.NoError(t, err)
+
+	input := &schema.Message{
+		Role: schema.Assistant,
+		ToolCalls: []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "hello", Arguments: `{"name":"eino"}`}},
+			{ID: "2", Function: schema.FunctionCall{Name: "unknown", Arguments: `{}`}},
+			{ID: "3", Function: schema.FunctionCall{Name: "hello", Arguments: `{"name":`}},
+			{ID: "4", Function: schema.FunctionCall{Name: "hello", Arguments: `{"name":"error"}`}},
+			{ID: "5", Function: schema.FunctionCall{Name: "hello", Arguments: `{"name":"panic"}`}},
+		},
+	}
+
+	t.Run("not recovered", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{hello}})
+		assert.NoError(t, err)
+
+		_, err = tn.Invoke(ctx, input)
+		assert.ErrorContains(t, err, "tool unknown not found")
+	})
+
+	t.Run("recovered", func(t *testing.T) {
+		var mu sync.Mutex
+		kinds := make(map[string]ToolCallErrorKind)
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{hello},
+			RecoverToolErrors: true,
+			OnToolCallError: func(ctx context.Context, err *ToolCallError) {
+				mu.Lock()
+				kinds[err.CallID] = err.Kind
+				mu.Unlock()
+			},
+		})
+		assert.NoError(t, err)
+
+		starts := make(map[string]int)
+		onErrors := make(map[string]int)
+		recovered := make(map[string]string)
+		handler := callbacks.NewHandlerBuilder().OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			mu.Lock()
+			starts[GetToolCallID(ctx)]++
+			mu.Unlock()
+			return ctx
+		}).OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			mu.Lock()
+			defer mu.Unlock()
+			var tcErr *ToolCallError
+			if errors.As(err, &tcErr) {
+				assert.Equal(t, components.ComponentOfTool, info.Component)
+				recovered[GetToolCallID(ctx)] = info.Name + ":" + string(tcErr.Kind)
+				return ctx
+			}
+			onErrors[GetToolCallID(ctx)]++
+			return ctx
+		}).Build()
+		cbCtx := callbacks.InitCallbacks(ctx, &callbacks.RunInfo{}, handler)
+
+		out, err := tn.Invoke(cbCtx, input)
+		assert.NoError(t, err)
+		assert.Len(t, out, 5)
+		assert.Equal(t, `"hello eino"`, out[0].Content)
+		assert.Contains(t, out[1].Content, "tool unknown does not exist")
+		assert.Contains(t, out[2].Content, "invalid arguments for tool hello")
+		assert.Contains(t, out[3].Content, "bad name")
+		assert.Equal(t, "error: tool hello failed unexpectedly", out[4].Content)
+		for i, msg := range out {
+			assert.Equal(t, input.ToolCalls[i].ID, msg.ToolCallID)
+		}
+
+		assert.Equal(t, map[string]ToolCallErrorKind{
+			"2": ToolCallErrorUnknownTool,
+			"3": ToolCallErrorInvalidArguments,
+			"4": ToolCallErrorExecution,
+			"5": ToolCallErrorPanic,
+		}, kinds)
+
+		// the errors returned by the tools that have been run are reported by their own callbacks,
+		// and all the recovered errors are reported as *ToolCallError, including the unknown tool, which is never run
+		assert.Equal(t, map[string]int{"1": 1, "3": 1, "4": 1, "5": 1}, starts)
+		assert.Equal(t, map[string]int{"3": 1, "4": 1}, onErrors)
+		assert.Equal(t, map[string]string{
+			"2": "unknown:unknown_tool",
+			"3": "hello:invalid_arguments",
+			"4": "hello:execution",
+			"5": "hello:panic",
+		}, recovered)
+
+		sr, err := tn.Stream(ctx, input)
+		assert.NoError(t, err)
+		msgs, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 5)
+		assert.Equal(t, `"hello eino"`, msgs[0].Content)
+		assert.Contains(t, msgs[1].Content, "tool unknown does not exist")
+	})
+
+	t.Run("graph callbacks", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{Tools: []tool.BaseTool{hello}, RecoverToolErrors: true})
+		assert.NoError(t, err)
+
+		g := NewGraph[*schema.Message, []*schema.Message]()
+		assert.NoError(t, g.AddToolsNode("tools", tn))
+		assert.NoError(t, g.AddEdge(START, "tools"))
+		assert.NoError(t, g.AddEdge("tools", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		var mu sync.Mutex
+		var kinds []ToolCallErrorKind
+		handler := callbacks.NewHandlerBuilder().OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			var tcErr *ToolCallError
+			if errors.As(err, &tcErr) {
+				mu.Lock()
+				kinds = append(kinds, tcErr.Kind)
+				mu.Unlock()
+			}
+			return ctx
+		}).Build()
+
+		_, err = r.Invoke(ctx, input, WithCallbacks(handler))
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []ToolCallErrorKind{
+			ToolCallErrorUnknownTool, ToolCallErrorInvalidArguments, ToolCallErrorExecution, ToolCallErrorPanic,
+		}, kinds)
+	})
+
+	t.Run("formatter", func(t *testing.T) {
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:             []tool.BaseTool{hello},
+			RecoverToolErrors: true,
+			ToolErrorFormatter: func(ctx context.Context, err *ToolCallError) string {
+				return string(err.Kind)
+			},
+		})
+		assert.NoError(t, err)
+
+		out, err := tn.Invoke(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, "unknown_tool", out[1].Content)
+		assert.Equal(t, "panic", out[4].Content)
+	})
+}
+
+type concurrencyRecorder struct {
+	mu      sync.Mutex
+	active  map[string]int
+	maxSeen map[string]int
+	order   []string
+}
+
+func (r *concurrencyRecorder) newTool(t *testing.T, name, group string) tool.InvokableTool {
+	type args struct {
+		ID string `json:"id"`
+	}
+
+	it, err := utils.InferTool(name, name, func(ctx context.Context, in *args) (string, error) {
+		r.mu.Lock()
+		r.active[group]++
+		if r.active[group] > r.maxSeen[group] {
+			r.maxSeen[group] = r.active[group]
+		}
+		r.order = append(r.order, in.ID)
+		r.mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		r.mu.Lock()
+		r.active[group]--
+		r.mu.Unlock()
+		return in.ID, nil
+	})
+	assert.NoError(t, err)
+
+	return it
+}
+
+func TestToolsNodeExecutionMode(t *testing.T) {
+	ctx := context.Background()
+
+	newInput := func(names ...string) *schema.Message {
+		msg := &schema.Message{Role: schema.Assistant}
+		for i, name := range names {
+			id := fmt.Sprintf("%d", i)
+			msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+				ID:       id,
+				Function: schema.FunctionCall{Name: name, Arguments: fmt.Sprintf(`{"id":"%s"}`, id)},
+			})
+		}
+		return msg
+	}
+
+	checkOutput := func(t *testing.T, input *schema.Message, out []*schema.Message) {
+		assert.Len(t, out, len(input.ToolCalls))
+		for i, msg := range out {
+			assert.Equal(t, input.ToolCalls[i].ID, msg.ToolCallID)
+			assert.Equal(t, fmt.Sprintf(`"%s"`, input.ToolCalls[i].ID), msg.Content)
+		}
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		r := &concurrencyRecorder{active: map[string]int{}, maxSeen: map[string]int{}}
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools:         []tool.BaseTool{r.newTool(t, "a", "all"), r.newTool(t, "b", "all")},
+			ExecutionMode: ToolsExecutionModeSequential,
+		})
+		assert.NoError(t, err)
+
+		input := newInput("a", "b", "a", "b")
+		out, err := tn.Invoke(ctx, input)
+		assert.NoError(t, err)
+		checkOutput(t, input, out)
+		assert.Equal(t, 1, r.maxSeen["all"])
+		assert.Equal(t, []string{"0", "1", "2", "3"}, r.order)
+	})
+
+	t.Run("groups and concurrency", func(t *testing.T) {
+		r := &concurrencyRecorder{active: map[string]int{}, maxSeen: map[string]int{}}
+		tn, err := NewToolNode(ctx, &ToolsNodeConfig{
+			Tools: []tool.BaseTool{
+				r.newTool(t, "edit", "files"),
+				r.newTool(t, "delete", "files"),
+				r.newTool(t, "search", "search"),
+			},
+			ToolGroups:      map[string]string{"edit": "files", "delete": "files"},
+			ToolConcurrency: map[string]int{"search": 2},
+		})
+		assert.NoError(t, err)
+
+		input := newInput("edit", "search", "delete", "search", "search", "edit", "search")
+		out, err := tn.Invoke(ctx, input)
+		assert.NoError(t, err)
+		checkOutput(t, input, out)
+		assert.Equal(t, 1, r.maxSeen["files"])
+		assert.Equal(t, 2, r.maxSeen["search"])
+
+		var fileOrder []string
+		for _, id := range r.order {
+			if id == "0" || id == "2" || id == "5" {
+				fileOrder = append(fileOrder, id)
+			}
+		}
+		assert.Equal(t, []string{"0", "2", "5"}, fileOrder)
+
+		sr, err := tn.Stream(ctx, input)
+		assert.NoError(t, err)
+		out, err = concatStreamReader(sr)
+		assert.NoError(t, err)
+		checkOutput(t, input, out)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		_, err := NewToolNode(ctx, &ToolsNodeConfig{ExecutionMode: "unknown"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package agenttool wraps compose.Runnable, such as compiled graphs and chains, and agents, such as react.Agent
+// and host.MultiAgent, as tools, so that they can be called by other agents.
+package agenttool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Tool is both tool.InvokableTool and tool.StreamableTool.
+type Tool interface {
+	tool.InvokableTool
+	tool.StreamableTool
+}
+
+// Agent is the interface of agents which can be wrapped as tool, e.g. react.Agent and host.MultiAgent.
+type Agent interface {
+	Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error)
+	Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error)
+}
+
+// AgentInput is the arguments of the tools created by NewAgentTool.
+type AgentInput struct {
+	Request string `json:"request" jsonschema:"description=the request to the agent, with all the context needed to complete it"`
+}
+
+type options struct {
+	m  utils.MarshalOutput
+	sc utils.SchemaCustomizerFn
+}
+
+// Option is the option for creating the tools.
+type Option func(o *options)
+
+// WithMarshalOutput sets the function to convert the output of the Runnable or Agent, or each chunk of the output stream, to string.
+// By default, string is returned as is, the content is returned for *schema.Message, and other types are marshaled to JSON.
+func WithMarshalOutput(m utils.MarshalOutput) Option {
+	return func(o *options) {
+		o.m = m
+	}
+}
+
+// WithSchemaCustomizer sets the schema customizer used to infer the ToolInfo from the input type, see utils.WithSchemaCustomizer.
+func WithSchemaCustomizer(sc utils.SchemaCustomizerFn) Option {
+	return func(o *options) {
+		o.sc = sc
+	}
+}
+
+type callOptions struct {
+	composeOpts []compose.Option
+	agentOpts   []agent.AgentOption
+}
+
+// WithComposeOptions forwards the compose options to the Runnable or Agent when the tool is called,
+// e.g. pass it to ToolsNode by compose.WithToolOption.
+func WithComposeOptions(opts ...compose.Option) tool.Option {
+	return tool.WrapImplSpecificOptFn(func(o *callOptions) {
+		o.composeOpts = append(o.composeOpts, opts...)
+	})
+}
+
+// WithAgentOptions forwards the agent options to the Agent when the tool is called.
+func WithAgentOptions(opts ...agent.AgentOption) tool.Option {
+	return tool.WrapImplSpecificOptFn(func(o *callOptions) {
+		o.agentOpts = append(o.agentOpts, opts...)
+	})
+}
+
+// InferRunnableTool creates a tool from the Runnable, with the ToolInfo inferred from the input type I, which is usually a struct.
+// The arguments are unmarshalled to I, and the output O is converted to string, see WithMarshalOutput.
+// The callbacks of the Runnable are nested in the callbacks of the tool, and when the Runnable is interrupted
+// inside a ToolsNode of a graph with checkpoint, the interrupt is reported as the one of a subgraph of the ToolsNode,
+// and the Runnable is resumed when the graph is resumed. Note that the other tool calls of the same message are run again after resuming.
+func InferRunnableTool[I, O any](toolName, toolDesc string, r compose.Runnable[I, O], opts ...Option) (Tool, error) {
+	o := getOptions(opts...)
+
+	var inferOpts []utils.Option
+	if o.sc != nil {
+		inferOpts = append(inferOpts, utils.WithSchemaCustomizer(o.sc))
+	}
+	info, err := utils.GoStruct2ToolInfo[I](toolName, toolDesc, inferOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer tool info of %s: %w", toolName, err)
+	}
+
+	return NewRunnableTool(info, r, opts...), nil
+}
+
+// NewRunnableTool creates a tool from the Runnable with the given ToolInfo, see InferRunnableTool.
+func NewRunnableTool[I, O any](info *schema.ToolInfo, r compose.Runnable[I, O], opts ...Option) Tool {
+	return &runnableTool[I, O]{
+		info: info,
+		typ:  "RunnableTool",
+		m:    getOptions(opts...).m,
+		invoke: func(ctx context.Context, input I, co *callOptions) (O, error) {
+			return r.Invoke(ctx, input, co.composeOpts...)
+		},
+		stream: func(ctx context.Context, input I, co *callOptions) (*schema.StreamReader[O], error) {
+			return r.Stream(ctx, input, co.composeOpts...)
+		},
+	}
+}
+
+// NewAgentTool creates a tool from the Agent, the arguments of the tool is AgentInput,
+// and the request is sent to the Agent as a user message.
+// The compose options from WithComposeOptions are passed to the Agent by agent.WithComposeOptions.
+func NewAgentTool(toolName, toolDesc string, a Agent, opts ...Option) (Tool, error) {
+	if a == nil {
+		return nil, errors.New("agent is nil")
+	}
+
+	o := getOptions(opts...)
+
+	var inferOpts []utils.Option
+	if o.sc != nil {
+		inferOpts = append(inferOpts, utils.WithSchemaCustomizer(o.sc))
+	}
+	info, err := utils.GoStruct2ToolInfo[AgentInput](toolName, toolDesc, inferOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer tool info of %s: %w", toolName, err)
+	}
+
+	agentOptions := func(co *callOptions) []agent.AgentOption {
+		if len(co.composeOpts) == 0 {
+			return co.agentOpts
+		}
+		return append(append([]agent.AgentOption{}, co.agentOpts...), agent.WithComposeOptions(co.composeOpts...))
+	}
+
+	return &runnableTool[*AgentInput, *schema.Message]{
+		info: info,
+		typ:  "AgentTool",
+		m:    o.m,
+		invoke: func(ctx context.Context, input *AgentInput, co *callOptions) (*schema.Message, error) {
+			return a.Generate(ctx, []*schema.Message{schema.UserMessage(input.Request)}, agentOptions(co)...)
+		},
+		stream: func(ctx context.Context, input *AgentInput, co *callOptions) (*schema.StreamReader[*schema.Message], error) {
+			return a.Stream(ctx, []*schema.Message{schema.UserMessage(input.Request)}, agentOptions(co)...)
+		},
+	}, nil
+}
+
+func getOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type runnableTool[I, O any] struct {
+	info *schema.ToolInfo
+	typ  string
+	m    utils.MarshalOutput
+
+	invoke func(ctx context.Context, input I, co *callOptions) (O, error)
+	stream func(ctx context.Context, input I, co *callOptions) (*schema.StreamReader[O], error)
+}
+
+func (r *runnableTool[I, O]) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return r.info, nil
+}
+
+func (r *runnableTool[I, O]) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	input, err := r.unmarshalArguments(argumentsInJSON)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := r.invoke(ctx, input, tool.GetImplSpecificOptions(&callOptions{}, opts...))
+	if err != nil {
+		return "", err
+	}
+
+	return r.marshalOutput(ctx, output)
+}
+
+func (r *runnableTool[I, O]) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	input, err := r.unmarshalArguments(argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	sr, err := r.stream(ctx, input, tool.GetImplSpecificOptions(&callOptions{}, opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.StreamReaderWithConvert(sr, func(chunk O) (string, error) {
+		return r.marshalOutput(ctx, chunk)
+	}), nil
+}
+
+func (r *runnableTool[I, O]) GetType() string {
+	return r.typ
+}
+
+func (r *runnableTool[I, O]) unmarshalArguments(argumentsInJSON string) (I, error) {
+	input := generic.NewInstance[I]()
+	if len(strings.TrimSpace(argumentsInJSON)) == 0 {
+		return input, nil
+	}
+
+	if err := sonic.UnmarshalString(argumentsInJSON, &input); err != nil {
+		return input, &tool.InvalidArgumentsError{
+			Err: fmt.Errorf("failed to unmarshal arguments of tool %s: %w", r.info.Name, err),
+		}
+	}
+
+	return input, nil
+}
+
+func (r *runnableTool[I, O]) marshalOutput(ctx context.Context, output O) (string, error) {
+	if r.m != nil {
+		return r.m(ctx, output)
+	}
+
+	switch v := any(output).(type) {
+	case string:
+		return v, nil
+	case *schema.Message:
+		if v == nil {
+			return "", nil
+		}
+		return v.Content, nil
+	default:
+		s, err := sonic.MarshalString(output)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal output of tool %s: %w", r.info.Name, err)
+		}
+		return s, nil
+	}
+}
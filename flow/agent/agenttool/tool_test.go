@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agenttool
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/schema"
+)
+
+type searchInput struct {
+	Query string `json:"query"`
+}
+
+func init() {
+	_ = compose.RegisterSerializableType[searchInput]("agenttool_test_search_input")
+}
+
+type searchOutput struct {
+	Results []string `json:"results"`
+}
+
+type inMemoryStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (i *inMemoryStore) Get(_ context.Context, checkPointID string) ([]byte, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	v, ok := i.m[checkPointID]
+	return v, ok, nil
+}
+
+func (i *inMemoryStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.m[checkPointID] = checkPoint
+	return nil
+}
+
+func newToolCallMessage(name, arguments string) *schema.Message {
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call_1",
+		Function: schema.FunctionCall{Name: name, Arguments: arguments},
+	}})
+}
+
+func TestRunnableTool(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := compose.NewChain[*searchInput, *searchOutput]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in *searchInput) (*searchOutput, error) {
+			return &searchOutput{Results: []string{in.Query + "_1", in.Query + "_2"}}, nil
+		}), compose.WithNodeName("search")).
+		Compile(ctx)
+	assert.NoError(t, err)
+
+	st, err := InferRunnableTool("search", "search the web", r)
+	assert.NoError(t, err)
+
+	info, err := st.Info(ctx)
+	assert.NoError(t, err)
+	sc, err := info.ToOpenAPIV3()
+	assert.NoError(t, err)
+	assert.Contains(t, sc.Properties, "query")
+
+	tn, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: []tool.BaseTool{st}})
+	assert.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		names []string
+	)
+	handler := callbacks.NewHandlerBuilder().OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+		mu.Lock()
+		names = append(names, info.Name)
+		mu.Unlock()
+		return ctx
+	}).Build()
+
+	out, err := tn.Invoke(ctx, newToolCallMessage("search", `{"query":"eino"}`),
+		compose.WithToolOption(WithComposeOptions(compose.WithCallbacks(handler))))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"results":["eino_1","eino_2"]}`, out[0].Content)
+	assert.Contains(t, names, "search")
+
+	sr, err := st.StreamableRun(ctx, `{"query":"go"}`)
+	assert.NoError(t, err)
+	chunk, err := sr.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"results":["go_1","go_2"]}`, chunk)
+	sr.Close()
+
+	_, err = st.InvokableRun(ctx, `{"query":`)
+	var argErr *tool.InvalidArgumentsError
+	assert.ErrorAs(t, err, &argErr)
+}
+
+type fakeAgent struct {
+	opts []agent.AgentOption
+}
+
+func (f *fakeAgent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	f.opts = opts
+	return schema.AssistantMessage("answer to "+input[0].Content, nil), nil
+}
+
+func (f *fakeAgent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+	f.opts = opts
+	return schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage("answer to ", nil),
+		schema.AssistantMessage(input[0].Content, nil),
+	}), nil
+}
+
+func TestAgentTool(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeAgent{}
+
+	at, err := NewAgentTool("expert", "ask the expert", a)
+	assert.NoError(t, err)
+
+	info, err := at.Info(ctx)
+	assert.NoError(t, err)
+	sc, err := info.ToOpenAPIV3()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"request"}, sc.Required)
+
+	out, err := at.InvokableRun(ctx, `{"request":"what is eino"}`, WithComposeOptions(compose.WithCallbacks()))
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to what is eino", out)
+	assert.Len(t, agent.GetComposeOptions(a.opts...), 1)
+
+	sr, err := at.StreamableRun(ctx, `{"request":"what is eino"}`)
+	assert.NoError(t, err)
+	var sb strings.Builder
+	for {
+		chunk, err := sr.Recv()
+		if err != nil {
+			break
+		}
+		sb.WriteString(chunk)
+	}
+	assert.Equal(t, "answer to what is eino", sb.String())
+	assert.Len(t, a.opts, 0)
+}
+
+func TestRunnableToolInterrupt(t *testing.T) {
+	ctx := context.Background()
+
+	inner := compose.NewGraph[*searchInput, string]()
+	assert.NoError(t, inner.AddLambdaNode("prepare", compose.InvokableLambda(func(ctx context.Context, in *searchInput) (string, error) {
+		return in.Query, nil
+	})))
+	assert.NoError(t, inner.AddLambdaNode("approve", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return "approved " + in, nil
+	})))
+	assert.NoError(t, inner.AddEdge(compose.START, "prepare"))
+	assert.NoError(t, inner.AddEdge("prepare", "approve"))
+	assert.NoError(t, inner.AddEdge("approve", compose.END))
+	r, err := inner.Compile(ctx, compose.WithInterruptBeforeNodes([]string{"approve"}))
+	assert.NoError(t, err)
+
+	st, err := InferRunnableTool("deploy", "deploy the service", r)
+	assert.NoError(t, err)
+	tn, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: []tool.BaseTool{st}})
+	assert.NoError(t, err)
+
+	outer := compose.NewGraph[*schema.Message, []*schema.Message]()
+	assert.NoError(t, outer.AddToolsNode("tools", tn))
+	assert.NoError(t, outer.AddEdge(compose.START, "tools"))
+	assert.NoError(t, outer.AddEdge("tools", compose.END))
+	or, err := outer.Compile(ctx, compose.WithCheckPointStore(&inMemoryStore{m: map[string][]byte{}}))
+	assert.NoError(t, err)
+
+	input := newToolCallMessage("deploy", `{"query":"eino"}`)
+	_, err = or.Invoke(ctx, input, compose.WithCheckPointID("1"))
+	info, ok := compose.ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	if assert.Contains(t, info.SubGraphs, "tools") {
+		assert.Equal(t, []string{"approve"}, info.SubGraphs["tools"].BeforeNodes)
+	}
+
+	out, err := or.Invoke(ctx, input, compose.WithCheckPointID("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "approved eino", out[0].Content)
+}
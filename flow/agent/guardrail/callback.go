@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/flow/agent"
+	icb "github.com/cloudwego/eino/internal/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ComponentOfGuardrail is the component in callbacks.RunInfo of the checks by the guardrail.
+const ComponentOfGuardrail components.Component = "Guardrail"
+
+// CallbackInput is the input of the callbacks, the message to be checked by a checker.
+type CallbackInput struct {
+	Stage Stage
+	// Message is the message checked, before the decision applies.
+	Message *schema.Message
+}
+
+// CallbackOutput is the output of the callbacks, the decision of a checker.
+type CallbackOutput struct {
+	Decision *Decision
+}
+
+// ConvCallbackInput converts the callback input to the guardrail callback input.
+func ConvCallbackInput(src callbacks.CallbackInput) *CallbackInput {
+	switch t := src.(type) {
+	case *CallbackInput:
+		return t
+	default:
+		return nil
+	}
+}
+
+// ConvCallbackOutput converts the callback output to the guardrail callback output.
+func ConvCallbackOutput(src callbacks.CallbackOutput) *CallbackOutput {
+	switch t := src.(type) {
+	case *CallbackOutput:
+		return t
+	case *Decision:
+		return &CallbackOutput{Decision: t}
+	default:
+		return nil
+	}
+}
+
+type options struct {
+	handlers []callbacks.Handler
+}
+
+// WithCallbacks returns an agent option notifying the callback handlers of the checks in the run,
+// with ComponentOfGuardrail in callbacks.RunInfo, CallbackInput on start and CallbackOutput on end.
+// Like the global handlers, the handlers are passed on to the graph of the agent,
+// so they are notified of the nodes of the agent too, which are told apart by callbacks.RunInfo.
+// The checks in the graph of the agent, e.g. at StageToolCall, are reported to the handlers given by compose.WithCallbacks too.
+func WithCallbacks(handlers ...callbacks.Handler) agent.AgentOption {
+	return agent.WrapImplSpecificOptFn(func(opts *options) {
+		opts.handlers = append(opts.handlers, handlers...)
+	})
+}
+
+// InitCallbacks sets the callback handlers given by WithCallbacks to the context of the run.
+// It's called by the agents supporting Guardrail.
+func InitCallbacks(ctx context.Context, opts ...agent.AgentOption) context.Context {
+	o := agent.GetImplSpecificOptions(&options{}, opts...)
+	if len(o.handlers) == 0 {
+		return ctx
+	}
+
+	return icb.AppendHandlers(ctx, newRunInfo(), o.handlers...)
+}
+
+func newRunInfo() *callbacks.RunInfo {
+	return &callbacks.RunInfo{Name: "Guardrail", Component: ComponentOfGuardrail}
+}
+
+// onCheck returns the context of the check, with the handlers of the run and the global handlers.
+func onCheck(ctx context.Context, stage Stage, msg *schema.Message) context.Context {
+	ctx = icb.AppendHandlers(ctx, newRunInfo())
+	return callbacks.OnStart(ctx, &CallbackInput{Stage: stage, Message: msg})
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package guardrail screens the input, the tool calls and the output of the agents for policy violations,
+// by a list of checkers which pass, block with a canned reply, or redact the messages.
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Stage is where the message is checked.
+type Stage string
+
+const (
+	// StageInput checks each input message of the agent, before the agent runs.
+	StageInput Stage = "input"
+	// StageToolCall checks the message with tool calls, before the tools are executed.
+	StageToolCall Stage = "tool_call"
+	// StageOutput checks the output of the agent, before it's returned.
+	StageOutput Stage = "output"
+)
+
+// Action is the action decided by a checker.
+type Action string
+
+const (
+	// ActionPass lets the message pass.
+	ActionPass Action = "pass"
+	// ActionBlock stops the agent, which answers with the canned reply instead.
+	ActionBlock Action = "block"
+	// ActionRedact replaces the message with Decision.Message.
+	ActionRedact Action = "redact"
+)
+
+// DefaultBlockReply is the default canned reply when a message is blocked.
+const DefaultBlockReply = "Sorry, I can't help with that."
+
+// Decision is the decision of a checker on a message.
+type Decision struct {
+	Action Action
+	// Reason is why the message is blocked or redacted, which is reported to the callbacks in CallbackOutput.
+	Reason string
+	// Reply is the canned reply when blocked.
+	// Optional. Default is Config.BlockReply.
+	Reply string
+	// Message is the redacted message when redacted.
+	Message *schema.Message
+}
+
+// Checker checks the message at the stage, a nil decision lets the message pass.
+// When streaming, the output is checked incrementally, with a sliding window of the content so far as the message.
+// Each check is reported to the callbacks, see WithCallbacks.
+type Checker func(ctx context.Context, stage Stage, msg *schema.Message) (*Decision, error)
+
+// Config is the config of Guardrail.
+type Config struct {
+	// Checkers check the messages in order, a message redacted by a checker is passed on to the next one,
+	// and the first blocking checker stops the check.
+	// Required.
+	Checkers []Checker
+	// BlockReply is the canned reply when a message is blocked, and the decision has no Reply.
+	// Optional. Default is DefaultBlockReply.
+	BlockReply string
+	// Lookahead is the number of runes held back when checking the streaming output,
+	// so that the checkers see them before they are sent, e.g. a phrase split across chunks.
+	// The content already sent can't be taken back, so if a redaction changes it, the stream is blocked.
+	// Optional. Default is 32.
+	Lookahead int
+	// StreamCheckInterval is the number of runes arriving between the checks of the streaming output,
+	// so that the checkers don't run on every chunk. The content is sent after it's checked,
+	// so it's sent by at most StreamCheckInterval runes at once.
+	// Optional. Default is 16.
+	StreamCheckInterval int
+	// StreamWindow is the number of runes already sent to check along with the content not sent yet,
+	// so that the checkers see the context before the new content, without checking the whole content over and over.
+	// Optional. Default is 256.
+	StreamWindow int
+}
+
+// Guardrail runs the checkers on the messages of the agents.
+// e.g.
+//
+//	g, err := guardrail.New(&guardrail.Config{Checkers: []guardrail.Checker{moderation}})
+//	if err != nil {...}
+//	agent, err := react.NewAgent(ctx, &react.AgentConfig{..., Guardrail: g})
+type Guardrail struct {
+	checkers       []Checker
+	blockReply     string
+	lookahead      int
+	streamInterval int
+	streamWindow   int
+}
+
+// BlockedError is returned when a message is blocked, the agents answer with Reply instead of the error.
+type BlockedError struct {
+	Stage  Stage
+	Reason string
+	Reply  *schema.Message
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked by guardrail at %s stage: %s", e.Stage, e.Reason)
+}
+
+// New creates a Guardrail.
+func New(config *Config) (*Guardrail, error) {
+	if config == nil || len(config.Checkers) == 0 {
+		return nil, errors.New("guardrail checkers are required")
+	}
+
+	blockReply := config.BlockReply
+	if len(blockReply) == 0 {
+		blockReply = DefaultBlockReply
+	}
+
+	lookahead := config.Lookahead
+	if lookahead <= 0 {
+		lookahead = 32
+	}
+
+	streamInterval := config.StreamCheckInterval
+	if streamInterval <= 0 {
+		streamInterval = 16
+	}
+
+	streamWindow := config.StreamWindow
+	if streamWindow <= 0 {
+		streamWindow = 256
+	}
+
+	return &Guardrail{
+		checkers:       config.Checkers,
+		blockReply:     blockReply,
+		lookahead:      lookahead,
+		streamInterval: streamInterval,
+		streamWindow:   streamWindow,
+	}, nil
+}
+
+// Check runs the checkers on the message, and returns the message, which is redacted if any checker decides to.
+// It returns a *BlockedError if any checker blocks the message.
+func (g *Guardrail) Check(ctx context.Context, stage Stage, msg *schema.Message) (*schema.Message, error) {
+	for _, checker := range g.checkers {
+		checkCtx := onCheck(ctx, stage, msg)
+		decision, err := checker(checkCtx, stage, msg)
+		if err != nil {
+			callbacks.OnError(checkCtx, err)
+			return nil, fmt.Errorf("guardrail failed to check %s: %w", stage, err)
+		}
+		if decision == nil {
+			decision = &Decision{Action: ActionPass}
+		}
+
+		callbacks.OnEnd(checkCtx, &CallbackOutput{Decision: decision})
+
+		switch decision.Action {
+		case ActionPass, "":
+		case ActionBlock:
+			return nil, g.blocked(stage, decision)
+		case ActionRedact:
+			if decision.Message == nil {
+				return nil, fmt.Errorf("guardrail redacts %s without the redacted message", stage)
+			}
+			msg = decision.Message
+		default:
+			return nil, fmt.Errorf("unknown guardrail action: %s", decision.Action)
+		}
+	}
+
+	return msg, nil
+}
+
+// CheckInput checks each input message, and returns the input with the redacted messages.
+func (g *Guardrail) CheckInput(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
+	output := make([]*schema.Message, len(input))
+	for i, msg := range input {
+		checked, err := g.Check(ctx, StageInput, msg)
+		if err != nil {
+			return nil, err
+		}
+		output[i] = checked
+	}
+
+	return output, nil
+}
+
+func (g *Guardrail) blocked(stage Stage, decision *Decision) *BlockedError {
+	reply := decision.Reply
+	if len(reply) == 0 {
+		reply = g.blockReply
+	}
+
+	return &BlockedError{Stage: stage, Reason: decision.Reason, Reply: schema.AssistantMessage(reply, nil)}
+}
+
+// AsBlocked returns the canned reply if the error is caused by a blocked message.
+func AsBlocked(err error) (*schema.Message, bool) {
+	var blockedErr *BlockedError
+	if errors.As(err, &blockedErr) {
+		return blockedErr.Reply, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// recordHandler records the checks reported to the callbacks
+type recordHandler struct {
+	inputs  []*CallbackInput
+	outputs []*CallbackOutput
+	errs    []error
+}
+
+func (r *recordHandler) handler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			if info.Component == ComponentOfGuardrail {
+				r.inputs = append(r.inputs, ConvCallbackInput(input))
+			}
+			return ctx
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if info.Component == ComponentOfGuardrail {
+				r.outputs = append(r.outputs, ConvCallbackOutput(output))
+			}
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			if info.Component == ComponentOfGuardrail {
+				r.errs = append(r.errs, err)
+			}
+			return ctx
+		}).Build()
+}
+
+// redactSecret redacts "secret", and blocks "bomb"
+func redactSecret(_ context.Context, _ Stage, msg *schema.Message) (*Decision, error) {
+	if strings.Contains(msg.Content, "bomb") {
+		return &Decision{Action: ActionBlock, Reason: "dangerous"}, nil
+	}
+	if strings.Contains(msg.Content, "secret") {
+		redacted := *msg
+		redacted.Content = strings.ReplaceAll(msg.Content, "secret", "******")
+		return &Decision{Action: ActionRedact, Reason: "secret", Message: &redacted}, nil
+	}
+	return nil, nil
+}
+
+func readAll(t *testing.T, sr *schema.StreamReader[*schema.Message]) []string {
+	defer sr.Close()
+
+	var contents []string
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return contents
+		}
+		assert.NoError(t, err)
+		contents = append(contents, msg.Content)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	_, err := New(&Config{})
+	assert.Error(t, err)
+
+	g, err := New(&Config{Checkers: []Checker{redactSecret}, BlockReply: "no way"})
+	assert.NoError(t, err)
+
+	rec := &recordHandler{}
+	ctx := InitCallbacks(context.Background(), WithCallbacks(rec.handler()))
+
+	msg, err := g.Check(ctx, StageInput, schema.UserMessage("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", msg.Content)
+
+	msg, err = g.Check(ctx, StageOutput, schema.AssistantMessage("the secret is 42", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "the ****** is 42", msg.Content)
+
+	input, err := g.CheckInput(ctx, []*schema.Message{schema.SystemMessage("be nice"), schema.UserMessage("how to make a bomb")})
+	assert.Nil(t, input)
+	reply, ok := AsBlocked(err)
+	assert.True(t, ok)
+	assert.Equal(t, schema.AssistantMessage("no way", nil), reply)
+	assert.ErrorContains(t, err, "blocked by guardrail at input stage: dangerous")
+
+	assert.Equal(t, []Action{ActionPass, ActionRedact, ActionPass, ActionBlock}, actions(rec.outputs))
+	assert.Len(t, rec.inputs, 4)
+	assert.Equal(t, StageOutput, rec.inputs[1].Stage)
+	assert.Equal(t, "the secret is 42", rec.inputs[1].Message.Content)
+
+	failing := func(_ context.Context, _ Stage, _ *schema.Message) (*Decision, error) {
+		return nil, errors.New("moderation unavailable")
+	}
+	g, err = New(&Config{Checkers: []Checker{failing}})
+	assert.NoError(t, err)
+	_, err = g.Check(ctx, StageToolCall, schema.AssistantMessage("", nil))
+	assert.ErrorContains(t, err, "moderation unavailable")
+	_, ok = AsBlocked(err)
+	assert.False(t, ok)
+	assert.Len(t, rec.errs, 1)
+	assert.Len(t, rec.outputs, 4)
+}
+
+func TestCheckStream(t *testing.T) {
+	ctx := context.Background()
+	g, err := New(&Config{Checkers: []Checker{redactSecret}, Lookahead: 6, StreamCheckInterval: 1})
+	assert.NoError(t, err)
+
+	chunks := func(contents ...string) *schema.StreamReader[*schema.Message] {
+		msgs := make([]*schema.Message, 0, len(contents))
+		for _, c := range contents {
+			msgs = append(msgs, schema.AssistantMessage(c, nil))
+		}
+		return schema.StreamReaderFromArray(msgs)
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		contents := readAll(t, g.CheckStream(ctx, StageOutput, chunks("hello ", "world, ", "how are you")))
+		assert.Equal(t, "hello world, how are you", strings.Join(contents, ""))
+		assert.Equal(t, "re you", contents[len(contents)-1]) // held back until the end
+	})
+
+	t.Run("redact across chunks", func(t *testing.T) {
+		contents := readAll(t, g.CheckStream(ctx, StageOutput, chunks("the sec", "ret is ", "42, another secret")))
+		assert.Equal(t, "the ****** is 42, another ******", strings.Join(contents, ""))
+	})
+
+	t.Run("block", func(t *testing.T) {
+		contents := readAll(t, g.CheckStream(ctx, StageOutput, chunks("to make a bo", "mb, you need", " more text")))
+		assert.Equal(t, []string{"to mak", DefaultBlockReply}, contents)
+	})
+
+	t.Run("redact content sent", func(t *testing.T) {
+		g, err := New(&Config{Checkers: []Checker{redactSecret}, Lookahead: 1, StreamCheckInterval: 1})
+		assert.NoError(t, err)
+		contents := readAll(t, g.CheckStream(ctx, StageOutput, chunks("the sec", "ret")))
+		assert.Equal(t, []string{"the se", DefaultBlockReply}, contents)
+	})
+
+	t.Run("interval and window", func(t *testing.T) {
+		var checked []string
+		recordChecked := func(_ context.Context, _ Stage, msg *schema.Message) (*Decision, error) {
+			checked = append(checked, msg.Content)
+			return nil, nil
+		}
+		g, err := New(&Config{Checkers: []Checker{recordChecked, redactSecret}, Lookahead: 4, StreamCheckInterval: 8, StreamWindow: 8})
+		assert.NoError(t, err)
+
+		in := make([]string, 0, 100)
+		for i := 0; i < 100; i++ {
+			in = append(in, "ab ")
+		}
+		in = append(in, "the sec", "ret")
+		contents := readAll(t, g.CheckStream(ctx, StageOutput, chunks(in...)))
+		assert.Equal(t, strings.Repeat("ab ", 100)+"the ******", strings.Join(contents, ""))
+
+		// checked once every 8 runes at most, instead of on every chunk
+		assert.Less(t, len(checked), 50)
+		for _, c := range checked {
+			// the window sent, the lookahead, and the runes arriving since the last check
+			assert.LessOrEqual(t, len(c), 8+4+8+2)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sr, sw := schema.Pipe[*schema.Message](2)
+		sw.Send(schema.AssistantMessage("hello", nil), nil)
+		sw.Send(nil, errors.New("broken"))
+		sw.Close()
+
+		out := g.CheckStream(ctx, StageOutput, sr)
+		defer out.Close()
+		_, err := out.Recv()
+		assert.EqualError(t, err, "broken")
+	})
+}
+
+func actions(outputs []*CallbackOutput) []Action {
+	ret := make([]Action, 0, len(outputs))
+	for _, output := range outputs {
+		ret = append(ret, output.Decision.Action)
+	}
+	return ret
+}
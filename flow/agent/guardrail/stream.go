@@ -0,0 +1,161 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/debug"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// CheckStream checks the streaming message incrementally, before the content is sent.
+// The last Lookahead runes are held back until more content arrives or the stream ends,
+// so that the checkers see them in context before they are sent.
+// The content is checked each time StreamCheckInterval runes arrive, along with the last StreamWindow runes sent.
+// When blocked, the stream ends with the canned reply as the last chunk, after the content already sent.
+func (g *Guardrail) CheckStream(ctx context.Context, stage Stage, sr *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	out, sw := schema.Pipe[*schema.Message](1)
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				_ = sw.Send(nil, safe.NewPanicErr(panicErr, debug.Stack()))
+			}
+			sw.Close()
+		}()
+		defer sr.Close()
+
+		s := &streamChecker{g: g, stage: stage, sw: sw}
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				s.flush(ctx)
+				return
+			}
+			if err != nil {
+				_ = sw.Send(nil, err)
+				return
+			}
+			if chunk == nil {
+				continue
+			}
+			if !s.add(ctx, chunk) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// streamChecker holds the content not sent yet, and checks it along with the tail of the content sent, before sending it.
+// The content is checked once StreamCheckInterval runes arrive beyond Lookahead, instead of on every chunk.
+type streamChecker struct {
+	g     *Guardrail
+	stage Stage
+	sw    *schema.StreamWriter[*schema.Message]
+
+	role      schema.RoleType
+	sent      string // the last StreamWindow runes sent
+	pending   string
+	unchecked bool // whether the content not sent has changed since the last check
+}
+
+// add returns false if the stream should stop.
+func (s *streamChecker) add(ctx context.Context, chunk *schema.Message) bool {
+	if len(s.role) == 0 {
+		s.role = chunk.Role
+	}
+	if len(chunk.Content) > 0 {
+		s.pending += chunk.Content
+		s.unchecked = true
+	}
+
+	var release string
+	if n := utf8.RuneCountInString(s.pending) - s.g.lookahead; n >= s.g.streamInterval {
+		if !s.check(ctx) {
+			return false
+		}
+		if n = utf8.RuneCountInString(s.pending) - s.g.lookahead; n > 0 {
+			release = string([]rune(s.pending)[:n])
+		}
+	}
+
+	if len(release) == 0 && !hasMoreThanContent(chunk) {
+		return true
+	}
+
+	s.sent = lastRunes(s.sent+release, s.g.streamWindow)
+	s.pending = s.pending[len(release):]
+
+	out := *chunk
+	out.Content = release
+	return !s.sw.Send(&out, nil)
+}
+
+func (s *streamChecker) flush(ctx context.Context) {
+	if s.unchecked && !s.check(ctx) {
+		return
+	}
+	if len(s.pending) > 0 {
+		_ = s.sw.Send(&schema.Message{Role: s.role, Content: s.pending}, nil)
+	}
+}
+
+// check returns false if the stream is blocked or fails.
+func (s *streamChecker) check(ctx context.Context) bool {
+	content := s.sent + s.pending
+	checked, err := s.g.Check(ctx, s.stage, &schema.Message{Role: s.role, Content: content})
+	if err != nil {
+		var blockedErr *BlockedError
+		if errors.As(err, &blockedErr) {
+			_ = s.sw.Send(blockedErr.Reply, nil)
+		} else {
+			_ = s.sw.Send(nil, err)
+		}
+		return false
+	}
+
+	if checked.Content != content {
+		if !strings.HasPrefix(checked.Content, s.sent) {
+			_ = s.sw.Send(s.g.blocked(s.stage, &Decision{Action: ActionBlock, Reason: "redacting the content already sent"}).Reply, nil)
+			return false
+		}
+		s.pending = checked.Content[len(s.sent):]
+	}
+	s.unchecked = false
+
+	return true
+}
+
+func lastRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[len(runes)-n:])
+}
+
+func hasMoreThanContent(msg *schema.Message) bool {
+	return len(msg.ToolCalls) > 0 || len(msg.MultiContent) > 0 || msg.ResponseMeta != nil || len(msg.Extra) > 0
+}
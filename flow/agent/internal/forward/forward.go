@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package forward streams the chunks read ahead by the StreamToolCallChecker of the agents to the caller,
+// instead of holding them until the checker decides whether the chat model calls tools.
+package forward
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/debug"
+	"sync"
+
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// runKey keys the forwarder of a run by the agent owning it, so that the agents nested in the run don't pick it up.
+type runKey struct {
+	owner any
+}
+
+type chunkKey struct{}
+
+// forwarder sends the chunks forwarded by the checker, and the output of the run if it's not forwarded, to the caller.
+type forwarder struct {
+	sw *schema.StreamWriter[*schema.Message]
+
+	ready chan error
+	once  sync.Once
+
+	mu        sync.Mutex
+	forwarded bool // whether the checker forwards any chunk in the current check
+	answered  bool // whether the final answer is forwarded by the checker
+}
+
+// Stream calls run in background, with the context from which Check forwards the chunks the checker reads ahead to the caller.
+// It returns as soon as the first chunk is forwarded, or run returns, in which case the error of run is returned.
+// The output of run is streamed after the chunks forwarded, unless the final answer has been forwarded.
+func Stream(ctx context.Context, owner any, run func(ctx context.Context) (*schema.StreamReader[*schema.Message], error)) (
+	*schema.StreamReader[*schema.Message], error) {
+	sr, sw := schema.Pipe[*schema.Message](10)
+	f := &forwarder{sw: sw, ready: make(chan error, 1)}
+	ctx = context.WithValue(ctx, runKey{owner: owner}, f)
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				err := safe.NewPanicErr(panicErr, debug.Stack())
+				if !f.setReady(err) {
+					_ = sw.Send(nil, err)
+				}
+			}
+			sw.Close()
+		}()
+
+		output, err := run(ctx)
+		if err != nil {
+			if !f.setReady(err) {
+				_ = sw.Send(nil, err)
+			}
+			return
+		}
+		f.setReady(nil)
+		defer output.Close()
+
+		f.mu.Lock()
+		answered := f.answered
+		f.mu.Unlock()
+		if answered {
+			return
+		}
+
+		for {
+			chunk, err := output.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				_ = sw.Send(nil, err)
+				return
+			}
+			if sw.Send(chunk, nil) {
+				return
+			}
+		}
+	}()
+
+	if err := <-f.ready; err != nil {
+		sr.Close()
+		return nil, err
+	}
+	return sr, nil
+}
+
+// Check runs the checker on the output of the chat model, with the context to forward the chunks by Chunk,
+// if the run of owner is called by Stream. Otherwise, the checker runs as it is.
+// A final answer forwarded by the checker is dropped from the output of the run.
+func Check(ctx context.Context, owner any, checker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error),
+	sr *schema.StreamReader[*schema.Message]) (bool, error) {
+	f, ok := ctx.Value(runKey{owner: owner}).(*forwarder)
+	if !ok {
+		return checker(ctx, sr)
+	}
+
+	f.mu.Lock()
+	f.forwarded = false
+	f.mu.Unlock()
+
+	isToolCall, err := checker(context.WithValue(ctx, chunkKey{}, f), sr)
+	if err == nil && !isToolCall {
+		f.mu.Lock()
+		f.answered = f.forwarded
+		f.mu.Unlock()
+	}
+	return isToolCall, err
+}
+
+// Chunk forwards the chunk read ahead by the checker to the caller, and returns false if the chunk can't be forwarded.
+// A checker forwarding the chunks must forward all the chunks of the final answer, before it returns false.
+func Chunk(ctx context.Context, chunk *schema.Message) bool {
+	f, ok := ctx.Value(chunkKey{}).(*forwarder)
+	if !ok {
+		return false
+	}
+
+	f.mu.Lock()
+	f.forwarded = true
+	f.mu.Unlock()
+
+	f.setReady(nil)
+	_ = f.sw.Send(chunk, nil)
+	return true
+}
+
+// setReady returns false if the caller is ready already.
+func (f *forwarder) setReady(err error) bool {
+	set := false
+	f.once.Do(func() {
+		f.ready <- err
+		set = true
+	})
+	return set
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package forward
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func forwardAll(ctx context.Context, sr *schema.StreamReader[*schema.Message]) (bool, error) {
+	defer sr.Close()
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(msg.ToolCalls) > 0 {
+			return true, nil
+		}
+		Chunk(ctx, msg)
+	}
+}
+
+func recvAll(t *testing.T, sr *schema.StreamReader[*schema.Message]) string {
+	defer sr.Close()
+	var content string
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return content
+		}
+		assert.NoError(t, err)
+		content += msg.Content
+	}
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	owner := new(int)
+
+	t.Run("answer forwarded", func(t *testing.T) {
+		sr, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			output := schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("hello ", nil), schema.AssistantMessage("world", nil)})
+			copies := output.Copy(2)
+			isToolCall, err := Check(ctx, owner, forwardAll, copies[0])
+			assert.NoError(t, err)
+			assert.False(t, isToolCall)
+			return copies[1], nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", recvAll(t, sr))
+	})
+
+	t.Run("text before tool calls forwarded", func(t *testing.T) {
+		sr, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			isToolCall, err := Check(ctx, owner, forwardAll, schema.StreamReaderFromArray([]*schema.Message{
+				schema.AssistantMessage("let me check", nil),
+				schema.AssistantMessage("", []schema.ToolCall{{ID: "1"}}),
+			}))
+			assert.NoError(t, err)
+			assert.True(t, isToolCall)
+			return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(", done", nil)}), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "let me check, done", recvAll(t, sr))
+	})
+
+	t.Run("other owner", func(t *testing.T) {
+		sr, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			_, err := Check(ctx, new(int), forwardAll, schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("nested", nil)}))
+			assert.NoError(t, err)
+			return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("answer", nil)}), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "answer", recvAll(t, sr))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errRun := errors.New("run failed")
+		_, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			return nil, errRun
+		})
+		assert.ErrorIs(t, err, errRun)
+
+		sr, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			_, _ = Check(ctx, owner, forwardAll, schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("partial", nil)}))
+			return nil, errRun
+		})
+		assert.NoError(t, err)
+		msg, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "partial", msg.Content)
+		_, err = sr.Recv()
+		assert.ErrorIs(t, err, errRun)
+		sr.Close()
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		_, err := Stream(ctx, owner, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+			panic("oops")
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,280 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory provides the conversation memory of agents, which saves the messages of each session into a Store,
+// so that the callers only need to pass the new messages of each round.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/internal/ctxutil"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Store saves the messages of sessions.
+type Store interface {
+	// Load returns all the messages of the session in order, and an empty slice if the session doesn't exist.
+	Load(ctx context.Context, sessionID string) ([]*schema.Message, error)
+	// Append adds the messages to the end of the session.
+	Append(ctx context.Context, sessionID string, msgs ...*schema.Message) error
+	// Clear removes all the messages of the session.
+	Clear(ctx context.Context, sessionID string) error
+}
+
+// Config is the config for NewMemory.
+type Config struct {
+	// Store saves the messages of sessions.
+	Store Store
+	// OnSaveError is called when the messages of a streaming run fail to be saved,
+	// as the output stream has been returned to the caller by then.
+	// The errors of saving non-streaming runs are returned by Memory.Generate instead.
+	// Optional. By default, the errors of saving streaming runs are ignored.
+	OnSaveError func(ctx context.Context, sessionID string, err error)
+}
+
+// Memory loads the history of the session before running an agent, and saves the new messages after the run.
+// The runs on the same session are serialized, so that each of them sees the complete history of the runs before.
+// Set it to the config of the agents, e.g. react.AgentConfig.Memory, and pass the session id by WithSessionID when calling them.
+type Memory struct {
+	store       Store
+	onSaveError func(ctx context.Context, sessionID string, err error)
+
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewMemory creates a Memory.
+func NewMemory(config *Config) (*Memory, error) {
+	if config == nil || config.Store == nil {
+		return nil, errors.New("memory store is required")
+	}
+
+	return &Memory{
+		store:       config.Store,
+		onSaveError: config.OnSaveError,
+		locks:       make(map[string]*sessionLock),
+	}, nil
+}
+
+// Store returns the store of the memory, e.g. to clear a session.
+func (m *Memory) Store() Store {
+	return m.store
+}
+
+func (m *Memory) lock(sessionID string) (unlock func()) {
+	m.mu.Lock()
+	l, ok := m.locks[sessionID]
+	if !ok {
+		l = &sessionLock{}
+		m.locks[sessionID] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Unlock()
+
+			m.mu.Lock()
+			l.refs--
+			if l.refs == 0 {
+				delete(m.locks, sessionID)
+			}
+			m.mu.Unlock()
+		})
+	}
+}
+
+// Generate runs the agent with the history of the session followed by the input,
+// and appends the input, the messages reported by SetRunMessages and the output to the session.
+func (m *Memory) Generate(ctx context.Context, sessionID string, input []*schema.Message,
+	run func(ctx context.Context, input []*schema.Message) (*schema.Message, error)) (*schema.Message, error) {
+
+	unlock := m.lock(sessionID)
+	defer unlock()
+
+	history, err := m.store.Load(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memory of session %s: %w", sessionID, err)
+	}
+
+	rec := &recorder{sessionID: sessionID}
+	output, err := run(withRecorder(ctx, rec), concatMessages(history, input))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.store.Append(ctx, sessionID, rec.newMessages(history, input, output)...); err != nil {
+		return nil, fmt.Errorf("failed to save memory of session %s: %w", sessionID, err)
+	}
+
+	return output, nil
+}
+
+// Stream is like Generate, but the messages are appended after the output stream is fully received.
+// The session is locked until then. The messages are saved even if ctx is canceled after Stream returns,
+// and the error of saving them is reported to Config.OnSaveError, as the output has been returned.
+// Nothing is saved if the output stream fails.
+func (m *Memory) Stream(ctx context.Context, sessionID string, input []*schema.Message,
+	run func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error)) (
+	*schema.StreamReader[*schema.Message], error) {
+
+	unlock := m.lock(sessionID)
+
+	history, err := m.store.Load(ctx, sessionID)
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("failed to load memory of session %s: %w", sessionID, err)
+	}
+
+	rec := &recorder{sessionID: sessionID}
+	sr, err := run(withRecorder(ctx, rec), concatMessages(history, input))
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	saveCtx := ctxutil.WithoutCancel(ctx)
+	copies := sr.Copy(2)
+	go func() {
+		defer unlock()
+
+		output, err := concatStream(copies[1])
+		if err != nil {
+			return
+		}
+
+		if err = m.store.Append(saveCtx, sessionID, rec.newMessages(history, input, output)...); err != nil && m.onSaveError != nil {
+			m.onSaveError(saveCtx, sessionID, fmt.Errorf("failed to save memory of session %s: %w", sessionID, err))
+		}
+	}()
+
+	return copies[0], nil
+}
+
+func concatStream(sr *schema.StreamReader[*schema.Message]) (*schema.Message, error) {
+	defer sr.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	return schema.ConcatMessages(chunks)
+}
+
+func concatMessages(history, input []*schema.Message) []*schema.Message {
+	ret := make([]*schema.Message, 0, len(history)+len(input))
+	ret = append(ret, history...)
+	return append(ret, input...)
+}
+
+type sessionIDOptions struct {
+	sessionID string
+}
+
+// WithSessionID sets the session id of the run, the agents without Memory configured ignore it.
+func WithSessionID(sessionID string) agent.AgentOption {
+	return agent.WrapImplSpecificOptFn(func(o *sessionIDOptions) {
+		o.sessionID = sessionID
+	})
+}
+
+// GetSessionID returns the session id set by WithSessionID, the run is stateless if it's empty.
+func GetSessionID(opts ...agent.AgentOption) string {
+	return agent.GetImplSpecificOptions(&sessionIDOptions{}, opts...).sessionID
+}
+
+type recorderKey struct{}
+
+type recorder struct {
+	sessionID string
+
+	mu   sync.Mutex
+	msgs []*schema.Message
+	set  bool
+}
+
+func withRecorder(ctx context.Context, rec *recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+// SessionIDFromContext returns the session id of the run started by Memory, or empty if the run is not started by Memory.
+func SessionIDFromContext(ctx context.Context) string {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok || rec == nil {
+		return ""
+	}
+	return rec.sessionID
+}
+
+// SetRunMessages reports all the messages of the current run so far, starting with the history and the input,
+// e.g. including the tool calls and the tool results of a ReAct agent, which are saved along with the output.
+// It's called by the agents supporting Memory, and does nothing if the run is not started by Memory.
+func SetRunMessages(ctx context.Context, msgs []*schema.Message) {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok || rec == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.msgs = append(rec.msgs[:0], msgs...)
+	rec.set = true
+}
+
+func (r *recorder) newMessages(history, input []*schema.Message, output *schema.Message) []*schema.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ret []*schema.Message
+	if r.set && len(r.msgs) >= len(history)+len(input) {
+		ret = append(ret, r.msgs[len(history):]...)
+	} else {
+		ret = append(ret, input...)
+	}
+
+	if output != nil {
+		ret = append(ret, output)
+	}
+
+	return ret
+}
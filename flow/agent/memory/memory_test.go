@@ -0,0 +1,189 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := NewMemory(&Config{Store: NewInMemoryStore()})
+	assert.NoError(t, err)
+
+	t.Run("generate", func(t *testing.T) {
+		assert.Empty(t, SessionIDFromContext(ctx))
+
+		out, err := m.Generate(ctx, "s1", []*schema.Message{schema.UserMessage("hi")},
+			func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+				assert.Len(t, input, 1)
+				assert.Equal(t, "s1", SessionIDFromContext(ctx))
+				return schema.AssistantMessage("hello", nil), nil
+			})
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", out.Content)
+
+		toolCall := schema.AssistantMessage("", []schema.ToolCall{{ID: "1"}})
+		toolResult := schema.ToolMessage("result", "1")
+		_, err = m.Generate(ctx, "s1", []*schema.Message{schema.UserMessage("use tool")},
+			func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+				assert.Len(t, input, 3)
+				SetRunMessages(ctx, append(input, toolCall))
+				SetRunMessages(ctx, append(input, toolCall, toolResult))
+				return schema.AssistantMessage("done", nil), nil
+			})
+		assert.NoError(t, err)
+
+		history, err := m.Store().Load(ctx, "s1")
+		assert.NoError(t, err)
+		contents := make([]string, len(history))
+		for i, msg := range history {
+			contents[i] = msg.Content
+		}
+		assert.Equal(t, []string{"hi", "hello", "use tool", "", "result", "done"}, contents)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		sr, err := m.Stream(ctx, "s2", []*schema.Message{schema.UserMessage("hi")},
+			func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+				return schema.StreamReaderFromArray([]*schema.Message{
+					schema.AssistantMessage("hel", nil),
+					schema.AssistantMessage("lo", nil),
+				}), nil
+			})
+		assert.NoError(t, err)
+		for {
+			if _, err = sr.Recv(); err == io.EOF {
+				break
+			}
+		}
+		sr.Close()
+
+		// the next run waits until the messages of the stream are saved
+		_, err = m.Generate(ctx, "s2", []*schema.Message{schema.UserMessage("again")},
+			func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+				if assert.Len(t, input, 3) {
+					assert.Equal(t, "hello", input[1].Content)
+				}
+				return schema.AssistantMessage("ok", nil), nil
+			})
+		assert.NoError(t, err)
+	})
+
+	t.Run("stream save", func(t *testing.T) {
+		store := &failingStore{InMemoryStore: NewInMemoryStore()}
+		saveErrs := make(chan error, 1)
+		m, err := NewMemory(&Config{
+			Store: store,
+			OnSaveError: func(ctx context.Context, sessionID string, err error) {
+				assert.Equal(t, "s4", sessionID)
+				saveErrs <- err
+			},
+		})
+		assert.NoError(t, err)
+
+		run := func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+			return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("hello", nil)}), nil
+		}
+		drain := func(sr *schema.StreamReader[*schema.Message]) {
+			defer sr.Close()
+			for {
+				if _, err := sr.Recv(); err != nil {
+					return
+				}
+			}
+		}
+
+		// the messages are saved even if the ctx is canceled once the stream is returned
+		cctx, cancel := context.WithCancel(ctx)
+		sr, err := m.Stream(cctx, "s4", []*schema.Message{schema.UserMessage("hi")}, run)
+		assert.NoError(t, err)
+		cancel()
+		drain(sr)
+
+		assert.Eventually(t, func() bool {
+			history, err := store.InMemoryStore.Load(ctx, "s4")
+			return err == nil && len(history) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		store.err = errors.New("store down")
+		sr, err = m.Stream(ctx, "s4", []*schema.Message{schema.UserMessage("again")}, run)
+		assert.NoError(t, err)
+		drain(sr)
+		assert.ErrorIs(t, <-saveErrs, store.err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := m.Generate(ctx, "s3", []*schema.Message{schema.UserMessage(fmt.Sprint(i))},
+					func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+						// each run sees the complete rounds before
+						assert.Equal(t, 1, len(input)%2)
+						return schema.AssistantMessage(fmt.Sprint(i), nil), nil
+					})
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		history, err := m.Store().Load(ctx, "s3")
+		assert.NoError(t, err)
+		assert.Len(t, history, 20)
+		for i := 0; i < len(history); i += 2 {
+			assert.Equal(t, history[i].Content, history[i+1].Content)
+		}
+		assert.Len(t, m.locks, 0)
+	})
+
+	t.Run("session id", func(t *testing.T) {
+		assert.Equal(t, "", GetSessionID())
+		assert.Equal(t, "abc", GetSessionID(WithSessionID("abc")))
+	})
+
+	_, err = NewMemory(&Config{})
+	assert.Error(t, err)
+}
+
+type failingStore struct {
+	*InMemoryStore
+	err error
+}
+
+func (f *failingStore) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return f.InMemoryStore.Append(ctx, sessionID, msgs...)
+}
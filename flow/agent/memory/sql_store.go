@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const defaultTableName = "eino_agent_messages"
+
+// SQLStoreConfig is the config for NewSQLStore.
+type SQLStoreConfig struct {
+	// DB is the database, the table must exist with the columns below, e.g. in MySQL:
+	//
+	//	CREATE TABLE eino_agent_messages (
+	//		session_id VARCHAR(255) NOT NULL,
+	//		seq        BIGINT       NOT NULL,
+	//		message    TEXT         NOT NULL,
+	//		PRIMARY KEY (session_id, seq)
+	//	);
+	DB *sql.DB
+	// Table is the name of the table.
+	// Optional. Default is eino_agent_messages.
+	Table string
+	// Placeholder returns the placeholder of the i-th (from 1) argument in the statements,
+	// e.g. func(i int) string { return fmt.Sprintf("$%d", i) } for PostgreSQL.
+	// Optional. Default is "?".
+	Placeholder func(i int) string
+}
+
+// SQLStore saves the messages in a SQL database, one message in JSON per row.
+type SQLStore struct {
+	db *sql.DB
+
+	loadSQL   string
+	maxSeqSQL string
+	insertSQL string
+	clearSQL  string
+}
+
+var tableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// NewSQLStore creates a SQLStore.
+func NewSQLStore(config *SQLStoreConfig) (*SQLStore, error) {
+	if config == nil || config.DB == nil {
+		return nil, errors.New("db of sql store is required")
+	}
+
+	table := config.Table
+	if table == "" {
+		table = defaultTableName
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+
+	ph := config.Placeholder
+	if ph == nil {
+		ph = func(int) string { return "?" }
+	}
+
+	return &SQLStore{
+		db:        config.DB,
+		loadSQL:   fmt.Sprintf("SELECT message FROM %s WHERE session_id = %s ORDER BY seq", table, ph(1)),
+		maxSeqSQL: fmt.Sprintf("SELECT COALESCE(MAX(seq), 0) FROM %s WHERE session_id = %s", table, ph(1)),
+		insertSQL: fmt.Sprintf("INSERT INTO %s (session_id, seq, message) VALUES (%s, %s, %s)", table, ph(1), ph(2), ph(3)),
+		clearSQL:  fmt.Sprintf("DELETE FROM %s WHERE session_id = %s", table, ph(1)),
+	}, nil
+}
+
+func (s *SQLStore) Load(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	rows, err := s.db.QueryContext(ctx, s.loadSQL, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	msgs := make([]*schema.Message, 0)
+	for rows.Next() {
+		var data string
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		msg := &schema.Message{}
+		if err = sonic.UnmarshalString(data, msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message of session %s: %w", sessionID, err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, rows.Err()
+}
+
+func (s *SQLStore) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) (err error) {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var seq int64
+	if err = tx.QueryRowContext(ctx, s.maxSeqSQL, sessionID).Scan(&seq); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		data, mErr := sonic.MarshalString(msg)
+		if mErr != nil {
+			err = fmt.Errorf("failed to marshal message of session %s: %w", sessionID, mErr)
+			return err
+		}
+
+		seq++
+		if _, err = tx.ExecContext(ctx, s.insertSQL, sessionID, seq, data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) Clear(ctx context.Context, sessionID string) error {
+	_, err := s.db.ExecContext(ctx, s.clearSQL, sessionID)
+	return err
+}
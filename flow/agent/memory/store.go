@@ -0,0 +1,166 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// InMemoryStore keeps the messages in memory, which are lost when the process exits.
+type InMemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string][]*schema.Message
+}
+
+// NewInMemoryStore creates an InMemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{sessions: make(map[string][]*schema.Message)}
+}
+
+func (s *InMemoryStore) Load(_ context.Context, sessionID string) ([]*schema.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := s.sessions[sessionID]
+	ret := make([]*schema.Message, len(msgs))
+	copy(ret, msgs)
+	return ret, nil
+}
+
+func (s *InMemoryStore) Append(_ context.Context, sessionID string, msgs ...*schema.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[sessionID] = append(s.sessions[sessionID], msgs...)
+	return nil
+}
+
+func (s *InMemoryStore) Clear(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// FileStore saves the messages of each session in a file under the directory, one message in JSON per line.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore, the directory is created if it doesn't exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("directory of file store is required")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory of file store: %w", err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// path encodes the session id, so that any session id is a valid file name.
+func (s *FileStore) path(sessionID string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(sessionID))+".jsonl")
+}
+
+func (s *FileStore) Load(_ context.Context, sessionID string) ([]*schema.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*schema.Message{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var msgs []*schema.Message
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 1 {
+			msg := &schema.Message{}
+			if uErr := sonic.Unmarshal(line, msg); uErr != nil {
+				return nil, fmt.Errorf("failed to unmarshal message of session %s: %w", sessionID, uErr)
+			}
+			msgs = append(msgs, msg)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return msgs, nil
+}
+
+func (s *FileStore) Append(_ context.Context, sessionID string, msgs ...*schema.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, msg := range msgs {
+		line, err := sonic.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message of session %s: %w", sessionID, err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path(sessionID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (s *FileStore) Clear(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(sessionID))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
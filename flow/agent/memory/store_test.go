@@ -0,0 +1,197 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	msgs, err := store.Load(ctx, "a/../b")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 0)
+
+	assert.NoError(t, store.Append(ctx, "a/../b", schema.UserMessage("hi"), schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "1",
+		Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"eino"}`},
+	}})))
+	assert.NoError(t, store.Append(ctx, "a/../b", schema.ToolMessage("result\nline 2", "1")))
+	assert.NoError(t, store.Append(ctx, "other", schema.UserMessage("other")))
+
+	msgs, err = store.Load(ctx, "a/../b")
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 3) {
+		assert.Equal(t, schema.User, msgs[0].Role)
+		assert.Equal(t, "search", msgs[1].ToolCalls[0].Function.Name)
+		assert.Equal(t, "result\nline 2", msgs[2].Content)
+		assert.Equal(t, "1", msgs[2].ToolCallID)
+	}
+
+	assert.NoError(t, store.Clear(ctx, "a/../b"))
+	msgs, err = store.Load(ctx, "a/../b")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 0)
+
+	msgs, err = store.Load(ctx, "other")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.NoError(t, store.Clear(ctx, "other"))
+}
+
+func TestInMemoryStore(t *testing.T) {
+	testStore(t, NewInMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	testStore(t, store)
+}
+
+func TestSQLStore(t *testing.T) {
+	db, err := sql.Open("fake_memory_sql", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewSQLStore(&SQLStoreConfig{DB: db})
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	_, err = NewSQLStore(&SQLStoreConfig{DB: db, Table: "messages; DROP TABLE users"})
+	assert.Error(t, err)
+}
+
+func init() {
+	sql.Register("fake_memory_sql", &fakeDriver{rows: map[string]map[int64]string{}})
+}
+
+// fakeDriver is an in memory driver which only understands the statements of SQLStore.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows map[string]map[int64]string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error { return nil }
+
+func (c *fakeConn) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	session := args[0].(string)
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO eino_agent_messages"):
+		if s.d.rows[session] == nil {
+			s.d.rows[session] = map[int64]string{}
+		}
+		s.d.rows[session][args[1].(int64)] = args[2].(string)
+	case strings.HasPrefix(s.query, "DELETE FROM eino_agent_messages"):
+		delete(s.d.rows, session)
+	default:
+		return nil, errors.New("unknown exec: " + s.query)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	session := args[0].(string)
+	var seqs []int64
+	for seq := range s.d.rows[session] {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	switch {
+	case strings.HasPrefix(s.query, "SELECT message FROM eino_agent_messages"):
+		values := make([]driver.Value, len(seqs))
+		for i, seq := range seqs {
+			values[i] = s.d.rows[session][seq]
+		}
+		return &fakeRows{column: "message", values: values}, nil
+	case strings.HasPrefix(s.query, "SELECT COALESCE(MAX(seq), 0) FROM eino_agent_messages"):
+		var maxSeq int64
+		if len(seqs) > 0 {
+			maxSeq = seqs[len(seqs)-1]
+		}
+		return &fakeRows{column: "seq", values: []driver.Value{maxSeq}}, nil
+	default:
+		return nil, errors.New("unknown query: " + s.query)
+	}
+}
+
+type fakeRows struct {
+	column string
+	values []driver.Value
+	idx    int
+}
+
+func (r *fakeRows) Columns() []string { return []string{r.column} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.idx]
+	r.idx++
+	return nil
+}
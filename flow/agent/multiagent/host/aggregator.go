@@ -0,0 +1,278 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+const defaultAggregatorPrompt = "combine the answers of the specialists into one complete and coherent response to the user, " +
+	"resolving any conflicts between them."
+
+// errOutputClosed stops receiving the chunks of the specialists when the output is closed by the caller.
+var errOutputClosed = errors.New("output closed")
+
+// ExtraKeySpecialist is the key of Message.Extra of the answers passed to Aggregator.Combine,
+// and of the chunks of the specialists streamed in parallel hand off, whose value is the name of the specialist.
+const ExtraKeySpecialist = "_host_specialist"
+
+// GetSpecialistName returns the name of the specialist giving the answer passed to Aggregator.Combine,
+// or the chunk streamed in parallel hand off, and empty for the combined answer.
+func GetSpecialistName(answer *schema.Message) string {
+	if answer == nil {
+		return ""
+	}
+	name, _ := answer.Extra[ExtraKeySpecialist].(string)
+	return name
+}
+
+// addAggregator adds the node combining the answers of the specialists handed off to in parallel,
+// whose input is the map from the names of the specialists to their answers.
+// If only one specialist is called, Invoke and Stream output its answer.
+// Otherwise, Invoke outputs the combined answer, while Stream outputs the chunks of the specialists as they arrive,
+// tagged with the names of the specialists by ExtraKeySpecialist, followed by the chunks of the combined answer, which are not tagged.
+func addAggregator(aggregator *Aggregator, g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+	invoke := func(ctx context.Context, in map[string]any) (*schema.Message, error) {
+		input, answers, err := orderAnswers(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) == 1 {
+			return in[GetSpecialistName(answers[0])].(*schema.Message), nil
+		}
+
+		return aggregate(ctx, aggregator, input, answers)
+	}
+
+	transform := func(ctx context.Context, sr *schema.StreamReader[map[string]any]) (*schema.StreamReader[*schema.Message], error) {
+		var (
+			input      []*schema.Message
+			dispatched []string
+		)
+		err := compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			input, dispatched = state.msgs, state.dispatched
+			return nil
+		})
+		if err != nil {
+			sr.Close()
+			return nil, err
+		}
+
+		if len(dispatched) == 1 {
+			name := dispatched[0]
+			return schema.StreamReaderWithConvert(sr, func(chunk map[string]any) (*schema.Message, error) {
+				msg, ok := chunk[name].(*schema.Message)
+				if !ok || msg == nil {
+					return nil, schema.ErrNoValue
+				}
+				return msg, nil
+			}), nil
+		}
+
+		out, sw := schema.Pipe[*schema.Message](10)
+		go func() {
+			defer func() {
+				if panicErr := recover(); panicErr != nil {
+					_ = sw.Send(nil, safe.NewPanicErr(panicErr, debug.Stack()))
+				}
+				sw.Close()
+			}()
+
+			if err := streamAggregated(ctx, aggregator, input, dispatched, sr, sw); err != nil {
+				_ = sw.Send(nil, err)
+			}
+		}()
+		return out, nil
+	}
+
+	lambda, err := compose.AnyLambda[map[string]any, *schema.Message, any](
+		func(ctx context.Context, in map[string]any, _ ...any) (*schema.Message, error) {
+			return invoke(ctx, in)
+		}, nil, nil,
+		func(ctx context.Context, sr *schema.StreamReader[map[string]any], _ ...any) (*schema.StreamReader[*schema.Message], error) {
+			return transform(ctx, sr)
+		}, compose.WithLambdaType("Aggregator"))
+	if err != nil {
+		return err
+	}
+
+	if err = g.AddLambdaNode(aggregatorNodeKey, lambda, compose.WithNodeName("aggregator")); err != nil {
+		return err
+	}
+
+	return g.AddEdge(aggregatorNodeKey, compose.END)
+}
+
+// orderAnswers returns the input messages, and the answers in the order the specialists are called,
+// with Message.Extra tagged with the names of the specialists.
+func orderAnswers(ctx context.Context, in map[string]any) (input []*schema.Message, answers []*schema.Message, err error) {
+	err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+		input = state.msgs
+		answers, err = tagAnswers(state.dispatched, in)
+		return err
+	})
+	return input, answers, err
+}
+
+func tagAnswers(dispatched []string, in map[string]any) ([]*schema.Message, error) {
+	answers := make([]*schema.Message, 0, len(dispatched))
+	for _, name := range dispatched {
+		answer, ok := in[name].(*schema.Message)
+		if !ok || answer == nil {
+			return nil, fmt.Errorf("specialist %s has no answer", name)
+		}
+		answers = append(answers, tagSpecialist(answer, name))
+	}
+	return answers, nil
+}
+
+// tagSpecialist returns a copy of msg, with Message.Extra tagged with the name of the specialist.
+func tagSpecialist(msg *schema.Message, name string) *schema.Message {
+	tagged := *msg
+	tagged.Extra = make(map[string]any, len(msg.Extra)+1)
+	for k, v := range msg.Extra {
+		tagged.Extra[k] = v
+	}
+	tagged.Extra[ExtraKeySpecialist] = name
+	return &tagged
+}
+
+// streamAggregated sends the chunks of the specialists tagged with their names as they arrive,
+// then the combined answer after the answers of all the specialists are received.
+func streamAggregated(ctx context.Context, aggregator *Aggregator, input []*schema.Message, dispatched []string,
+	sr *schema.StreamReader[map[string]any], sw *schema.StreamWriter[*schema.Message]) error {
+	in, err := concatAnswers(sr, func(name string, chunk *schema.Message) bool {
+		return sw.Send(tagSpecialist(chunk, name), nil)
+	})
+	if errors.Is(err, errOutputClosed) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	answers, err := tagAnswers(dispatched, in)
+	if err != nil {
+		return err
+	}
+
+	if aggregator == nil || aggregator.ChatModel == nil {
+		output, err := aggregate(ctx, aggregator, input, answers)
+		if err != nil {
+			return err
+		}
+		sw.Send(output, nil)
+		return nil
+	}
+
+	output, err := aggregator.ChatModel.Stream(ctx, aggregatorInput(aggregator, input, answers))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	for {
+		chunk, err := output.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if sw.Send(chunk, nil) {
+			return nil
+		}
+	}
+}
+
+func aggregate(ctx context.Context, aggregator *Aggregator, input []*schema.Message, answers []*schema.Message) (*schema.Message, error) {
+	switch {
+	case aggregator == nil:
+		parts := make([]string, 0, len(answers))
+		for _, answer := range answers {
+			parts = append(parts, GetSpecialistName(answer)+":\n"+answer.Content)
+		}
+		return schema.AssistantMessage(strings.Join(parts, "\n\n"), nil), nil
+	case aggregator.ChatModel != nil:
+		return aggregator.ChatModel.Generate(ctx, aggregatorInput(aggregator, input, answers))
+	default:
+		return aggregator.Combine(ctx, input, answers)
+	}
+}
+
+func aggregatorInput(aggregator *Aggregator, input []*schema.Message, answers []*schema.Message) []*schema.Message {
+	prompt := aggregator.SystemPrompt
+	if len(prompt) == 0 {
+		prompt = defaultAggregatorPrompt
+	}
+
+	var sb strings.Builder
+	sb.WriteString("The answers of the specialists:")
+	for _, answer := range answers {
+		fmt.Fprintf(&sb, "\n\n[%s]\n%s", GetSpecialistName(answer), answer.Content)
+	}
+
+	msgs := make([]*schema.Message, 0, len(input)+2)
+	msgs = append(msgs, schema.SystemMessage(prompt))
+	msgs = append(msgs, input...)
+	return append(msgs, schema.UserMessage(sb.String()))
+}
+
+// concatAnswers receives the chunks of the specialists, and concatenates them into the answer of each specialist.
+// Each chunk is passed to onChunk as it arrives, which returns true to stop receiving, e.g. when the output is closed.
+func concatAnswers(sr *schema.StreamReader[map[string]any], onChunk func(name string, chunk *schema.Message) bool) (map[string]any, error) {
+	defer sr.Close()
+
+	chunks := make(map[string][]*schema.Message)
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for name, v := range chunk {
+			if msg, ok := v.(*schema.Message); ok && msg != nil {
+				chunks[name] = append(chunks[name], msg)
+				if onChunk(name, msg) {
+					return nil, errOutputClosed
+				}
+			}
+		}
+	}
+
+	answers := make(map[string]any, len(chunks))
+	for name, msgs := range chunks {
+		answer, err := schema.ConcatMessages(msgs)
+		if err != nil {
+			return nil, err
+		}
+		answers[name] = answer
+	}
+
+	return answers, nil
+}
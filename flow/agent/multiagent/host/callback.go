@@ -34,11 +34,18 @@ type MultiAgentCallback interface {
 
 // HandOffInfo is the info which will be passed to MultiAgentCallback.OnHandOff, representing a hand off event.
 type HandOffInfo struct {
-	ToAgentName string
-	Argument    string
+	// FromAgentName is the name of the specialist handing off to its peer, empty if it's the host handing off.
+	FromAgentName string
+	ToAgentName   string
+	Argument      string
+	// Chain is the names of the agents handed off to so far in the run, in order, ending with ToAgentName.
+	Chain []string
 }
 
-// ConvertCallbackHandlers converts []host.MultiAgentCallback to callbacks.Handler.
+// ConvertCallbackHandlers converts []host.MultiAgentCallback to callbacks.Handler,
+// which reads the hand offs from the output of the chat model, to be designated to MultiAgent.HostNodeKey.
+// It's only notified of the hand offs of the host, without FromAgentName or Chain,
+// use ConvertHandOffCallbackHandlers to be notified of all the hand offs.
 func ConvertCallbackHandlers(handlers ...MultiAgentCallback) callbacks.Handler {
 	onChatModelEnd := func(ctx context.Context, info *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
 		if output == nil || info == nil {
@@ -113,6 +120,30 @@ func ConvertCallbackHandlers(handlers ...MultiAgentCallback) callbacks.Handler {
 	}).Handler()
 }
 
+// ConvertHandOffCallbackHandlers converts []host.MultiAgentCallback to callbacks.Handler,
+// which is notified of all the hand offs, including the ones between specialists, with the full hand off chain.
+// It reads the hand offs from the node of MultiAgent.HandOffNodeKey, so it's notified once for each hand off,
+// whether designated to the node or not.
+// It's what MultiAgent.Generate and MultiAgent.Stream use for WithAgentCallbacks.
+func ConvertHandOffCallbackHandlers(handlers ...MultiAgentCallback) callbacks.Handler {
+	onHandOffEnd := func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+		handOffInfos, ok := output.([]*HandOffInfo)
+		if !ok || info == nil {
+			return ctx
+		}
+
+		for _, handOffInfo := range handOffInfos {
+			for _, cb := range handlers {
+				ctx = cb.OnHandOff(ctx, handOffInfo)
+			}
+		}
+
+		return ctx
+	}
+
+	return template.NewHandlerHelper().Lambda(callbacks.NewHandlerBuilder().OnEndFn(onHandOffEnd).Build()).Handler()
+}
+
 // convertCallbacks reads graph call options, extract host.MultiAgentCallback and convert it to callbacks.Handler.
 func convertCallbacks(opts ...agent.AgentOption) callbacks.Handler {
 	agentOptions := agent.GetImplSpecificOptions(&options{}, opts...)
@@ -121,5 +152,5 @@ func convertCallbacks(opts ...agent.AgentOption) callbacks.Handler {
 	}
 
 	handlers := agentOptions.agentCallbacks
-	return ConvertCallbackHandlers(handlers...)
+	return ConvertHandOffCallbackHandlers(handlers...)
 }
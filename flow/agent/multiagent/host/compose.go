@@ -19,26 +19,41 @@ package host
 import (
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/flow/agent/internal/forward"
 	"github.com/cloudwego/eino/schema"
 )
 
 const (
-	defaultHostNodeKey = "host" // the key of the host node in the graph
+	defaultHostNodeKey = "host"       // the key of the host node in the graph
+	handOffNodeKey     = "handoff"    // the key of the node handing off to the specialists
+	returnNodeKey      = "return"     // the key of the node returning the answer of the specialist to the host
+	aggregatorNodeKey  = "aggregator" // the key of the node combining the answers of the specialists handed off to in parallel
 	defaultHostPrompt  = "decide which tool is best for the task and call only the best tool."
 )
 
 type state struct {
-	msgs []*schema.Message
+	msgs []*schema.Message // the input messages
+
+	hostMsgs []*schema.Message // the hand offs of the host and their results, following msgs in the input of the host
+	results  []*schema.Message // the answers of the specialists, following msgs in the input of the specialists
+	hostCall *schema.Message   // the hand off of the host whose result is pending
+	current  string            // the name of the specialist running, empty for the host
+	chain    []string          // the names of the specialists handed off to
+
+	dispatched []string // the names of the specialists handed off to in parallel, in the order they are called
 }
 
 // NewMultiAgent creates a new host multi-agent system.
 //
-// IMPORTANT!! For models that don't output tool calls in the first streaming chunk (e.g. Claude)
-// the default StreamToolCallChecker may not work properly since it only checks the first chunk for tool calls.
-// In such cases, you need to implement a custom StreamToolCallChecker that can properly detect tool calls.
+// IMPORTANT!! The default StreamToolCallChecker reads ahead the output of the host until a chunk with tool calls,
+// and when the multi-agent is called by Stream, the text before the tool calls (e.g. by Claude) is streamed to the caller too.
 func NewMultiAgent(ctx context.Context, config *MultiAgentConfig) (*MultiAgent, error) {
 	if err := config.validate(); err != nil {
 		return nil, err
@@ -48,6 +63,7 @@ func NewMultiAgent(ctx context.Context, config *MultiAgentConfig) (*MultiAgent,
 		hostPrompt      = config.Host.SystemPrompt
 		name            = config.Name
 		toolCallChecker = config.StreamToolCallChecker
+		maxDepth        = config.MaxHandOffDepth
 	)
 
 	if len(hostPrompt) == 0 {
@@ -59,18 +75,23 @@ func NewMultiAgent(ctx context.Context, config *MultiAgentConfig) (*MultiAgent,
 	}
 
 	if toolCallChecker == nil {
-		toolCallChecker = firstChunkStreamToolCallChecker
+		toolCallChecker = agent.ReadAheadStreamToolCallChecker
+	}
+
+	if maxDepth <= 0 {
+		maxDepth = 1
 	}
 
 	g := compose.NewGraph[[]*schema.Message, *schema.Message](
 		compose.WithGenLocalState(func(context.Context) *state { return &state{} }))
 
 	agentTools := make([]*schema.ToolInfo, 0, len(config.Specialists))
+	agentToolMap := make(map[string]*schema.ToolInfo, len(config.Specialists))
 	agentMap := make(map[string]bool, len(config.Specialists)+1)
 	for i := range config.Specialists {
 		specialist := config.Specialists[i]
 
-		agentTools = append(agentTools, &schema.ToolInfo{
+		agentTool := &schema.ToolInfo{
 			Name: specialist.Name,
 			Desc: specialist.IntendedUse,
 			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
@@ -79,33 +100,52 @@ func NewMultiAgent(ctx context.Context, config *MultiAgentConfig) (*MultiAgent,
 					Desc: "the reason to call this tool",
 				},
 			}),
-		})
+		}
+		agentTools = append(agentTools, agentTool)
+		agentToolMap[specialist.Name] = agentTool
+		agentMap[specialist.Name] = true
+	}
 
-		if err := addSpecialistAgent(specialist, g); err != nil {
+	if config.ParallelHandOff {
+		if err := addAggregator(config.Aggregator, g); err != nil {
 			return nil, err
 		}
+	}
 
-		agentMap[specialist.Name] = true
+	for _, specialist := range config.Specialists {
+		if err := addSpecialistAgent(specialist, agentToolMap, maxDepth, config.ParallelHandOff, g); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := addHostAgent(config.Host.ChatModel, hostPrompt, agentTools, g); err != nil {
 		return nil, err
 	}
 
-	const convertorName = "msg2MsgList"
-	if err := g.AddLambdaNode(convertorName, compose.ToList[*schema.Message](), compose.WithNodeName("converter")); err != nil {
+	if err := addHandOff(agentMap, config.ParallelHandOff, config.Guardrail, g); err != nil {
 		return nil, err
 	}
 
-	if err := addDirectAnswerBranch(convertorName, g, toolCallChecker); err != nil {
+	if err := addDirectAnswerBranch(handOffNodeKey, g, toolCallChecker); err != nil {
 		return nil, err
 	}
 
-	if err := addSpecialistsBranch(convertorName, agentMap, g); err != nil {
-		return nil, err
+	compileOpts := []compose.GraphCompileOption{compose.WithNodeTriggerMode(compose.AnyPredecessor), compose.WithGraphName(name)}
+	if maxDepth > 1 {
+		if err := addReturnToHost(g); err != nil {
+			return nil, err
+		}
+
+		for _, specialist := range config.Specialists {
+			if err := addSpecialistBranch(specialist, maxDepth, toolCallChecker, g); err != nil {
+				return nil, err
+			}
+		}
+
+		// host, handoff, specialist and return for each hand off, and the final answer of the host
+		compileOpts = append(compileOpts, compose.WithMaxRunSteps(4*maxDepth+2))
 	}
 
-	compileOpts := []compose.GraphCompileOption{compose.WithNodeTriggerMode(compose.AnyPredecessor), compose.WithGraphName(name)}
 	r, err := g.Compile(ctx, compileOpts...)
 	if err != nil {
 		return nil, err
@@ -115,38 +155,111 @@ func NewMultiAgent(ctx context.Context, config *MultiAgentConfig) (*MultiAgent,
 		runnable:         r,
 		graph:            g,
 		graphAddNodeOpts: []compose.GraphAddNodeOpt{compose.WithGraphCompileOptions(compileOpts...)},
+		memory:           config.Memory,
+		guardrail:        config.Guardrail,
 	}, nil
 }
 
-func addSpecialistAgent(specialist *Specialist, g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+func addSpecialistAgent(specialist *Specialist, agentToolMap map[string]*schema.ToolInfo, maxDepth int, parallel bool,
+	g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+
+	opts := []compose.GraphAddNodeOpt{compose.WithNodeName(specialist.Name)}
+	if parallel {
+		// the answers of the specialists run in parallel are merged into a map keyed by their names
+		opts = append(opts, compose.WithOutputKey(specialist.Name))
+	}
+
 	if specialist.Invokable != nil || specialist.Streamable != nil {
 		lambda, err := compose.AnyLambda(specialist.Invokable, specialist.Streamable, nil, nil, compose.WithLambdaType("Specialist"))
 		if err != nil {
 			return err
 		}
-		preHandler := func(_ context.Context, input []*schema.Message, state *state) ([]*schema.Message, error) {
-			return state.msgs, nil // replace the tool call message with input msgs stored in state
-		}
-		if err := g.AddLambdaNode(specialist.Name, lambda, compose.WithStatePreHandler(preHandler), compose.WithNodeName(specialist.Name)); err != nil {
+		if err := g.AddLambdaNode(specialist.Name, lambda, opts...); err != nil {
 			return err
 		}
 	} else if specialist.ChatModel != nil {
+		if maxDepth > 1 && len(specialist.HandOffTo) > 0 {
+			peerTools := make([]*schema.ToolInfo, 0, len(specialist.HandOffTo))
+			for _, peer := range specialist.HandOffTo {
+				peerTools = append(peerTools, agentToolMap[peer])
+			}
+			if err := specialist.ChatModel.BindTools(peerTools); err != nil {
+				return err
+			}
+		}
+
 		preHandler := func(_ context.Context, input []*schema.Message, state *state) ([]*schema.Message, error) {
 			if len(specialist.SystemPrompt) > 0 {
 				return append([]*schema.Message{{
 					Role:    schema.System,
 					Content: specialist.SystemPrompt,
-				}}, state.msgs...), nil
+				}}, input...), nil
 			}
 
-			return state.msgs, nil // replace the tool call message with input msgs stored in state
+			return input, nil
 		}
-		if err := g.AddChatModelNode(specialist.Name, specialist.ChatModel, compose.WithStatePreHandler(preHandler), compose.WithNodeName(specialist.Name)); err != nil {
+		if err := g.AddChatModelNode(specialist.Name, specialist.ChatModel, append(opts, compose.WithStatePreHandler(preHandler))...); err != nil {
 			return err
 		}
 	}
 
-	return g.AddEdge(specialist.Name, compose.END)
+	if parallel {
+		return g.AddEdge(specialist.Name, aggregatorNodeKey)
+	}
+
+	if maxDepth <= 1 {
+		return g.AddEdge(specialist.Name, compose.END)
+	}
+
+	return nil
+}
+
+func addSpecialistBranch(specialist *Specialist, maxDepth int,
+	toolCallChecker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error),
+	g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+
+	peers := make(map[string]bool, len(specialist.HandOffTo))
+	for _, peer := range specialist.HandOffTo {
+		peers[peer] = true
+	}
+
+	// after the specialist answers, it either hands off to a peer, returns to the host, or ends the run once the depth is reached
+	branch := compose.NewStreamGraphBranch(func(ctx context.Context, sr *schema.StreamReader[*schema.Message]) (endNode string, err error) {
+		var handOff *schema.Message
+		if len(peers) > 0 {
+			copies := sr.Copy(2)
+			isToolCall, err := toolCallChecker(ctx, copies[0])
+			if err != nil {
+				copies[1].Close()
+				return "", err
+			}
+			if !isToolCall {
+				copies[1].Close()
+			} else if handOff, err = concatStream(copies[1]); err != nil {
+				return "", err
+			}
+		} else {
+			sr.Close()
+		}
+
+		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			switch {
+			case len(state.chain) >= maxDepth:
+				endNode = compose.END
+			case handOff != nil && len(handOff.ToolCalls) > 0 && peers[handOff.ToolCalls[0].Function.Name]:
+				endNode = handOffNodeKey
+			default:
+				endNode = returnNodeKey
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return endNode, nil
+	}, map[string]bool{handOffNodeKey: true, returnNodeKey: true, compose.END: true})
+
+	return g.AddBranch(specialist.Name, branch)
 }
 
 func addHostAgent(model model.ChatModel, prompt string, agentTools []*schema.ToolInfo, g *compose.Graph[[]*schema.Message, *schema.Message]) error {
@@ -155,14 +268,19 @@ func addHostAgent(model model.ChatModel, prompt string, agentTools []*schema.Too
 	}
 
 	preHandler := func(_ context.Context, input []*schema.Message, state *state) ([]*schema.Message, error) {
-		state.msgs = input
-		if len(prompt) == 0 {
-			return input, nil
+		if len(state.chain) == 0 {
+			state.msgs = input
+		}
+
+		msgs := make([]*schema.Message, 0, len(state.msgs)+len(state.hostMsgs)+1)
+		if len(prompt) > 0 {
+			msgs = append(msgs, &schema.Message{
+				Role:    schema.System,
+				Content: prompt,
+			})
 		}
-		return append([]*schema.Message{{
-			Role:    schema.System,
-			Content: prompt,
-		}}, input...), nil
+		msgs = append(msgs, state.msgs...)
+		return append(msgs, state.hostMsgs...), nil
 	}
 	if err := g.AddChatModelNode(defaultHostNodeKey, model, compose.WithStatePreHandler(preHandler), compose.WithNodeName(defaultHostNodeKey)); err != nil {
 		return err
@@ -171,35 +289,172 @@ func addHostAgent(model model.ChatModel, prompt string, agentTools []*schema.Too
 	return g.AddEdge(compose.START, defaultHostNodeKey)
 }
 
-func addDirectAnswerBranch(convertorName string, g *compose.Graph[[]*schema.Message, *schema.Message],
+// addHandOff adds the node which hands off to the specialist called by the host, or by a specialist handing off to its peer,
+// or to all the specialists called by the host in parallel.
+// The node reports the hand offs by its callbacks, with []*HandOffInfo as the output.
+func addHandOff(agentMap map[string]bool, parallel bool, guard *guardrail.Guardrail, g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+	handOff := func(ctx context.Context, msg *schema.Message) (output []*schema.Message, err error) {
+		ctx = callbacks.OnStart(ctx, msg)
+		defer func() {
+			if err != nil {
+				callbacks.OnError(ctx, err)
+			}
+		}()
+
+		if guard != nil {
+			if msg, err = guard.Check(ctx, guardrail.StageToolCall, msg); err != nil {
+				return nil, err
+			}
+		}
+
+		var infos []*HandOffInfo
+		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			agentName := "host agent"
+			if state.current != "" {
+				agentName = fmt.Sprintf("specialist %s", state.current)
+			}
+			if parallel {
+				if len(msg.ToolCalls) == 0 {
+					return fmt.Errorf("%s output no tool calls, but expected at least 1", agentName)
+				}
+			} else if len(msg.ToolCalls) != 1 {
+				return fmt.Errorf("%s output %d tool calls, but expected 1", agentName, len(msg.ToolCalls))
+			}
+
+			dispatched := make(map[string]bool, len(msg.ToolCalls))
+			for _, toolCall := range msg.ToolCalls {
+				if !agentMap[toolCall.Function.Name] {
+					return fmt.Errorf("%s handed off to unknown agent %s", agentName, toolCall.Function.Name)
+				}
+				if dispatched[toolCall.Function.Name] {
+					return fmt.Errorf("%s handed off to agent %s more than once", agentName, toolCall.Function.Name)
+				}
+				dispatched[toolCall.Function.Name] = true
+			}
+
+			if state.current == "" {
+				state.hostCall = msg
+			} else if len(msg.Content) > 0 {
+				state.results = append(state.results, &schema.Message{
+					Role:    schema.Assistant,
+					Content: msg.Content,
+					Name:    state.current,
+				})
+			}
+
+			from := state.current
+			for _, toolCall := range msg.ToolCalls {
+				state.current = toolCall.Function.Name
+				state.chain = append(state.chain, toolCall.Function.Name)
+				if parallel {
+					state.dispatched = append(state.dispatched, toolCall.Function.Name)
+				}
+
+				infos = append(infos, &HandOffInfo{
+					FromAgentName: from,
+					ToAgentName:   toolCall.Function.Name,
+					Argument:      toolCall.Function.Arguments,
+					Chain:         append([]string(nil), state.chain...),
+				})
+			}
+
+			output = make([]*schema.Message, 0, len(state.msgs)+len(state.results))
+			output = append(output, state.msgs...)
+			output = append(output, state.results...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		callbacks.OnEnd(ctx, infos)
+		return output, nil
+	}
+
+	if err := g.AddLambdaNode(handOffNodeKey, compose.InvokableLambda(handOff, compose.WithLambdaCallbackEnable(true)),
+		compose.WithNodeName("hand off")); err != nil {
+		return err
+	}
+
+	branch := compose.NewGraphMultiBranch(func(ctx context.Context, _ []*schema.Message) (endNodes map[string]bool, err error) {
+		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			if parallel {
+				endNodes = make(map[string]bool, len(state.dispatched))
+				for _, name := range state.dispatched {
+					endNodes[name] = true
+				}
+			} else {
+				endNodes = map[string]bool{state.current: true}
+			}
+			return nil
+		})
+		return endNodes, err
+	}, agentMap)
+
+	return g.AddBranch(handOffNodeKey, branch)
+}
+
+// addReturnToHost adds the node which returns the answer of the specialist to the host, as the result of its hand off.
+func addReturnToHost(g *compose.Graph[[]*schema.Message, *schema.Message]) error {
+	returnToHost := func(ctx context.Context, answer *schema.Message) (output []*schema.Message, err error) {
+		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			state.results = append(state.results, &schema.Message{
+				Role:    schema.Assistant,
+				Content: answer.Content,
+				Name:    state.current,
+			})
+
+			if state.hostCall != nil {
+				state.hostMsgs = append(state.hostMsgs, state.hostCall,
+					schema.ToolMessage(answer.Content, state.hostCall.ToolCalls[0].ID))
+			}
+
+			state.hostCall = nil
+			state.current = ""
+			output = state.msgs
+			return nil
+		})
+		return output, err
+	}
+
+	if err := g.AddLambdaNode(returnNodeKey, compose.InvokableLambda(returnToHost), compose.WithNodeName("return to host")); err != nil {
+		return err
+	}
+
+	return g.AddEdge(returnNodeKey, defaultHostNodeKey)
+}
+
+func addDirectAnswerBranch(handOffNodeKey string, g *compose.Graph[[]*schema.Message, *schema.Message],
 	toolCallChecker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error)) error {
 	// handles the case where the host agent returns a direct answer, instead of handling off to any specialist
 	branch := compose.NewStreamGraphBranch(func(ctx context.Context, sr *schema.StreamReader[*schema.Message]) (endNode string, err error) {
-		isToolCall, err := toolCallChecker(ctx, sr)
+		isToolCall, err := forward.Check(ctx, g, toolCallChecker, sr)
 		if err != nil {
 			return "", err
 		}
 		if isToolCall {
-			return convertorName, nil
+			return handOffNodeKey, nil
 		}
 		return compose.END, nil
-	}, map[string]bool{convertorName: true, compose.END: true})
+	}, map[string]bool{handOffNodeKey: true, compose.END: true})
 
 	return g.AddBranch(defaultHostNodeKey, branch)
 }
 
-func addSpecialistsBranch(convertorName string, agentMap map[string]bool, g *compose.Graph[[]*schema.Message, *schema.Message]) error {
-	branch := compose.NewGraphBranch(func(ctx context.Context, input []*schema.Message) (string, error) {
-		if len(input) != 1 {
-			return "", fmt.Errorf("host agent output %d messages, but expected 1", len(input))
-		}
+func concatStream(sr *schema.StreamReader[*schema.Message]) (*schema.Message, error) {
+	defer sr.Close()
 
-		if len(input[0].ToolCalls) != 1 {
-			return "", fmt.Errorf("host agent output %d tool calls, but expected 1", len(input[0].ToolCalls))
+	var msgs []*schema.Message
+	for {
+		msg, err := sr.Recv()
+		if err == io.EOF {
+			break
 		}
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
 
-		return input[0].ToolCalls[0].Function.Name, nil
-	}, agentMap)
-
-	return g.AddBranch(convertorName, branch)
+	return schema.ConcatMessages(msgs)
 }
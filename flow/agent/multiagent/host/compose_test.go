@@ -18,8 +18,12 @@ package host
 
 import (
 	"context"
+	"errors"
 	"io"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -27,6 +31,8 @@ import (
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/flow/agent/memory"
 	"github.com/cloudwego/eino/internal/generic"
 	"github.com/cloudwego/eino/internal/mock/components/model"
 	"github.com/cloudwego/eino/schema"
@@ -175,6 +181,7 @@ func TestHostMultiAgent(t *testing.T) {
 			{
 				ToAgentName: specialist1.Name,
 				Argument:    `{"reason": "specialist 1 is the best"}`,
+				Chain:       []string{specialist1.Name},
 			},
 		}, mockCallback.infos)
 
@@ -191,6 +198,7 @@ func TestHostMultiAgent(t *testing.T) {
 			{
 				ToAgentName: specialist2.Name,
 				Argument:    `{"reason": "specialist 2 is even better"}`,
+				Chain:       []string{specialist2.Name},
 			},
 		}, mockCallback.infos)
 	})
@@ -321,6 +329,7 @@ func TestHostMultiAgent(t *testing.T) {
 			{
 				ToAgentName: specialist1.Name,
 				Argument:    `{"reason": "specialist 1 is the best"}`,
+				Chain:       []string{specialist1.Name},
 			},
 		}, mockCallback.infos)
 
@@ -361,6 +370,7 @@ func TestHostMultiAgent(t *testing.T) {
 			{
 				ToAgentName: specialist2.Name,
 				Argument:    `{"reason": "specialist 2 is even better"}`,
+				Chain:       []string{specialist2.Name},
 			},
 		}, mockCallback.infos)
 	})
@@ -429,3 +439,512 @@ func (m *mockAgentCallback) OnHandOff(ctx context.Context, info *HandOffInfo) co
 	m.infos = append(m.infos, info)
 	return ctx
 }
+
+func TestHostMultiAgentWithMemory(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockHostLLM := model.NewMockChatModel(ctrl)
+	mockHostLLM.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	var inputs [][]*schema.Message
+	mockHostLLM.EXPECT().Generate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, _ ...any) (*schema.Message, error) {
+			inputs = append(inputs, input)
+			return schema.AssistantMessage("direct answer", nil), nil
+		}).Times(2)
+
+	mem, err := memory.NewMemory(&memory.Config{Store: memory.NewInMemoryStore()})
+	assert.NoError(t, err)
+
+	hostMA, err := NewMultiAgent(ctx, &MultiAgentConfig{
+		Host: Host{ChatModel: mockHostLLM},
+		Specialists: []*Specialist{
+			{
+				Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+					return schema.AssistantMessage("specialist 1 answer", nil), nil
+				},
+				AgentMeta: AgentMeta{Name: "specialist 1", IntendedUse: "do stuff"},
+			},
+			{
+				Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+					return schema.AssistantMessage("specialist 2 answer", nil), nil
+				},
+				AgentMeta: AgentMeta{Name: "specialist 2", IntendedUse: "do other stuff"},
+			},
+		},
+		Memory: mem,
+	})
+	assert.NoError(t, err)
+
+	_, err = hostMA.Generate(ctx, []*schema.Message{schema.UserMessage("hi")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+	_, err = hostMA.Generate(ctx, []*schema.Message{schema.UserMessage("hi again")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+
+	if assert.Len(t, inputs, 2) {
+		// the system prompt of host, and the history before the new user message
+		assert.Len(t, inputs[1], 4)
+		assert.Equal(t, "direct answer", inputs[1][2].Content)
+	}
+}
+
+func TestHostMultiAgentMultiHop(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	handOffMsg := func(id, content, name string) *schema.Message {
+		return &schema.Message{
+			Role:    schema.Assistant,
+			Content: content,
+			ToolCalls: []schema.ToolCall{{
+				Index:    generic.PtrOf(0),
+				ID:       id,
+				Function: schema.FunctionCall{Name: name, Arguments: `{"reason": "` + name + ` is the best"}`},
+			}},
+		}
+	}
+
+	scripted := func(m *model.MockChatModel, outputs ...*schema.Message) *[][]*schema.Message {
+		var inputs [][]*schema.Message
+		next := func(input []*schema.Message) *schema.Message {
+			inputs = append(inputs, input)
+			return outputs[len(inputs)-1]
+		}
+		m.EXPECT().Generate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input []*schema.Message, _ ...any) (*schema.Message, error) {
+				return next(input), nil
+			}).AnyTimes()
+		m.EXPECT().Stream(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input []*schema.Message, _ ...any) (*schema.StreamReader[*schema.Message], error) {
+				return schema.StreamReaderFromArray([]*schema.Message{next(input)}), nil
+			}).AnyTimes()
+		return &inputs
+	}
+
+	var peerInput []*schema.Message
+	newMultiAgent := func(hostLLM, specialistLLM *model.MockChatModel) *MultiAgent {
+		hostLLM.EXPECT().BindTools(gomock.Len(3)).Return(nil).Times(1)
+		specialistLLM.EXPECT().BindTools(gomock.Len(1)).Return(nil).Times(1)
+
+		ma, err := NewMultiAgent(ctx, &MultiAgentConfig{
+			Host: Host{ChatModel: hostLLM},
+			Specialists: []*Specialist{
+				{
+					ChatModel: specialistLLM,
+					AgentMeta: AgentMeta{Name: "researcher", IntendedUse: "research"},
+					HandOffTo: []string{"writer"},
+				},
+				{
+					Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+						peerInput = input
+						return schema.AssistantMessage("draft", nil), nil
+					},
+					AgentMeta: AgentMeta{Name: "writer", IntendedUse: "write"},
+				},
+				{
+					Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+						return schema.AssistantMessage("reviewed draft", nil), nil
+					},
+					AgentMeta: AgentMeta{Name: "reviewer", IntendedUse: "review"},
+				},
+			},
+			MaxHandOffDepth: 3,
+		})
+		assert.NoError(t, err)
+		return ma
+	}
+
+	input := []*schema.Message{schema.UserMessage("write an article")}
+
+	t.Run("return to host and hand off to peer", func(t *testing.T) {
+		hostLLM, specialistLLM := model.NewMockChatModel(ctrl), model.NewMockChatModel(ctrl)
+		hostInputs := scripted(hostLLM, handOffMsg("1", "", "researcher"), handOffMsg("2", "", "reviewer"))
+		scripted(specialistLLM, handOffMsg("3", "facts", "writer"))
+		ma := newMultiAgent(hostLLM, specialistLLM)
+
+		cb := &mockAgentCallback{}
+		out, err := ma.Generate(ctx, input, WithAgentCallbacks(cb))
+		assert.NoError(t, err)
+		assert.Equal(t, "reviewed draft", out.Content)
+
+		// the writer is given the input, and the answer of the researcher
+		assert.Equal(t, []*schema.Message{input[0], {Role: schema.Assistant, Content: "facts", Name: "researcher"}}, peerInput)
+
+		// the host is given the answer of the chain as the result of its hand off
+		if assert.Len(t, *hostInputs, 2) {
+			second := (*hostInputs)[1]
+			assert.Len(t, second, 4)
+			assert.Equal(t, "researcher", second[2].ToolCalls[0].Function.Name)
+			assert.Equal(t, schema.ToolMessage("draft", "1"), second[3])
+		}
+
+		assert.Equal(t, []*HandOffInfo{
+			{ToAgentName: "researcher", Argument: `{"reason": "researcher is the best"}`, Chain: []string{"researcher"}},
+			{FromAgentName: "researcher", ToAgentName: "writer", Argument: `{"reason": "writer is the best"}`, Chain: []string{"researcher", "writer"}},
+			{ToAgentName: "reviewer", Argument: `{"reason": "reviewer is the best"}`, Chain: []string{"researcher", "writer", "reviewer"}},
+		}, cb.infos)
+	})
+
+	t.Run("callbacks attached globally and per node", func(t *testing.T) {
+		hostLLM, specialistLLM := model.NewMockChatModel(ctrl), model.NewMockChatModel(ctrl)
+		scripted(hostLLM, handOffMsg("1", "", "researcher"), handOffMsg("2", "", "reviewer"))
+		scripted(specialistLLM, handOffMsg("3", "facts", "writer"))
+		ma := newMultiAgent(hostLLM, specialistLLM)
+
+		global, perNode := &mockAgentCallback{}, &mockAgentCallback{}
+		_, err := ma.Generate(ctx, input,
+			agent.WithComposeOptions(compose.WithCallbacks(ConvertHandOffCallbackHandlers(global))),
+			WithAgentCallbacks(perNode))
+		assert.NoError(t, err)
+
+		// each attachment is notified once for each hand off
+		assert.Len(t, global.infos, 3)
+		assert.Equal(t, global.infos, perNode.infos)
+	})
+
+	t.Run("host callbacks", func(t *testing.T) {
+		hostLLM, specialistLLM := model.NewMockChatModel(ctrl), model.NewMockChatModel(ctrl)
+		scripted(hostLLM, handOffMsg("1", "", "researcher"), handOffMsg("2", "", "reviewer"))
+		scripted(specialistLLM, handOffMsg("3", "facts", "writer"))
+		ma := newMultiAgent(hostLLM, specialistLLM)
+
+		cb := &mockAgentCallback{}
+		_, err := ma.Generate(ctx, input,
+			agent.WithComposeOptions(compose.WithCallbacks(ConvertCallbackHandlers(cb)).DesignateNode(ma.HostNodeKey())))
+		assert.NoError(t, err)
+
+		// only the hand offs of the host
+		assert.Equal(t, []*HandOffInfo{
+			{ToAgentName: "researcher", Argument: `{"reason": "researcher is the best"}`},
+			{ToAgentName: "reviewer", Argument: `{"reason": "reviewer is the best"}`},
+		}, cb.infos)
+	})
+
+	t.Run("host answers after specialist", func(t *testing.T) {
+		hostLLM, specialistLLM := model.NewMockChatModel(ctrl), model.NewMockChatModel(ctrl)
+		scripted(hostLLM, handOffMsg("1", "", "researcher"), schema.AssistantMessage("final answer", nil))
+		scripted(specialistLLM, schema.AssistantMessage("facts", nil))
+		ma := newMultiAgent(hostLLM, specialistLLM)
+
+		sr, err := ma.Stream(ctx, input)
+		assert.NoError(t, err)
+		out, err := concatStream(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "final answer", out.Content)
+	})
+}
+
+func TestHostMultiAgentStreamTextBeforeToolCall(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	hostLLM, specialistLLM := model.NewMockChatModel(ctrl), model.NewMockChatModel(ctrl)
+	hostLLM.EXPECT().BindTools(gomock.Len(2)).Return(nil).Times(1)
+	specialistLLM.EXPECT().BindTools(gomock.Len(1)).Return(nil).Times(1)
+
+	// text chunks before the tool call chunks, e.g. Claude
+	textThenToolCall := func(text []string, id, name string) []*schema.Message {
+		chunks := make([]*schema.Message, 0, len(text)+2)
+		for _, s := range text {
+			chunks = append(chunks, schema.AssistantMessage(s, nil))
+		}
+		return append(chunks,
+			schema.AssistantMessage("", []schema.ToolCall{{Index: generic.PtrOf(0), ID: id, Function: schema.FunctionCall{Name: name, Arguments: `{"reason": `}}}),
+			schema.AssistantMessage("", []schema.ToolCall{{Index: generic.PtrOf(0), Function: schema.FunctionCall{Arguments: `"the best"}`}}}),
+		)
+	}
+
+	hostLLM.EXPECT().Stream(gomock.Any(), gomock.Any()).
+		Return(schema.StreamReaderFromArray(textThenToolCall([]string{"", "let me ask ", "the researcher"}, "1", "researcher")), nil).Times(1)
+	specialistLLM.EXPECT().Stream(gomock.Any(), gomock.Any()).
+		Return(schema.StreamReaderFromArray(textThenToolCall([]string{"found ", "the facts"}, "2", "writer")), nil).Times(1)
+
+	var writerInput []*schema.Message
+	ma, err := NewMultiAgent(ctx, &MultiAgentConfig{
+		Host: Host{ChatModel: hostLLM},
+		Specialists: []*Specialist{
+			{
+				ChatModel: specialistLLM,
+				AgentMeta: AgentMeta{Name: "researcher", IntendedUse: "research"},
+				HandOffTo: []string{"writer"},
+			},
+			{
+				Streamable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+					writerInput = input
+					return schema.StreamReaderFromArray([]*schema.Message{
+						schema.AssistantMessage("the ", nil),
+						schema.AssistantMessage("article", nil),
+					}), nil
+				},
+				AgentMeta: AgentMeta{Name: "writer", IntendedUse: "write"},
+			},
+		},
+		MaxHandOffDepth: 2,
+	})
+	assert.NoError(t, err)
+
+	cb := &mockAgentCallback{}
+	sr, err := ma.Stream(ctx, []*schema.Message{schema.UserMessage("write an article")}, WithAgentCallbacks(cb))
+	assert.NoError(t, err)
+	out, err := concatStream(sr)
+	assert.NoError(t, err)
+	// the text of the host before the hand off is streamed while the checker reads ahead, unlike the one of the specialist
+	assert.Equal(t, "let me ask the researcherthe article", out.Content)
+
+	if assert.Len(t, writerInput, 2) {
+		assert.Equal(t, "found the facts", writerInput[1].Content)
+	}
+
+	assert.Equal(t, []*HandOffInfo{
+		{ToAgentName: "researcher", Argument: `{"reason": "the best"}`, Chain: []string{"researcher"}},
+		{FromAgentName: "researcher", ToAgentName: "writer", Argument: `{"reason": "the best"}`, Chain: []string{"researcher", "writer"}},
+	}, cb.infos)
+}
+
+func TestHostMultiAgentParallel(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	handOffMsg := &schema.Message{
+		Role: schema.Assistant,
+		ToolCalls: []schema.ToolCall{
+			{Index: generic.PtrOf(0), ID: "1", Function: schema.FunctionCall{Name: "weather", Arguments: `{}`}},
+			{Index: generic.PtrOf(1), ID: "2", Function: schema.FunctionCall{Name: "traffic", Arguments: `{}`}},
+		},
+	}
+
+	// the specialists wait for each other, so they must run concurrently
+	var barrier sync.WaitGroup
+	newSpecialist := func(name string) *Specialist {
+		return &Specialist{
+			AgentMeta: AgentMeta{Name: name, IntendedUse: "tell the " + name},
+			Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+				barrier.Done()
+				barrier.Wait()
+				return schema.AssistantMessage(name+" is good", nil), nil
+			},
+			Streamable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+				return schema.StreamReaderFromArray([]*schema.Message{
+					schema.AssistantMessage(name+" is ", nil),
+					schema.AssistantMessage("good", nil),
+				}), nil
+			},
+		}
+	}
+
+	newMultiAgent := func(hostOutput *schema.Message, aggregator *Aggregator) *MultiAgent {
+		hostLLM := model.NewMockChatModel(ctrl)
+		hostLLM.EXPECT().BindTools(gomock.Any()).Return(nil).Times(1)
+		hostLLM.EXPECT().Generate(gomock.Any(), gomock.Any()).Return(hostOutput, nil).AnyTimes()
+		hostLLM.EXPECT().Stream(gomock.Any(), gomock.Any()).
+			Return(schema.StreamReaderFromArray([]*schema.Message{hostOutput}), nil).AnyTimes()
+
+		ma, err := NewMultiAgent(ctx, &MultiAgentConfig{
+			Host:            Host{ChatModel: hostLLM},
+			Specialists:     []*Specialist{newSpecialist("weather"), newSpecialist("traffic")},
+			ParallelHandOff: true,
+			Aggregator:      aggregator,
+		})
+		assert.NoError(t, err)
+		return ma
+	}
+
+	input := []*schema.Message{schema.UserMessage("can I go out")}
+
+	_, err := NewMultiAgent(ctx, &MultiAgentConfig{
+		Host:            Host{ChatModel: model.NewMockChatModel(ctrl)},
+		Specialists:     []*Specialist{newSpecialist("weather"), newSpecialist("traffic")},
+		ParallelHandOff: true,
+		MaxHandOffDepth: 2,
+	})
+	assert.Error(t, err)
+
+	t.Run("generate", func(t *testing.T) {
+		ma := newMultiAgent(handOffMsg, nil)
+
+		barrier.Add(2)
+		cb := &mockAgentCallback{}
+		out, err := ma.Generate(ctx, input, WithAgentCallbacks(cb))
+		assert.NoError(t, err)
+		assert.Equal(t, "weather:\nweather is good\n\ntraffic:\ntraffic is good", out.Content)
+		assert.Equal(t, []*HandOffInfo{
+			{ToAgentName: "weather", Argument: `{}`, Chain: []string{"weather"}},
+			{ToAgentName: "traffic", Argument: `{}`, Chain: []string{"weather", "traffic"}},
+		}, cb.infos)
+	})
+
+	// splitAnswers splits the chunks of the specialists by their names, from the chunks of the combined answer after them
+	splitAnswers := func(t *testing.T, sr *schema.StreamReader[*schema.Message]) (map[string]string, string) {
+		defer sr.Close()
+		specialists := make(map[string]string)
+		var answer string
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return specialists, answer
+			}
+			assert.NoError(t, err)
+			if name := GetSpecialistName(chunk); len(name) > 0 {
+				assert.Empty(t, answer, "chunk of %s after the combined answer", name)
+				specialists[name] += chunk.Content
+				continue
+			}
+			answer += chunk.Content
+		}
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		ma := newMultiAgent(handOffMsg, nil)
+
+		// the chunks of the specialists as they arrive, then the same answer as Generate
+		sr, err := ma.Stream(ctx, input)
+		assert.NoError(t, err)
+		specialists, answer := splitAnswers(t, sr)
+		assert.Equal(t, map[string]string{"weather": "weather is good", "traffic": "traffic is good"}, specialists)
+		assert.Equal(t, "weather:\nweather is good\n\ntraffic:\ntraffic is good", answer)
+	})
+
+	t.Run("stream with memory", func(t *testing.T) {
+		ma := newMultiAgent(handOffMsg, nil)
+		mem, err := memory.NewMemory(&memory.Config{Store: memory.NewInMemoryStore()})
+		assert.NoError(t, err)
+		ma.memory = mem
+
+		sr, err := ma.Stream(ctx, input, memory.WithSessionID("s"))
+		assert.NoError(t, err)
+		specialists, _ := splitAnswers(t, sr)
+		assert.Len(t, specialists, 2)
+
+		// only the combined answer is saved
+		assert.Eventually(t, func() bool {
+			history, err := mem.Store().Load(ctx, "s")
+			return err == nil && len(history) == 2 &&
+				history[1].Content == "weather:\nweather is good\n\ntraffic:\ntraffic is good"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("combine", func(t *testing.T) {
+		ma := newMultiAgent(handOffMsg, &Aggregator{
+			Combine: func(ctx context.Context, in []*schema.Message, answers []*schema.Message) (*schema.Message, error) {
+				assert.Equal(t, input, in)
+				parts := make([]string, 0, len(answers))
+				for _, answer := range answers {
+					parts = append(parts, GetSpecialistName(answer)+"="+answer.Content)
+				}
+				return schema.AssistantMessage(strings.Join(parts, ","), nil), nil
+			},
+		})
+
+		barrier.Add(2)
+		out, err := ma.Generate(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, "weather=weather is good,traffic=traffic is good", out.Content)
+
+		sr, err := ma.Stream(ctx, input)
+		assert.NoError(t, err)
+		_, answer := splitAnswers(t, sr)
+		assert.Equal(t, "weather=weather is good,traffic=traffic is good", answer)
+	})
+
+	t.Run("chat model aggregator", func(t *testing.T) {
+		aggregatorLLM := model.NewMockChatModel(ctrl)
+		aggregatorLLM.EXPECT().Stream(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in []*schema.Message, _ ...any) (*schema.StreamReader[*schema.Message], error) {
+				assert.Len(t, in, 3)
+				assert.Equal(t, defaultAggregatorPrompt, in[0].Content)
+				assert.Equal(t, "The answers of the specialists:\n\n[weather]\nweather is good\n\n[traffic]\ntraffic is good", in[2].Content)
+				return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("you can go out", nil)}), nil
+			}).Times(1)
+
+		ma := newMultiAgent(handOffMsg, &Aggregator{ChatModel: aggregatorLLM})
+		sr, err := ma.Stream(ctx, input)
+		assert.NoError(t, err)
+		specialists, answer := splitAnswers(t, sr)
+		assert.Len(t, specialists, 2)
+		assert.Equal(t, "you can go out", answer)
+	})
+
+	t.Run("single specialist", func(t *testing.T) {
+		single := &schema.Message{Role: schema.Assistant, ToolCalls: handOffMsg.ToolCalls[:1]}
+		ma := newMultiAgent(single, &Aggregator{ChatModel: model.NewMockChatModel(ctrl)})
+
+		barrier.Add(1)
+		out, err := ma.Generate(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, schema.AssistantMessage("weather is good", nil), out)
+	})
+}
+
+func TestHostMultiAgentWithGuardrail(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	// blocks "bomb", and redacts "secret"
+	checker := func(_ context.Context, stage guardrail.Stage, msg *schema.Message) (*guardrail.Decision, error) {
+		if strings.Contains(msg.Content, "bomb") {
+			return &guardrail.Decision{Action: guardrail.ActionBlock, Reply: "blocked at " + string(stage)}, nil
+		}
+		if len(msg.ToolCalls) == 0 || !strings.Contains(msg.ToolCalls[0].Function.Arguments, "secret") {
+			return nil, nil
+		}
+		redacted := *msg
+		redacted.ToolCalls = []schema.ToolCall{msg.ToolCalls[0]}
+		redacted.ToolCalls[0].Function.Arguments = strings.ReplaceAll(msg.ToolCalls[0].Function.Arguments, "secret", "***")
+		return &guardrail.Decision{Action: guardrail.ActionRedact, Message: &redacted}, nil
+	}
+	g, err := guardrail.New(&guardrail.Config{Checkers: []guardrail.Checker{checker}})
+	assert.NoError(t, err)
+
+	hostLLM := model.NewMockChatModel(ctrl)
+	hostLLM.EXPECT().BindTools(gomock.Any()).Return(nil).Times(1)
+	handOff := &schema.Message{
+		Role: schema.Assistant,
+		ToolCalls: []schema.ToolCall{{
+			Index:    generic.PtrOf(0),
+			ID:       "1",
+			Function: schema.FunctionCall{Name: "writer", Arguments: `{"reason": "the secret plan"}`},
+		}},
+	}
+	hostLLM.EXPECT().Generate(gomock.Any(), gomock.Any()).Return(handOff, nil).AnyTimes()
+	hostLLM.EXPECT().Stream(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ []*schema.Message, _ ...any) (*schema.StreamReader[*schema.Message], error) {
+			return schema.StreamReaderFromArray([]*schema.Message{handOff}), nil
+		}).AnyTimes()
+
+	ma, err := NewMultiAgent(ctx, &MultiAgentConfig{
+		Host: Host{ChatModel: hostLLM},
+		Specialists: []*Specialist{
+			{
+				Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+					return schema.AssistantMessage("here is how to make a bomb", nil), nil
+				},
+				AgentMeta: AgentMeta{Name: "writer", IntendedUse: "write"},
+			},
+			{
+				Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+					return schema.AssistantMessage("reviewed", nil), nil
+				},
+				AgentMeta: AgentMeta{Name: "reviewer", IntendedUse: "review"},
+			},
+		},
+		Guardrail: g,
+	})
+	assert.NoError(t, err)
+
+	out, err := ma.Generate(ctx, []*schema.Message{schema.UserMessage("a bomb")})
+	assert.NoError(t, err)
+	assert.Equal(t, "blocked at input", out.Content)
+
+	cb := &mockAgentCallback{}
+	out, err = ma.Generate(ctx, []*schema.Message{schema.UserMessage("write")}, WithAgentCallbacks(cb))
+	assert.NoError(t, err)
+	assert.Equal(t, "blocked at output", out.Content)
+	if assert.Len(t, cb.infos, 1) {
+		assert.Equal(t, `{"reason": "the *** plan"}`, cb.infos[0].Argument)
+	}
+
+	sr, err := ma.Stream(ctx, []*schema.Message{schema.UserMessage("write")})
+	assert.NoError(t, err)
+	out, err = concatStream(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "blocked at output", out.Content)
+}
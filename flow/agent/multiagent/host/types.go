@@ -21,11 +21,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/flow/agent/internal/forward"
+	"github.com/cloudwego/eino/flow/agent/memory"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -36,28 +38,114 @@ type MultiAgent struct {
 	runnable         compose.Runnable[[]*schema.Message, *schema.Message]
 	graph            *compose.Graph[[]*schema.Message, *schema.Message]
 	graphAddNodeOpts []compose.GraphAddNodeOpt
+	memory           *memory.Memory
+	guardrail        *guardrail.Guardrail
 }
 
-func (ma *MultiAgent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+func (ma *MultiAgent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (output *schema.Message, err error) {
+	if ma.guardrail != nil {
+		ctx = guardrail.InitCallbacks(ctx, opts...)
+		if input, err = ma.guardrail.CheckInput(ctx, input); err != nil {
+			if reply, ok := guardrail.AsBlocked(err); ok {
+				return reply, nil
+			}
+			return nil, err
+		}
+	}
+
 	composeOptions := agent.GetComposeOptions(opts...)
 
 	handler := convertCallbacks(opts...)
 	if handler != nil {
-		composeOptions = append(composeOptions, compose.WithCallbacks(handler).DesignateNode(ma.HostNodeKey()))
+		composeOptions = append(composeOptions, compose.WithCallbacks(handler).DesignateNode(ma.HandOffNodeKey()))
 	}
 
-	return ma.runnable.Invoke(ctx, input, composeOptions...)
+	if sessionID := memory.GetSessionID(opts...); ma.memory != nil && len(sessionID) > 0 {
+		return ma.memory.Generate(ctx, sessionID, input, func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+			return ma.invoke(ctx, input, composeOptions...)
+		})
+	}
+
+	return ma.invoke(ctx, input, composeOptions...)
 }
 
-func (ma *MultiAgent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+func (ma *MultiAgent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (output *schema.StreamReader[*schema.Message], err error) {
+	if ma.guardrail != nil {
+		ctx = guardrail.InitCallbacks(ctx, opts...)
+		if input, err = ma.guardrail.CheckInput(ctx, input); err != nil {
+			if reply, ok := guardrail.AsBlocked(err); ok {
+				return schema.StreamReaderFromArray([]*schema.Message{reply}), nil
+			}
+			return nil, err
+		}
+	}
+
 	composeOptions := agent.GetComposeOptions(opts...)
 
 	handler := convertCallbacks(opts...)
 	if handler != nil {
-		composeOptions = append(composeOptions, compose.WithCallbacks(handler).DesignateNode(ma.HostNodeKey()))
+		composeOptions = append(composeOptions, compose.WithCallbacks(handler).DesignateNode(ma.HandOffNodeKey()))
+	}
+
+	if sessionID := memory.GetSessionID(opts...); ma.memory != nil && len(sessionID) > 0 {
+		// the chunks of the specialists handed off to in parallel are streamed to the caller,
+		// while only the combined answer after them is saved to the memory
+		var output *schema.StreamReader[*schema.Message]
+		saved, err := ma.memory.Stream(ctx, sessionID, input, func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+			sr, err := ma.stream(ctx, input, composeOptions...)
+			if err != nil {
+				return nil, err
+			}
+			copies := sr.Copy(2)
+			output = copies[0]
+			return schema.StreamReaderWithConvert(copies[1], func(chunk *schema.Message) (*schema.Message, error) {
+				if len(GetSpecialistName(chunk)) > 0 {
+					return nil, schema.ErrNoValue
+				}
+				return chunk, nil
+			}), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		saved.Close()
+		return output, nil
+	}
+
+	return ma.stream(ctx, input, composeOptions...)
+}
+
+func (ma *MultiAgent) invoke(ctx context.Context, input []*schema.Message, opts ...compose.Option) (*schema.Message, error) {
+	output, err := ma.runnable.Invoke(ctx, input, opts...)
+	if reply, ok := guardrail.AsBlocked(err); ok {
+		return reply, nil
+	}
+	if err != nil || ma.guardrail == nil {
+		return output, err
 	}
 
-	return ma.runnable.Stream(ctx, input, composeOptions...)
+	if output, err = ma.guardrail.Check(ctx, guardrail.StageOutput, output); err != nil {
+		if reply, ok := guardrail.AsBlocked(err); ok {
+			return reply, nil
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func (ma *MultiAgent) stream(ctx context.Context, input []*schema.Message, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	output, err := forward.Stream(ctx, ma.graph, func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		output, err := ma.runnable.Stream(ctx, input, opts...)
+		if reply, ok := guardrail.AsBlocked(err); ok {
+			return schema.StreamReaderFromArray([]*schema.Message{reply}), nil
+		}
+		return output, err
+	})
+	if err != nil || ma.guardrail == nil {
+		return output, err
+	}
+
+	return ma.guardrail.CheckStream(ctx, guardrail.StageOutput, output), nil
 }
 
 // ExportGraph exports the underlying graph from MultiAgent, along with the []compose.GraphAddNodeOpt to be used when adding this graph to another graph.
@@ -69,6 +157,12 @@ func (ma *MultiAgent) HostNodeKey() string {
 	return defaultHostNodeKey
 }
 
+// HandOffNodeKey returns the key of the node which hands off the task to the specialists,
+// where the handler of ConvertHandOffCallbackHandlers is notified of all the hand offs.
+func (ma *MultiAgent) HandOffNodeKey() string {
+	return handOffNodeKey
+}
+
 // MultiAgentConfig is the config for host multi-agent system.
 type MultiAgentConfig struct {
 	Host        Host
@@ -76,6 +170,27 @@ type MultiAgentConfig struct {
 
 	Name string // the name of the host multi-agent
 
+	// MaxHandOffDepth is the max number of hand offs in a run, counting both the hand offs of the host and the ones between specialists.
+	// When it's greater than 1, the answer of the specialist is returned to the host as the result of its tool call,
+	// then the host can hand off to another specialist, or answer by itself,
+	// and the specialists can hand off to their peers in Specialist.HandOffTo directly.
+	// Once the depth is reached, the answer of the last specialist is the final answer.
+	// Optional. Default is 1, where the answer of the first specialist handed off to is the final answer.
+	MaxHandOffDepth int
+
+	// ParallelHandOff lets the host hand off to several specialists at once, by calling more than one of them in a message.
+	// The specialists called run concurrently, each given the same input messages,
+	// then their answers are combined into one response by Aggregator.
+	// If only one specialist is called, its answer is the response.
+	// In Stream, the chunks of the specialists are streamed as they arrive, tagged with their names by ExtraKeySpecialist,
+	// followed by the combined answer, so tell them apart by GetSpecialistName, which is empty for the combined answer.
+	// With Memory, only the combined answer is saved.
+	// It can't be used together with MaxHandOffDepth greater than 1.
+	ParallelHandOff bool
+	// Aggregator combines the answers of the specialists handed off to in parallel.
+	// Optional. By default, the answers are joined one after another, each headed by the name of the specialist.
+	Aggregator *Aggregator
+
 	// StreamOutputHandler is a function to determine whether the model's streaming output contains tool calls.
 	// Different models have different ways of outputting tool calls in streaming mode:
 	// - Some models (like OpenAI) output tool calls directly
@@ -86,10 +201,21 @@ type MultiAgentConfig struct {
 	// - false if no tool calls and agent should stop
 	// Note: This field only needs to be configured when using streaming mode
 	// Note: The handler MUST close the modelOutput stream before returning
-	// Optional. By default, it checks if the first chunk contains tool calls.
-	// Note: The default implementation does not work well with Claude, which typically outputs tool calls after text content.
-	// Note: If your ChatModel doesn't output tool calls first, you can try adding prompts to constrain the model from generating extra text during the tool call.
+	// Optional. By default, it's agent.ReadAheadStreamToolCallChecker, which reads ahead until a chunk with tool calls,
+	// and works with the models outputting text before tool calls (e.g. Claude) too.
+	// When the multi-agent is called by Stream, the text chunks the host reads ahead are streamed to the caller without waiting.
 	StreamToolCallChecker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error)
+
+	// Memory saves the input and output messages of each session,
+	// and loads them before the input messages when the multi-agent is called with memory.WithSessionID.
+	// Optional. By default, the multi-agent is stateless, and the full history must be passed on each call.
+	Memory *memory.Memory
+
+	// Guardrail checks the input and the output of the multi-agent, and the hand offs before the specialists run.
+	// A blocked message stops the multi-agent, which answers with the canned reply instead.
+	// The input and the output are only checked when the multi-agent is called by Generate or Stream, instead of being exported as a graph.
+	// Optional. By default, nothing is checked.
+	Guardrail *guardrail.Guardrail
 }
 
 func (conf *MultiAgentConfig) validate() error {
@@ -105,6 +231,20 @@ func (conf *MultiAgentConfig) validate() error {
 		return errors.New("host multi agent specialists are empty")
 	}
 
+	if conf.ParallelHandOff && conf.MaxHandOffDepth > 1 {
+		return errors.New("host multi agent parallel hand off can't be used with max hand off depth greater than 1")
+	}
+
+	if conf.Aggregator != nil {
+		if conf.Aggregator.ChatModel == nil && conf.Aggregator.Combine == nil {
+			return errors.New("host multi agent aggregator has no chat model or Combine")
+		}
+		if conf.Aggregator.ChatModel != nil && conf.Aggregator.Combine != nil {
+			return errors.New("host multi agent aggregator has both chat model and Combine")
+		}
+	}
+
+	names := make(map[string]bool, len(conf.Specialists))
 	for _, s := range conf.Specialists {
 		if s.ChatModel == nil && s.Invokable == nil && s.Streamable == nil {
 			return fmt.Errorf("specialist %s has no chat model or Invokable or Streamable", s.Name)
@@ -113,6 +253,16 @@ func (conf *MultiAgentConfig) validate() error {
 		if err := s.AgentMeta.validate(); err != nil {
 			return err
 		}
+
+		names[s.Name] = true
+	}
+
+	for _, s := range conf.Specialists {
+		for _, peer := range s.HandOffTo {
+			if !names[peer] || peer == s.Name {
+				return fmt.Errorf("specialist %s hands off to invalid peer %s", s.Name, peer)
+			}
+		}
 	}
 
 	return nil
@@ -157,28 +307,21 @@ type Specialist struct {
 
 	Invokable  compose.Invoke[[]*schema.Message, *schema.Message, agent.AgentOption]
 	Streamable compose.Stream[[]*schema.Message, *schema.Message, agent.AgentOption]
-}
-
-func firstChunkStreamToolCallChecker(_ context.Context, sr *schema.StreamReader[*schema.Message]) (bool, error) {
-	defer sr.Close()
-
-	for {
-		msg, err := sr.Recv()
-		if err == io.EOF {
-			return false, nil
-		}
-		if err != nil {
-			return false, err
-		}
 
-		if len(msg.ToolCalls) > 0 {
-			return true, nil
-		}
+	// HandOffTo is the names of the peer specialists this specialist can hand off to directly, without returning to the host.
+	// The peers are bound to ChatModel as tools, the same as the ones of the host.
+	// Invokable and Streamable hand off by returning a message whose only tool call is named after the peer.
+	// Optional. It only takes effect when MultiAgentConfig.MaxHandOffDepth is greater than 1.
+	HandOffTo []string
+}
 
-		if len(msg.Content) == 0 { // skip empty chunks at the front
-			continue
-		}
+// Aggregator combines the answers of the specialists handed off to in parallel into one response.
+// It can be a model.ChatModel, which is given the input messages followed by a user message with all the answers,
+// or a custom Combine function. ChatModel and Combine are mutually exclusive, only one should be provided.
+type Aggregator struct {
+	ChatModel    model.ChatModel
+	SystemPrompt string
 
-		return false, nil
-	}
+	// Combine combines the answers in the order the specialists are called, get the name of the specialist by GetSpecialistName.
+	Combine func(ctx context.Context, input []*schema.Message, answers []*schema.Message) (*schema.Message, error)
 }
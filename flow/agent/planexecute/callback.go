@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package planexecute
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/flow/agent"
+	template "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// PlanExecuteCallback is the callback interface for plan-and-execute agent.
+type PlanExecuteCallback interface {
+	// OnPlan is called when the planner makes the plan, or the replanner revises it.
+	OnPlan(ctx context.Context, info *PlanInfo) context.Context
+	// OnStep is called when a step is executed.
+	OnStep(ctx context.Context, result *StepResult) context.Context
+}
+
+// PlanInfo is the info which will be passed to PlanExecuteCallback.OnPlan.
+type PlanInfo struct {
+	Plan *Plan
+	// Replanned is true if the plan is revised by the replanner, rather than made by the planner.
+	Replanned bool
+}
+
+// ConvertCallbackHandlers converts []planexecute.PlanExecuteCallback to callbacks.Handler.
+func ConvertCallbackHandlers(handlers ...PlanExecuteCallback) callbacks.Handler {
+	onEnd := func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+		if info == nil {
+			return ctx
+		}
+
+		switch info.Name {
+		case PlannerNodeName, ReplannerNodeName:
+			p, ok := output.(*Plan)
+			if !ok || p == nil {
+				return ctx
+			}
+			for _, cb := range handlers {
+				ctx = cb.OnPlan(ctx, &PlanInfo{Plan: p, Replanned: info.Name == ReplannerNodeName})
+			}
+		case ExecutorNodeName:
+			result, ok := output.(*StepResult)
+			if !ok || result == nil {
+				return ctx
+			}
+			for _, cb := range handlers {
+				ctx = cb.OnStep(ctx, result)
+			}
+		}
+
+		return ctx
+	}
+
+	return template.NewHandlerHelper().Lambda(callbacks.NewHandlerBuilder().OnEndFn(onEnd).Build()).Handler()
+}
+
+// convertCallbacks reads graph call options, extract planexecute.PlanExecuteCallback and convert it to callbacks.Handler.
+func convertCallbacks(opts ...agent.AgentOption) callbacks.Handler {
+	agentOptions := agent.GetImplSpecificOptions(&options{}, opts...)
+	if len(agentOptions.agentCallbacks) == 0 {
+		return nil
+	}
+
+	return ConvertCallbackHandlers(agentOptions.agentCallbacks...)
+}
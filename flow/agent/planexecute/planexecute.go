@@ -0,0 +1,463 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package planexecute implements a plan-and-execute agent, which breaks the task down into steps with a planner,
+// executes the steps one by one with an executor, and revises the remaining plan with a replanner after each step.
+package planexecute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	GraphName         = "PlanExecuteAgent"
+	PlannerNodeName   = "Planner"
+	ExecutorNodeName  = "Executor"
+	ReplannerNodeName = "Replanner"
+	RespondNodeName   = "Respond"
+)
+
+const (
+	nodeKeyPlanner   = "planner"
+	nodeKeyExecutor  = "executor"
+	nodeKeyReplanner = "replanner"
+	nodeKeyRespond   = "respond"
+
+	defaultMaxSteps = 10
+)
+
+// DefaultPlannerPrompt is the default system prompt of the planner.
+const DefaultPlannerPrompt = `For the given objective, come up with a simple step by step plan.
+This plan should involve individual tasks, that if executed correctly will yield the correct answer. Do not add any superfluous steps.
+The result of the final step should be the final answer. Make sure that each step has all the information needed - do not skip steps.
+If the objective can be answered directly, answer it without any step.
+Respond with a JSON object only, either {"steps": ["step 1", "step 2"]} for the plan, or {"response": "the answer"} for the answer.`
+
+// DefaultReplannerPrompt is the default system prompt of the replanner.
+const DefaultReplannerPrompt = `For the given objective, revise the step by step plan according to the steps completed so far.
+Only add the steps that still NEED to be done, and do not return the completed steps as part of the plan.
+If no more steps are needed and you can answer the objective, answer it.
+Respond with a JSON object only, either {"steps": ["step 1", "step 2"]} for the remaining plan, or {"response": "the answer"} for the answer.`
+
+// ErrExceedMaxSteps is returned when the plan still has steps to execute after MaxSteps steps are executed.
+var ErrExceedMaxSteps = errors.New("plan exceeds max steps")
+
+// Plan is the plan made by the planner or the replanner.
+// Either Steps is the steps to execute, or Response is the final answer.
+type Plan struct {
+	Steps    []string `json:"steps,omitempty"`
+	Response string   `json:"response,omitempty"`
+}
+
+// StepResult is the result of an executed step.
+type StepResult struct {
+	Step   string `json:"step"`
+	Result string `json:"result"`
+}
+
+// State is the local state of the agent's graph, it's saved in the checkpoint when the agent is interrupted,
+// and can be modified by compose.WithStateModifier on resume, e.g. to edit the plan.
+type State struct {
+	// Input is the input messages of the agent.
+	Input []*schema.Message
+	// Plan is the remaining plan, whose first step is the next one to execute.
+	Plan *Plan
+	// PastSteps are the steps executed so far.
+	PastSteps []*StepResult
+}
+
+func init() {
+	_ = compose.RegisterSerializableType[State]("_eino_plan_execute_state")
+	_ = compose.RegisterSerializableType[Plan]("_eino_plan_execute_plan")
+	_ = compose.RegisterSerializableType[StepResult]("_eino_plan_execute_step_result")
+}
+
+// Executor executes a step of the plan, e.g. a *react.Agent.
+type Executor interface {
+	Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error)
+}
+
+// NewRunnableExecutor creates an Executor from a compose.Runnable, such as a compiled graph or chain.
+// The compose options of agent.WithComposeOptions are passed to the Runnable.
+func NewRunnableExecutor(r compose.Runnable[[]*schema.Message, *schema.Message]) Executor {
+	return &runnableExecutor{r: r}
+}
+
+type runnableExecutor struct {
+	r compose.Runnable[[]*schema.Message, *schema.Message]
+}
+
+func (e *runnableExecutor) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	return e.r.Invoke(ctx, input, agent.GetComposeOptions(opts...)...)
+}
+
+// Config is the config for plan-and-execute agent.
+type Config struct {
+	// Planner makes the initial plan from the input messages, in the format of Plan in JSON.
+	// Required.
+	Planner model.ChatModel
+	// PlannerPrompt is the system prompt of the planner.
+	// Optional. Default is DefaultPlannerPrompt.
+	PlannerPrompt string
+
+	// Executor executes each step of the plan, given the input messages followed by a user message
+	// describing the plan, the completed steps and the step to execute.
+	// Required.
+	Executor Executor
+
+	// Replanner revises the remaining plan after each step, or gives the final answer, in the format of Plan in JSON.
+	// Optional. Default is Planner.
+	Replanner model.ChatModel
+	// ReplannerPrompt is the system prompt of the replanner.
+	// Optional. Default is DefaultReplannerPrompt.
+	ReplannerPrompt string
+
+	// MaxSteps is the max number of steps executed, ErrExceedMaxSteps is returned if the plan needs more.
+	// Optional. Default is 10.
+	MaxSteps int
+
+	// InterruptBeforeStep interrupts the agent before executing each step, so that the plan can be reviewed,
+	// and modified with compose.WithStateModifier, before the agent is resumed with the same checkpoint id.
+	// CheckPointStore is required for it.
+	InterruptBeforeStep bool
+	// CheckPointStore saves the checkpoints of the agent, see compose.WithCheckPointStore.
+	// Optional.
+	CheckPointStore compose.CheckPointStore
+}
+
+// Agent is the plan-and-execute agent.
+// e.g.
+//
+//	a, err := planexecute.NewAgent(ctx, &planexecute.Config{Planner: chatModel, Executor: reactAgent})
+//	if err != nil {...}
+//	msg, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("who is the oldest winner of the US open")})
+type Agent struct {
+	runnable         compose.Runnable[[]*schema.Message, *schema.Message]
+	graph            *compose.Graph[[]*schema.Message, *schema.Message]
+	graphAddNodeOpts []compose.GraphAddNodeOpt
+}
+
+// NewAgent creates a plan-and-execute agent.
+func NewAgent(ctx context.Context, config *Config) (*Agent, error) {
+	if config == nil || config.Planner == nil {
+		return nil, errors.New("planner is required")
+	}
+	if config.Executor == nil {
+		return nil, errors.New("executor is required")
+	}
+	if config.InterruptBeforeStep && config.CheckPointStore == nil {
+		return nil, errors.New("check point store is required to interrupt before step")
+	}
+
+	var (
+		planner         = config.Planner
+		replanner       = config.Replanner
+		plannerPrompt   = config.PlannerPrompt
+		replannerPrompt = config.ReplannerPrompt
+		maxSteps        = config.MaxSteps
+		executor        = config.Executor
+	)
+	if replanner == nil {
+		replanner = planner
+	}
+	if plannerPrompt == "" {
+		plannerPrompt = DefaultPlannerPrompt
+	}
+	if replannerPrompt == "" {
+		replannerPrompt = DefaultReplannerPrompt
+	}
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+
+	graph := compose.NewGraph[[]*schema.Message, *schema.Message](compose.WithGenLocalState(func(ctx context.Context) *State {
+		return &State{}
+	}))
+
+	plan := func(ctx context.Context, input []*schema.Message) (*Plan, error) {
+		msgs := make([]*schema.Message, 0, len(input)+1)
+		msgs = append(msgs, schema.SystemMessage(plannerPrompt))
+		msgs = append(msgs, input...)
+
+		p, err := generatePlan(ctx, planner, msgs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan: %w", err)
+		}
+		if len(p.Steps) == 0 && p.Response == "" {
+			return nil, errors.New("planner returned an empty plan")
+		}
+
+		err = compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			state.Input = input
+			state.Plan = p
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	execute := func(ctx context.Context, _ *Plan, opts ...agent.AgentOption) (*StepResult, error) {
+		var input []*schema.Message
+		var step string
+		err := compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			if state.Plan == nil || len(state.Plan.Steps) == 0 {
+				return errors.New("no step to execute")
+			}
+			step = state.Plan.Steps[0]
+			input = executorInput(state)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := executor.Generate(ctx, input, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute step %q: %w", step, err)
+		}
+
+		result := &StepResult{Step: step, Result: output.Content}
+		err = compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			state.PastSteps = append(state.PastSteps, result)
+			state.Plan = &Plan{Steps: state.Plan.Steps[1:]}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	replan := func(ctx context.Context, _ *StepResult) (*Plan, error) {
+		var msgs []*schema.Message
+		var executed int
+		err := compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			msgs = make([]*schema.Message, 0, len(state.Input)+2)
+			msgs = append(msgs, schema.SystemMessage(replannerPrompt))
+			msgs = append(msgs, state.Input...)
+			msgs = append(msgs, schema.UserMessage(replannerInput(state)))
+			executed = len(state.PastSteps)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := generatePlan(ctx, replanner, msgs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replan: %w", err)
+		}
+		if p.Response == "" && len(p.Steps) > 0 && executed >= maxSteps {
+			return nil, ErrExceedMaxSteps
+		}
+
+		err = compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			state.Plan = p
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	respond := func(ctx context.Context, p *Plan) (*schema.Message, error) {
+		if p.Response != "" {
+			return schema.AssistantMessage(p.Response, nil), nil
+		}
+
+		// the replanner may return an empty plan instead of the answer, after the final step is done
+		var content string
+		err := compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			if len(state.PastSteps) > 0 {
+				content = state.PastSteps[len(state.PastSteps)-1].Result
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return schema.AssistantMessage(content, nil), nil
+	}
+
+	if err := graph.AddLambdaNode(nodeKeyPlanner, compose.InvokableLambda(plan), compose.WithNodeName(PlannerNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyExecutor, compose.InvokableLambdaWithOption(execute), compose.WithNodeName(ExecutorNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyReplanner, compose.InvokableLambda(replan), compose.WithNodeName(ReplannerNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyRespond, compose.InvokableLambda(respond), compose.WithNodeName(RespondNodeName)); err != nil {
+		return nil, err
+	}
+
+	if err := graph.AddEdge(compose.START, nodeKeyPlanner); err != nil {
+		return nil, err
+	}
+
+	planBranch := func(_ context.Context, p *Plan) (string, error) {
+		if p.Response != "" || len(p.Steps) == 0 {
+			return nodeKeyRespond, nil
+		}
+		return nodeKeyExecutor, nil
+	}
+	endNodes := map[string]bool{nodeKeyExecutor: true, nodeKeyRespond: true}
+	if err := graph.AddBranch(nodeKeyPlanner, compose.NewGraphBranch(planBranch, endNodes)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddEdge(nodeKeyExecutor, nodeKeyReplanner); err != nil {
+		return nil, err
+	}
+	if err := graph.AddBranch(nodeKeyReplanner, compose.NewGraphBranch(planBranch, endNodes)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddEdge(nodeKeyRespond, compose.END); err != nil {
+		return nil, err
+	}
+
+	compileOpts := []compose.GraphCompileOption{
+		// planner, (executor, replanner) * maxSteps, and respond
+		compose.WithMaxRunSteps(2*maxSteps + 2),
+		compose.WithNodeTriggerMode(compose.AnyPredecessor),
+		compose.WithGraphName(GraphName),
+	}
+	if config.InterruptBeforeStep {
+		compileOpts = append(compileOpts, compose.WithInterruptBeforeNodes([]string{nodeKeyExecutor}))
+	}
+
+	runnableOpts := compileOpts
+	if config.CheckPointStore != nil {
+		runnableOpts = append(runnableOpts[:len(runnableOpts):len(runnableOpts)], compose.WithCheckPointStore(config.CheckPointStore))
+	}
+
+	runnable, err := graph.Compile(ctx, runnableOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{
+		runnable:         runnable,
+		graph:            graph,
+		graphAddNodeOpts: []compose.GraphAddNodeOpt{compose.WithGraphCompileOptions(compileOpts...)},
+	}, nil
+}
+
+func generatePlan(ctx context.Context, cm model.ChatModel, msgs []*schema.Message) (*Plan, error) {
+	output, err := cm.Generate(ctx, msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := schema.NewMessageJSONParser[*Plan](nil)
+	p, err := parser.Parse(ctx, &schema.Message{Role: output.Role, Content: utils.RepairJSON(output.Content)})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return &Plan{}, nil
+	}
+	return p, nil
+}
+
+func writePastSteps(sb *strings.Builder, state *State) {
+	if len(state.PastSteps) > 0 {
+		sb.WriteString("The completed steps and their results:\n")
+		for i, s := range state.PastSteps {
+			fmt.Fprintf(sb, "%d. %s\nResult: %s\n", i+1, s.Step, s.Result)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func executorInput(state *State) []*schema.Message {
+	var sb strings.Builder
+	sb.WriteString("The plan:\n")
+	for i, s := range state.PastSteps {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, s.Step)
+	}
+	for i, s := range state.Plan.Steps {
+		fmt.Fprintf(&sb, "%d. %s\n", len(state.PastSteps)+i+1, s)
+	}
+	sb.WriteString("\n")
+	writePastSteps(&sb, state)
+	fmt.Fprintf(&sb, "You are tasked with executing step %d: %s", len(state.PastSteps)+1, state.Plan.Steps[0])
+
+	msgs := make([]*schema.Message, 0, len(state.Input)+1)
+	msgs = append(msgs, state.Input...)
+	return append(msgs, schema.UserMessage(sb.String()))
+}
+
+func replannerInput(state *State) string {
+	var sb strings.Builder
+	writePastSteps(&sb, state)
+	if state.Plan != nil && len(state.Plan.Steps) > 0 {
+		sb.WriteString("The remaining steps of the plan:\n")
+		for i, s := range state.Plan.Steps {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, s)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Update the plan accordingly, or answer the objective if no more steps are needed.")
+	return sb.String()
+}
+
+// Generate generates a response from the agent.
+func (a *Agent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	return a.runnable.Invoke(ctx, input, a.composeOptions(opts...)...)
+}
+
+// Stream calls the agent and returns a stream response.
+func (a *Agent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+	return a.runnable.Stream(ctx, input, a.composeOptions(opts...)...)
+}
+
+// ExportGraph exports the underlying graph from Agent, along with the []compose.GraphAddNodeOpt to be used when adding this graph to another graph.
+// The checkpoint store of Config is not exported, set it when compiling the outer graph instead.
+func (a *Agent) ExportGraph() (compose.AnyGraph, []compose.GraphAddNodeOpt) {
+	return a.graph, a.graphAddNodeOpts
+}
+
+func (a *Agent) composeOptions(opts ...agent.AgentOption) []compose.Option {
+	composeOpts := agent.GetComposeOptions(opts...)
+
+	o := agent.GetImplSpecificOptions(&options{}, opts...)
+	if len(o.executorOptions) > 0 {
+		lambdaOpts := make([]any, len(o.executorOptions))
+		for i := range o.executorOptions {
+			lambdaOpts[i] = o.executorOptions[i]
+		}
+		composeOpts = append(composeOpts, compose.WithLambdaOption(lambdaOpts...).DesignateNode(nodeKeyExecutor))
+	}
+
+	if handler := convertCallbacks(opts...); handler != nil {
+		composeOpts = append(composeOpts, compose.WithCallbacks(handler))
+	}
+
+	return composeOpts
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package planexecute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/schema"
+)
+
+type scriptedModel struct {
+	outputs []string
+	inputs  [][]*schema.Message
+}
+
+func (s *scriptedModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if len(s.inputs) >= len(s.outputs) {
+		return nil, errors.New("unexpected call")
+	}
+	s.inputs = append(s.inputs, input)
+	return schema.AssistantMessage(s.outputs[len(s.inputs)-1], nil), nil
+}
+
+func (s *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := s.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (s *scriptedModel) BindTools(_ []*schema.ToolInfo) error {
+	return nil
+}
+
+type stepOption struct {
+	suffix string
+}
+
+func withSuffix(suffix string) agent.AgentOption {
+	return agent.WrapImplSpecificOptFn(func(o *stepOption) {
+		o.suffix = suffix
+	})
+}
+
+type echoExecutor struct {
+	inputs [][]*schema.Message
+}
+
+func (e *echoExecutor) Generate(_ context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	e.inputs = append(e.inputs, input)
+	last := input[len(input)-1].Content
+	step := last[strings.LastIndex(last, ": ")+2:]
+	return schema.AssistantMessage("done "+step+agent.GetImplSpecificOptions(&stepOption{}, opts...).suffix, nil), nil
+}
+
+type recordingCallback struct {
+	plans []*PlanInfo
+	steps []*StepResult
+}
+
+func (r *recordingCallback) OnPlan(ctx context.Context, info *PlanInfo) context.Context {
+	r.plans = append(r.plans, info)
+	return ctx
+}
+
+func (r *recordingCallback) OnStep(ctx context.Context, result *StepResult) context.Context {
+	r.steps = append(r.steps, result)
+	return ctx
+}
+
+type inMemoryStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (i *inMemoryStore) Get(_ context.Context, checkPointID string) ([]byte, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	v, ok := i.m[checkPointID]
+	return v, ok, nil
+}
+
+func (i *inMemoryStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.m[checkPointID] = checkPoint
+	return nil
+}
+
+func TestPlanExecuteAgent(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("plan a trip")}
+
+	_, err := NewAgent(ctx, &Config{Executor: &echoExecutor{}})
+	assert.Error(t, err)
+	_, err = NewAgent(ctx, &Config{Planner: &scriptedModel{}, Executor: &echoExecutor{}, InterruptBeforeStep: true})
+	assert.Error(t, err)
+
+	t.Run("generate", func(t *testing.T) {
+		planner := &scriptedModel{outputs: []string{"```json\n{\"steps\": [\"book flight\", \"book hotel\"]}\n```"}}
+		replanner := &scriptedModel{outputs: []string{
+			`{"steps": ["book hotel", "rent car"]}`,
+			`{"steps": ["rent car"]}`,
+			`{"response": "trip planned"}`,
+		}}
+		executor := &echoExecutor{}
+		a, err := NewAgent(ctx, &Config{Planner: planner, Replanner: replanner, Executor: executor})
+		assert.NoError(t, err)
+
+		cb := &recordingCallback{}
+		output, err := a.Generate(ctx, input, WithAgentCallbacks(cb), WithExecutorOptions(withSuffix("!")))
+		assert.NoError(t, err)
+		assert.Equal(t, "trip planned", output.Content)
+
+		assert.Equal(t, DefaultPlannerPrompt, planner.inputs[0][0].Content)
+		assert.Equal(t, input[0], planner.inputs[0][1])
+
+		assert.Len(t, executor.inputs, 3)
+		assert.Equal(t, input[0], executor.inputs[1][0])
+		assert.Equal(t, "The plan:\n1. book flight\n2. book hotel\n3. rent car\n\n"+
+			"The completed steps and their results:\n1. book flight\nResult: done book flight!\n\n"+
+			"You are tasked with executing step 2: book hotel", executor.inputs[1][1].Content)
+
+		assert.Equal(t, DefaultReplannerPrompt, replanner.inputs[2][0].Content)
+		assert.Contains(t, replanner.inputs[2][2].Content, "3. rent car\nResult: done rent car!\n")
+
+		assert.Len(t, cb.plans, 4)
+		assert.False(t, cb.plans[0].Replanned)
+		assert.Equal(t, []string{"book flight", "book hotel"}, cb.plans[0].Plan.Steps)
+		assert.True(t, cb.plans[3].Replanned)
+		assert.Equal(t, "trip planned", cb.plans[3].Plan.Response)
+		assert.Equal(t, []*StepResult{
+			{Step: "book flight", Result: "done book flight!"},
+			{Step: "book hotel", Result: "done book hotel!"},
+			{Step: "rent car", Result: "done rent car!"},
+		}, cb.steps)
+	})
+
+	t.Run("direct response", func(t *testing.T) {
+		executor := &echoExecutor{}
+		a, err := NewAgent(ctx, &Config{
+			Planner:  &scriptedModel{outputs: []string{`{"response": "no plan needed"}`}},
+			Executor: executor,
+		})
+		assert.NoError(t, err)
+
+		sr, err := a.Stream(ctx, input)
+		assert.NoError(t, err)
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			chunks = append(chunks, chunk)
+		}
+		output, err := schema.ConcatMessages(chunks)
+		assert.NoError(t, err)
+		assert.Equal(t, "no plan needed", output.Content)
+		assert.Len(t, executor.inputs, 0)
+	})
+
+	t.Run("max steps", func(t *testing.T) {
+		outputs := make([]string, 3)
+		for i := range outputs {
+			outputs[i] = fmt.Sprintf(`{"steps": ["step %d"]}`, i)
+		}
+		a, err := NewAgent(ctx, &Config{
+			Planner:  &scriptedModel{outputs: outputs},
+			Executor: &echoExecutor{},
+			MaxSteps: 2,
+		})
+		assert.NoError(t, err)
+
+		_, err = a.Generate(ctx, input)
+		assert.ErrorIs(t, err, ErrExceedMaxSteps)
+	})
+
+	t.Run("interrupt before step", func(t *testing.T) {
+		executor := &echoExecutor{}
+		a, err := NewAgent(ctx, &Config{
+			Planner: &scriptedModel{outputs: []string{
+				`{"steps": ["book flight", "book hotel"]}`,
+				`{"response": "trip planned"}`,
+			}},
+			Executor:            executor,
+			InterruptBeforeStep: true,
+			CheckPointStore:     &inMemoryStore{m: map[string][]byte{}},
+		})
+		assert.NoError(t, err)
+
+		_, err = a.Generate(ctx, input, agent.WithComposeOptions(compose.WithCheckPointID("1")))
+		info, ok := compose.ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{nodeKeyExecutor}, info.BeforeNodes)
+		assert.Equal(t, []string{"book flight", "book hotel"}, info.State.(*State).Plan.Steps)
+		assert.Len(t, executor.inputs, 0)
+
+		// the user edits the plan before resuming
+		output, err := a.Generate(ctx, input, agent.WithComposeOptions(
+			compose.WithCheckPointID("1"),
+			compose.WithStateModifier(func(_ context.Context, _ compose.NodePath, state any) error {
+				state.(*State).Plan.Steps = []string{"book train"}
+				return nil
+			}),
+		))
+		assert.NoError(t, err)
+		assert.Equal(t, "trip planned", output.Content)
+		assert.Len(t, executor.inputs, 1)
+		assert.Contains(t, executor.inputs[0][1].Content, "step 1: book train")
+	})
+
+	t.Run("export graph", func(t *testing.T) {
+		a, err := NewAgent(ctx, &Config{
+			Planner:  &scriptedModel{outputs: []string{`{"steps": ["book flight"]}`, `{"response": "trip planned"}`}},
+			Executor: &echoExecutor{},
+		})
+		assert.NoError(t, err)
+
+		g, opts := a.ExportGraph()
+		chain := compose.NewChain[[]*schema.Message, *schema.Message]()
+		chain.AppendGraph(g, opts...)
+		r, err := chain.Compile(ctx)
+		assert.NoError(t, err)
+
+		output, err := r.Invoke(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, "trip planned", output.Content)
+	})
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent/memory"
+	"github.com/cloudwego/eino/schema"
+)
+
+// MaxStepPolicy decides what the agent does when the run is out of MaxStep.
+type MaxStepPolicy string
+
+const (
+	// MaxStepPolicyError returns a *MaxStepError carrying the messages so far.
+	MaxStepPolicyError MaxStepPolicy = "error"
+	// MaxStepPolicyFinalAnswer calls the chat model once more without tools, with the messages so far,
+	// and returns its answer as the final answer.
+	MaxStepPolicyFinalAnswer MaxStepPolicy = "final_answer"
+)
+
+// defaultMaxStep is the node num + 10 of the chat model and the tools, as the default max steps of a pregel graph.
+const defaultMaxStep = 12
+
+const defaultMaxStepFinalAnswerPrompt = "You have reached the maximum number of steps. " +
+	"Do not call any tools, give the final answer with the information you have so far."
+
+// MaxStepError is returned by the agent when the run is out of MaxStep with MaxStepPolicyError.
+// errors.Is(err, compose.ErrExceedMaxSteps) still holds for it.
+type MaxStepError struct {
+	// Messages are the messages of the run so far, starting with the input, including the tool calls and the tool results.
+	Messages []*schema.Message
+
+	err error
+}
+
+func (e *MaxStepError) Error() string {
+	return fmt.Sprintf("react agent stops after %d messages: %v", len(e.Messages), e.err)
+}
+
+func (e *MaxStepError) Unwrap() error {
+	return e.err
+}
+
+const (
+	nodeKeyMaxStep       = "max_step"
+	nodeKeyDropToolCalls = "drop_tool_calls"
+)
+
+// maxStepConfig builds the nodes running the MaxStepPolicy, which the agent goes to instead of the next step out of MaxStep.
+type maxStepConfig struct {
+	maxStep         int
+	policy          MaxStepPolicy
+	model           model.ChatModel
+	messageModifier MessageModifier
+	prompt          string
+	withMemory      bool
+}
+
+// next returns the node to go to after the current step, which is nodeKeyMaxStep if the run is out of MaxStep.
+func (c *maxStepConfig) next(ctx context.Context, node string) (string, error) {
+	var steps int
+	err := compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+		steps = state.Steps
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if steps < c.maxStep {
+		return node, nil
+	}
+	if node == nodeKeyTools {
+		return nodeKeyDropToolCalls, nil
+	}
+	return nodeKeyMaxStep, nil
+}
+
+// addNodes adds nodeKeyMaxStep, which takes the tool results of the last step,
+// and nodeKeyDropToolCalls, which drops the tool calls not to be executed out of MaxStep.
+func (c *maxStepConfig) addNodes(graph *compose.Graph[[]*schema.Message, *schema.Message]) error {
+	if c.policy == MaxStepPolicyFinalAnswer {
+		cm := newWithOptionsModel(c.model, model.WithTools(nil), model.WithToolChoice(schema.ToolChoiceForbidden))
+		if err := graph.AddChatModelNode(nodeKeyMaxStep, cm, compose.WithStatePreHandler(c.finalAnswerPreHandle),
+			compose.WithNodeName(ModelNodeName)); err != nil {
+			return err
+		}
+	} else {
+		if err := graph.AddLambdaNode(nodeKeyMaxStep, compose.InvokableLambda(c.error)); err != nil {
+			return err
+		}
+	}
+
+	if err := graph.AddEdge(nodeKeyMaxStep, compose.END); err != nil {
+		return err
+	}
+
+	dropToolCalls := func(_ context.Context, _ *schema.Message) ([]*schema.Message, error) {
+		return nil, nil
+	}
+	if err := graph.AddLambdaNode(nodeKeyDropToolCalls, compose.InvokableLambda(dropToolCalls)); err != nil {
+		return err
+	}
+	return graph.AddEdge(nodeKeyDropToolCalls, nodeKeyMaxStep)
+}
+
+// finalAnswerPreHandle asks for the final answer with the messages so far.
+func (c *maxStepConfig) finalAnswerPreHandle(ctx context.Context, results []*schema.Message, state *state) ([]*schema.Message, error) {
+	state.Messages = appendToolResults(state.Messages, results)
+	if c.withMemory {
+		memory.SetRunMessages(ctx, state.Messages)
+	}
+
+	input := append(state.Messages[:len(state.Messages):len(state.Messages)], schema.UserMessage(c.prompt))
+	if c.messageModifier != nil {
+		input = c.messageModifier(ctx, input)
+	}
+	return input, nil
+}
+
+func (c *maxStepConfig) error(ctx context.Context, results []*schema.Message) (*schema.Message, error) {
+	var msgs []*schema.Message
+	err := compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+		msgs = appendToolResults(state.Messages, results)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, &MaxStepError{Messages: msgs, err: compose.ErrExceedMaxSteps}
+}
+
+func appendToolResults(msgs []*schema.Message, results []*schema.Message) []*schema.Message {
+	msgs = append([]*schema.Message{}, msgs...)
+	for _, result := range results {
+		if result != nil {
+			msgs = append(msgs, result)
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestMaxStepPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	toolCall := func(i int) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{
+			{ID: fmt.Sprint(i), Function: schema.FunctionCall{Name: "greet", Arguments: fmt.Sprintf(`{"name": "%d"}`, i)}},
+		})
+	}
+
+	// finalTemperature is the temperature of the last call to ask for the final answer
+	var finalTemperature *float32
+
+	// newModel returns a chat model which keeps calling tools, unless it's called without tools
+	newModel := func(t *testing.T, finalInput *[]*schema.Message) *mockModel.MockChatModel {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockChatModel(ctrl)
+		cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+		times := 0
+		answer := func(input []*schema.Message, opts ...model.Option) *schema.Message {
+			o := model.GetCommonOptions(nil, opts...)
+			if o.Tools != nil && len(o.Tools) == 0 {
+				assert.Equal(t, schema.ToolChoiceForbidden, *o.ToolChoice)
+				*finalInput = input
+				finalTemperature = o.Temperature
+				return schema.AssistantMessage("partial answer", nil)
+			}
+			times++
+			return toolCall(times)
+		}
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+				return answer(input, opts...), nil
+			}).AnyTimes()
+		cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+				return schema.StreamReaderFromArray([]*schema.Message{answer(input, opts...)}), nil
+			}).AnyTimes()
+		return cm
+	}
+
+	newAgentWithMaxStep := func(t *testing.T, cm model.ChatModel, policy MaxStepPolicy, maxStep int) *Agent {
+		a, err := NewAgent(ctx, &AgentConfig{
+			Model:         cm,
+			ToolsConfig:   compose.ToolsNodeConfig{Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 100}}},
+			MaxStep:       maxStep,
+			MaxStepPolicy: policy,
+		})
+		assert.NoError(t, err)
+		return a
+	}
+	newAgent := func(t *testing.T, cm model.ChatModel, policy MaxStepPolicy) *Agent {
+		return newAgentWithMaxStep(t, cm, policy, 4)
+	}
+
+	input := []*schema.Message{schema.UserMessage("greet everyone")}
+	history := []*schema.Message{
+		input[0],
+		toolCall(1),
+		schema.ToolMessage(`{"say": "hello 1"}`, "1"),
+		toolCall(2),
+		schema.ToolMessage(`{"say": "hello 2"}`, "2"),
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := newAgent(t, newModel(t, nil), "").Generate(ctx, input)
+		assert.ErrorIs(t, err, compose.ErrExceedMaxSteps)
+		var maxStepErr *MaxStepError
+		if assert.True(t, errors.As(err, &maxStepErr)) {
+			assert.Equal(t, history, maxStepErr.Messages)
+		}
+
+		_, err = newAgent(t, newModel(t, nil), "").Stream(ctx, input)
+		if assert.True(t, errors.As(err, &maxStepErr)) {
+			assert.Equal(t, history, maxStepErr.Messages)
+		}
+	})
+
+	t.Run("final answer", func(t *testing.T) {
+		var finalInput []*schema.Message
+		out, err := newAgent(t, newModel(t, &finalInput), MaxStepPolicyFinalAnswer).Generate(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, "partial answer", out.Content)
+		assert.Equal(t, append(history, schema.UserMessage(defaultMaxStepFinalAnswerPrompt)), finalInput)
+
+		finalInput = nil
+		sr, err := newAgent(t, newModel(t, &finalInput), MaxStepPolicyFinalAnswer).Stream(ctx, input)
+		assert.NoError(t, err)
+		out, err = sr.Recv()
+		assert.NoError(t, err)
+		sr.Close()
+		assert.Equal(t, "partial answer", out.Content)
+		assert.Equal(t, append(history, schema.UserMessage(defaultMaxStepFinalAnswerPrompt)), finalInput)
+	})
+
+	t.Run("out of max step after the chat model", func(t *testing.T) {
+		// the tool calls of the third step are not executed
+		_, err := newAgentWithMaxStep(t, newModel(t, nil), "", 3).Generate(ctx, input)
+		var maxStepErr *MaxStepError
+		if assert.True(t, errors.As(err, &maxStepErr)) {
+			assert.Equal(t, history[:3], maxStepErr.Messages)
+		}
+
+		var finalInput []*schema.Message
+		sr, err := newAgentWithMaxStep(t, newModel(t, &finalInput), MaxStepPolicyFinalAnswer, 3).Stream(ctx, input)
+		assert.NoError(t, err)
+		out, err := sr.Recv()
+		assert.NoError(t, err)
+		sr.Close()
+		assert.Equal(t, "partial answer", out.Content)
+		assert.Equal(t, append(history[:3:3], schema.UserMessage(defaultMaxStepFinalAnswerPrompt)), finalInput)
+	})
+
+	t.Run("final answer in graph", func(t *testing.T) {
+		var modelStarts []string
+		handler := callbacks.NewHandlerBuilder().OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			if info.Component == components.ComponentOfChatModel {
+				modelStarts = append(modelStarts, info.Name)
+			}
+			return ctx
+		}).Build()
+
+		var finalInput []*schema.Message
+		finalTemperature = nil
+		out, err := newAgent(t, newModel(t, &finalInput), MaxStepPolicyFinalAnswer).Generate(ctx, input,
+			agent.WithComposeOptions(compose.WithCallbacks(handler), compose.WithChatModelOption(model.WithTemperature(0.5))))
+		assert.NoError(t, err)
+		assert.Equal(t, "partial answer", out.Content)
+		assert.Equal(t, []string{ModelNodeName, ModelNodeName, ModelNodeName}, modelStarts)
+		if assert.NotNil(t, finalTemperature) {
+			assert.Equal(t, float32(0.5), *finalTemperature)
+		}
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		_, err := NewAgent(ctx, &AgentConfig{Model: newModel(t, nil), MaxStepPolicy: "retry"})
+		assert.Error(t, err)
+	})
+}
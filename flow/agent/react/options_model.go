@@ -0,0 +1,54 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// withOptionsModel calls the chat model with the options appended to the ones of each call,
+// e.g. to call the model without tools.
+type withOptionsModel struct {
+	model.ChatModel
+
+	opts []model.Option
+}
+
+func newWithOptionsModel(cm model.ChatModel, opts ...model.Option) *withOptionsModel {
+	return &withOptionsModel{ChatModel: cm, opts: opts}
+}
+
+func (w *withOptionsModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return w.ChatModel.Generate(ctx, input, append(opts[:len(opts):len(opts)], w.opts...)...)
+}
+
+func (w *withOptionsModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return w.ChatModel.Stream(ctx, input, append(opts[:len(opts):len(opts)], w.opts...)...)
+}
+
+func (w *withOptionsModel) GetType() string {
+	typ, _ := components.GetType(w.ChatModel)
+	return typ
+}
+
+func (w *withOptionsModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(w.ChatModel)
+}
@@ -18,17 +18,25 @@ package react
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/flow/agent/internal/forward"
+	"github.com/cloudwego/eino/flow/agent/memory"
 	"github.com/cloudwego/eino/schema"
 )
 
 type state struct {
-	Messages                 []*schema.Message
-	ReturnDirectlyToolCallID string
+	Messages                  []*schema.Message
+	ReturnDirectlyToolCallIDs []string
+	// Steps counts the runs of the chat model and the tools, to stop the run by MaxStepPolicy out of MaxStep.
+	Steps int
 }
 
 const (
@@ -39,6 +47,13 @@ const (
 // MessageModifier modify the input messages before the model is called.
 type MessageModifier func(ctx context.Context, input []*schema.Message) []*schema.Message
 
+// ReturnDirectlyChecker decides whether the result of the tool call is returned directly as the final answer.
+type ReturnDirectlyChecker func(ctx context.Context, toolCall schema.ToolCall, result *schema.Message) (bool, error)
+
+// ReturnDirectlyCombiner combines the results of the tool calls returned directly into the final answer,
+// when more than one tool call is returned directly. The results are in the order of the tool calls.
+type ReturnDirectlyCombiner func(ctx context.Context, results []*schema.Message) (*schema.Message, error)
+
 // AgentConfig is the config for ReAct agent.
 type AgentConfig struct {
 	// Model is the chat model to be used for handling user messages.
@@ -50,13 +65,21 @@ type AgentConfig struct {
 	// modify the input messages before the model is called, it's useful when you want to add some system prompt or other messages.
 	MessageModifier MessageModifier
 
-	// MaxStep.
+	// MaxStep is the max number of the runs of the chat model and the tools, after which the agent stops by MaxStepPolicy.
 	// default 12 of steps in pregel (node num + 10).
 	MaxStep int `json:"max_step"`
 
 	// Tools that will make agent return directly when the tool is called.
-	// When multiple tools are called and more than one tool is in the return directly list, only the first one will be returned.
+	// When multiple tools are called and more than one tool is returned directly, their results are combined by ReturnDirectlyCombiner.
 	ToolReturnDirectly map[string]struct{}
+	// ReturnDirectlyChecker decides whether to return directly by the tool call and its result, in addition to ToolReturnDirectly.
+	// It's called for each tool call not in ToolReturnDirectly, after the tools are done,
+	// so the results of the tools are not streamed until all of them end.
+	// Optional. By default, only the tools in ToolReturnDirectly return directly.
+	ReturnDirectlyChecker ReturnDirectlyChecker
+	// ReturnDirectlyCombiner combines the results when more than one tool call is returned directly.
+	// Optional. By default, the contents of the results are joined by new lines into an assistant message.
+	ReturnDirectlyCombiner ReturnDirectlyCombiner
 
 	// StreamOutputHandler is a function to determine whether the model's streaming output contains tool calls.
 	// Different models have different ways of outputting tool calls in streaming mode:
@@ -68,10 +91,38 @@ type AgentConfig struct {
 	// - false if no tool calls and agent should stop
 	// Note: This field only needs to be configured when using streaming mode
 	// Note: The handler MUST close the modelOutput stream before returning
-	// Optional. By default, it checks if the first chunk contains tool calls.
-	// Note: The default implementation does not work well with Claude, which typically outputs tool calls after text content.
-	// Note: If your ChatModel doesn't output tool calls first, you can try adding prompts to constrain the model from generating extra text during the tool call.
+	// Optional. By default, it's agent.ReadAheadStreamToolCallChecker, which reads ahead until a chunk with tool calls,
+	// and works with the models outputting text before tool calls (e.g. Claude) too.
+	// When the agent is called by Stream, the text chunks it reads ahead are streamed to the caller without waiting.
 	StreamToolCallChecker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error)
+
+	// ToolSelector selects the tools for each call to the chat model, which is useful when there are too many tools to bind them all.
+	// When set, the tools are not bound by Model.BindTools, but passed to the chat model by model.WithTools on each call,
+	// so the chat model must support the WithTools option. The tools node still runs any tool in ToolsConfig.
+	// Optional. By default, all the tools are bound to the chat model.
+	ToolSelector ToolSelector
+	// PinnedTools are the names of the tools which are always given to the chat model, in addition to the tools selected by ToolSelector.
+	// Optional. It only takes effect when ToolSelector is set.
+	PinnedTools []string
+
+	// Memory saves the messages of each session, including the tool calls and tool results,
+	// and loads them before the input messages when the agent is called with memory.WithSessionID.
+	// Optional. By default, the agent is stateless, and the full history must be passed on each call.
+	Memory *memory.Memory
+
+	// MaxStepPolicy decides what the agent does when the run is out of MaxStep.
+	// It runs in the graph of the agent, so it takes effect when the agent is exported as a graph too.
+	// Optional. Default is MaxStepPolicyError, which returns a *MaxStepError carrying the messages so far.
+	MaxStepPolicy MaxStepPolicy
+	// MaxStepFinalAnswerPrompt is the user message appended to the messages so far, to ask for the final answer with MaxStepPolicyFinalAnswer.
+	// Optional. Default is defaultMaxStepFinalAnswerPrompt.
+	MaxStepFinalAnswerPrompt string
+
+	// Guardrail checks the input and the output of the agent, and the tool calls before the tools are executed.
+	// A blocked message stops the agent, which answers with the canned reply instead.
+	// The input and the output are only checked when the agent is called by Generate or Stream, instead of being exported as a graph.
+	// Optional. By default, nothing is checked.
+	Guardrail *guardrail.Guardrail
 }
 
 // Deprecated: This approach of adding persona involves unnecessary slice copying overhead.
@@ -100,30 +151,6 @@ func NewPersonaModifier(persona string) MessageModifier {
 	}
 }
 
-func firstChunkStreamToolCallChecker(_ context.Context, sr *schema.StreamReader[*schema.Message]) (bool, error) {
-	defer sr.Close()
-
-	for {
-		msg, err := sr.Recv()
-		if err == io.EOF {
-			return false, nil
-		}
-		if err != nil {
-			return false, err
-		}
-
-		if len(msg.ToolCalls) > 0 {
-			return true, nil
-		}
-
-		if len(msg.Content) == 0 { // skip empty chunks at the front
-			continue
-		}
-
-		return false, nil
-	}
-}
-
 const (
 	GraphName     = "ReActAgent"
 	ModelNodeName = "ChatModel"
@@ -146,13 +173,15 @@ type Agent struct {
 	runnable         compose.Runnable[[]*schema.Message, *schema.Message]
 	graph            *compose.Graph[[]*schema.Message, *schema.Message]
 	graphAddNodeOpts []compose.GraphAddNodeOpt
+	memory           *memory.Memory
+	guardrail        *guardrail.Guardrail
+	toolCallChecker  func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error)
 }
 
 // NewAgent creates a ReAct agent that feeds tool response into next round of Chat Model generation.
 //
-// IMPORTANT!! For models that don't output tool calls in the first streaming chunk (e.g. Claude)
-// the default StreamToolCallChecker may not work properly since it only checks the first chunk for tool calls.
-// In such cases, you need to implement a custom StreamToolCallChecker that can properly detect tool calls.
+// IMPORTANT!! The default StreamToolCallChecker reads ahead the output of the chat model until a chunk with tool calls,
+// and when the agent is called by Stream, the text before the tool calls (e.g. by Claude) is streamed to the caller too.
 func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 	var (
 		chatModel       = config.Model
@@ -163,14 +192,18 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 	)
 
 	if toolCallChecker == nil {
-		toolCallChecker = firstChunkStreamToolCallChecker
+		toolCallChecker = agent.ReadAheadStreamToolCallChecker
 	}
 
 	if toolInfos, err = genToolInfos(ctx, config.ToolsConfig); err != nil {
 		return nil, err
 	}
 
-	if err = chatModel.BindTools(toolInfos); err != nil {
+	if config.ToolSelector != nil {
+		if chatModel, err = newToolSelectModel(chatModel, toolInfos, config.PinnedTools, config.ToolSelector); err != nil {
+			return nil, err
+		}
+	} else if err = chatModel.BindTools(toolInfos); err != nil {
 		return nil, err
 	}
 
@@ -178,12 +211,39 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 		return nil, err
 	}
 
+	maxStepPolicy, maxStepPrompt := config.MaxStepPolicy, config.MaxStepFinalAnswerPrompt
+	switch maxStepPolicy {
+	case "":
+		maxStepPolicy = MaxStepPolicyError
+	case MaxStepPolicyError, MaxStepPolicyFinalAnswer:
+	default:
+		return nil, fmt.Errorf("unknown max step policy: %s", maxStepPolicy)
+	}
+	if len(maxStepPrompt) == 0 {
+		maxStepPrompt = defaultMaxStepFinalAnswerPrompt
+	}
+	maxStep := &maxStepConfig{
+		maxStep:         config.MaxStep,
+		policy:          maxStepPolicy,
+		model:           config.Model,
+		messageModifier: messageModifier,
+		prompt:          maxStepPrompt,
+		withMemory:      config.Memory != nil,
+	}
+	if maxStep.maxStep <= 0 {
+		maxStep.maxStep = defaultMaxStep
+	}
+
 	graph := compose.NewGraph[[]*schema.Message, *schema.Message](compose.WithGenLocalState(func(ctx context.Context) *state {
 		return &state{Messages: make([]*schema.Message, 0, config.MaxStep+1)}
 	}))
 
 	modelPreHandle := func(ctx context.Context, input []*schema.Message, state *state) ([]*schema.Message, error) {
 		state.Messages = append(state.Messages, input...)
+		state.Steps++
+		if config.Memory != nil {
+			memory.SetRunMessages(ctx, state.Messages)
+		}
 
 		if messageModifier == nil {
 			return state.Messages, nil
@@ -203,36 +263,59 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 	}
 
 	toolsNodePreHandle := func(ctx context.Context, input *schema.Message, state *state) (*schema.Message, error) {
+		if config.Guardrail != nil {
+			var err error
+			if input, err = config.Guardrail.Check(ctx, guardrail.StageToolCall, input); err != nil {
+				return nil, err
+			}
+		}
 		state.Messages = append(state.Messages, input)
-		state.ReturnDirectlyToolCallID = getReturnDirectlyToolCallID(input, config.ToolReturnDirectly)
+		state.ReturnDirectlyToolCallIDs = getReturnDirectlyToolCallIDs(input, config.ToolReturnDirectly)
+		state.Steps++
+		if config.Memory != nil {
+			memory.SetRunMessages(ctx, state.Messages)
+		}
 		return input, nil
 	}
 	if err = graph.AddToolsNode(nodeKeyTools, toolsNode, compose.WithStatePreHandler(toolsNodePreHandle), compose.WithNodeName(ToolsNodeName)); err != nil {
 		return nil, err
 	}
 
-	modelPostBranchCondition := func(_ context.Context, sr *schema.StreamReader[*schema.Message]) (endNode string, err error) {
-		if isToolCall, err := toolCallChecker(ctx, sr); err != nil {
+	if err = maxStep.addNodes(graph); err != nil {
+		return nil, err
+	}
+
+	modelPostBranchCondition := func(ctx context.Context, sr *schema.StreamReader[*schema.Message]) (endNode string, err error) {
+		if isToolCall, err := forward.Check(ctx, graph, toolCallChecker, sr); err != nil {
 			return "", err
 		} else if isToolCall {
-			return nodeKeyTools, nil
+			return maxStep.next(ctx, nodeKeyTools)
 		}
 		return compose.END, nil
 	}
 
-	if err = graph.AddBranch(nodeKeyModel, compose.NewStreamGraphBranch(modelPostBranchCondition, map[string]bool{nodeKeyTools: true, compose.END: true})); err != nil {
+	if err = graph.AddBranch(nodeKeyModel, compose.NewStreamGraphBranch(modelPostBranchCondition,
+		map[string]bool{nodeKeyTools: true, nodeKeyDropToolCalls: true, compose.END: true})); err != nil {
 		return nil, err
 	}
 
-	if len(config.ToolReturnDirectly) > 0 {
-		if err = buildReturnDirectly(graph); err != nil {
+	if len(config.ToolReturnDirectly) > 0 || config.ReturnDirectlyChecker != nil {
+		combiner := config.ReturnDirectlyCombiner
+		if combiner == nil {
+			combiner = defaultReturnDirectlyCombiner
+		}
+		if err = buildReturnDirectly(graph, config.ReturnDirectlyChecker, combiner, maxStep, config.Memory != nil); err != nil {
 			return nil, err
 		}
-	} else if err = graph.AddEdge(nodeKeyTools, nodeKeyModel); err != nil {
+	} else if err = graph.AddBranch(nodeKeyTools, compose.NewStreamGraphBranch(func(ctx context.Context, msgsStream *schema.StreamReader[[]*schema.Message]) (endNode string, err error) {
+		msgsStream.Close()
+		return maxStep.next(ctx, nodeKeyModel)
+	}, map[string]bool{nodeKeyModel: true, nodeKeyMaxStep: true})); err != nil {
 		return nil, err
 	}
 
-	compileOpts := []compose.GraphCompileOption{compose.WithMaxRunSteps(config.MaxStep), compose.WithNodeTriggerMode(compose.AnyPredecessor), compose.WithGraphName(GraphName)}
+	// the steps out of MaxStep are stopped by MaxStepPolicy, leave room for the nodes running it
+	compileOpts := []compose.GraphCompileOption{compose.WithMaxRunSteps(maxStep.maxStep + 2), compose.WithNodeTriggerMode(compose.AnyPredecessor), compose.WithGraphName(GraphName)}
 	runnable, err := graph.Compile(ctx, compileOpts...)
 	if err != nil {
 		return nil, err
@@ -242,30 +325,69 @@ func NewAgent(ctx context.Context, config *AgentConfig) (_ *Agent, err error) {
 		runnable:         runnable,
 		graph:            graph,
 		graphAddNodeOpts: []compose.GraphAddNodeOpt{compose.WithGraphCompileOptions(compileOpts...)},
+		memory:           config.Memory,
+		guardrail:        config.Guardrail,
+		toolCallChecker:  toolCallChecker,
 	}, nil
 }
 
-func buildReturnDirectly(graph *compose.Graph[[]*schema.Message, *schema.Message]) (err error) {
+func buildReturnDirectly(graph *compose.Graph[[]*schema.Message, *schema.Message],
+	checker ReturnDirectlyChecker, combiner ReturnDirectlyCombiner, maxStep *maxStepConfig, withMemory bool) (err error) {
 	directReturn := func(ctx context.Context, msgs *schema.StreamReader[[]*schema.Message]) (*schema.StreamReader[*schema.Message], error) {
-		return schema.StreamReaderWithConvert(msgs, func(msgs []*schema.Message) (*schema.Message, error) {
-			var msg *schema.Message
-			err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+		var ids []string
+		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+			ids = state.ReturnDirectlyToolCallIDs
+			return nil
+		})
+		if err != nil {
+			msgs.Close()
+			return nil, err
+		}
+
+		if len(ids) == 1 {
+			// stream the result of the only tool call returned directly
+			return schema.StreamReaderWithConvert(msgs, func(msgs []*schema.Message) (*schema.Message, error) {
 				for i := range msgs {
-					if msgs[i] != nil && msgs[i].ToolCallID == state.ReturnDirectlyToolCallID {
-						msg = msgs[i]
-						return nil
+					if msgs[i] != nil && msgs[i].ToolCallID == ids[0] {
+						return msgs[i], nil
 					}
 				}
+				return nil, schema.ErrNoValue
+			}), nil
+		}
+
+		results, err := concatToolResults(msgs)
+		if err != nil {
+			return nil, err
+		}
+
+		if withMemory {
+			// the tool results are saved before the combined answer, so that the tool calls saved are all answered
+			err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+				state.Messages = appendToolResults(state.Messages, results)
+				memory.SetRunMessages(ctx, state.Messages)
 				return nil
 			})
 			if err != nil {
 				return nil, err
 			}
-			if msg == nil {
-				return nil, schema.ErrNoValue
+		}
+
+		selected := make([]*schema.Message, 0, len(ids))
+		for _, id := range ids {
+			for _, result := range results {
+				if result != nil && result.ToolCallID == id {
+					selected = append(selected, result)
+					break
+				}
 			}
-			return msg, nil
-		}), nil
+		}
+
+		msg, err := combiner(ctx, selected)
+		if err != nil {
+			return nil, err
+		}
+		return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
 	}
 
 	nodeKeyDirectReturn := "direct_return"
@@ -275,21 +397,44 @@ func buildReturnDirectly(graph *compose.Graph[[]*schema.Message, *schema.Message
 
 	// this branch checks if the tool called should return directly. It either leads to END or back to ChatModel
 	err = graph.AddBranch(nodeKeyTools, compose.NewStreamGraphBranch(func(ctx context.Context, msgsStream *schema.StreamReader[[]*schema.Message]) (endNode string, err error) {
-		msgsStream.Close()
+		var results []*schema.Message
+		if checker != nil {
+			if results, err = concatToolResults(msgsStream); err != nil {
+				return "", err
+			}
+		} else {
+			msgsStream.Close()
+		}
 
+		var toolCalls []schema.ToolCall
+		var ids []string
 		err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
-			if len(state.ReturnDirectlyToolCallID) > 0 {
-				endNode = nodeKeyDirectReturn
-			} else {
-				endNode = nodeKeyModel
-			}
+			toolCalls = state.Messages[len(state.Messages)-1].ToolCalls
+			ids = state.ReturnDirectlyToolCallIDs
 			return nil
 		})
 		if err != nil {
 			return "", err
 		}
-		return endNode, nil
-	}, map[string]bool{nodeKeyModel: true, nodeKeyDirectReturn: true}))
+
+		if checker != nil {
+			if ids, err = checkReturnDirectly(ctx, checker, toolCalls, results, ids); err != nil {
+				return "", err
+			}
+			err = compose.ProcessState[*state](ctx, func(_ context.Context, state *state) error {
+				state.ReturnDirectlyToolCallIDs = ids
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if len(ids) > 0 {
+			return nodeKeyDirectReturn, nil
+		}
+		return maxStep.next(ctx, nodeKeyModel)
+	}, map[string]bool{nodeKeyModel: true, nodeKeyDirectReturn: true, nodeKeyMaxStep: true}))
 	if err != nil {
 		return err
 	}
@@ -297,6 +442,93 @@ func buildReturnDirectly(graph *compose.Graph[[]*schema.Message, *schema.Message
 	return graph.AddEdge(nodeKeyDirectReturn, compose.END)
 }
 
+// checkReturnDirectly returns the IDs of the tool calls returned directly, in the order of the tool calls,
+// including the ones returned directly by name, and the ones the checker decides to.
+func checkReturnDirectly(ctx context.Context, checker ReturnDirectlyChecker, toolCalls []schema.ToolCall,
+	results []*schema.Message, byName []string) ([]string, error) {
+	selected := make(map[string]bool, len(byName))
+	for _, id := range byName {
+		selected[id] = true
+	}
+
+	ids := make([]string, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		if selected[toolCall.ID] {
+			ids = append(ids, toolCall.ID)
+			continue
+		}
+
+		var result *schema.Message
+		for _, r := range results {
+			if r != nil && r.ToolCallID == toolCall.ID {
+				result = r
+				break
+			}
+		}
+		if result == nil {
+			continue
+		}
+
+		ok, err := checker(ctx, toolCall, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check return directly of tool %s: %w", toolCall.Function.Name, err)
+		}
+		if ok {
+			ids = append(ids, toolCall.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func defaultReturnDirectlyCombiner(_ context.Context, results []*schema.Message) (*schema.Message, error) {
+	contents := make([]string, 0, len(results))
+	for _, result := range results {
+		contents = append(contents, result.Content)
+	}
+	return schema.AssistantMessage(strings.Join(contents, "\n"), nil), nil
+}
+
+// concatToolResults reads the streaming output of the tools node, and concatenates the chunks of each tool result.
+// The results are in the order of the tool calls, and the result is nil if the tool outputs nothing.
+func concatToolResults(sr *schema.StreamReader[[]*schema.Message]) ([]*schema.Message, error) {
+	defer sr.Close()
+
+	var chunks [][]*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, m := range chunk {
+			if m == nil {
+				continue
+			}
+			for len(chunks) <= i {
+				chunks = append(chunks, nil)
+			}
+			chunks[i] = append(chunks[i], m)
+		}
+	}
+
+	results := make([]*schema.Message, len(chunks))
+	for i, c := range chunks {
+		if len(c) == 0 {
+			continue
+		}
+		result, err := schema.ConcatMessages(c)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
 func genToolInfos(ctx context.Context, config compose.ToolsNodeConfig) ([]*schema.ToolInfo, error) {
 	toolInfos := make([]*schema.ToolInfo, 0, len(config.Tools))
 	for _, t := range config.Tools {
@@ -311,23 +543,41 @@ func genToolInfos(ctx context.Context, config compose.ToolsNodeConfig) ([]*schem
 	return toolInfos, nil
 }
 
-func getReturnDirectlyToolCallID(input *schema.Message, toolReturnDirectly map[string]struct{}) string {
+func getReturnDirectlyToolCallIDs(input *schema.Message, toolReturnDirectly map[string]struct{}) []string {
 	if len(toolReturnDirectly) == 0 {
-		return ""
+		return nil
 	}
 
+	var ids []string
 	for _, toolCall := range input.ToolCalls {
 		if _, ok := toolReturnDirectly[toolCall.Function.Name]; ok {
-			return toolCall.ID
+			ids = append(ids, toolCall.ID)
 		}
 	}
 
-	return ""
+	return ids
 }
 
 // Generate generates a response from the agent.
 func (r *Agent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (output *schema.Message, err error) {
-	output, err = r.runnable.Invoke(ctx, input, agent.GetComposeOptions(opts...)...)
+	if r.guardrail != nil {
+		ctx = guardrail.InitCallbacks(ctx, opts...)
+		if input, err = r.guardrail.CheckInput(ctx, input); err != nil {
+			if reply, ok := guardrail.AsBlocked(err); ok {
+				return reply, nil
+			}
+			return nil, err
+		}
+	}
+
+	composeOpts := agent.GetComposeOptions(opts...)
+	if sessionID := memory.GetSessionID(opts...); r.memory != nil && len(sessionID) > 0 {
+		output, err = r.memory.Generate(ctx, sessionID, input, func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+			return r.invoke(ctx, input, composeOpts...)
+		})
+	} else {
+		output, err = r.invoke(ctx, input, composeOpts...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +588,32 @@ func (r *Agent) Generate(ctx context.Context, input []*schema.Message, opts ...a
 // Stream calls the agent and returns a stream response.
 func (r *Agent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (
 	output *schema.StreamReader[*schema.Message], err error) {
-	res, err := r.runnable.Stream(ctx, input, agent.GetComposeOptions(opts...)...)
+	return r.streamWith(ctx, input, true, opts...)
+}
+
+// streamWith streams the output of the agent, with the text chunks read ahead by the StreamToolCallChecker
+// forwarded to the caller if forwardChunks, e.g. not for StreamSteps, which streams the chat model output by itself.
+func (r *Agent) streamWith(ctx context.Context, input []*schema.Message, forwardChunks bool, opts ...agent.AgentOption) (
+	output *schema.StreamReader[*schema.Message], err error) {
+	if r.guardrail != nil {
+		ctx = guardrail.InitCallbacks(ctx, opts...)
+		if input, err = r.guardrail.CheckInput(ctx, input); err != nil {
+			if reply, ok := guardrail.AsBlocked(err); ok {
+				return schema.StreamReaderFromArray([]*schema.Message{reply}), nil
+			}
+			return nil, err
+		}
+	}
+
+	var res *schema.StreamReader[*schema.Message]
+	composeOpts := agent.GetComposeOptions(opts...)
+	if sessionID := memory.GetSessionID(opts...); r.memory != nil && len(sessionID) > 0 {
+		res, err = r.memory.Stream(ctx, sessionID, input, func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+			return r.stream(ctx, input, forwardChunks, composeOpts...)
+		})
+	} else {
+		res, err = r.stream(ctx, input, forwardChunks, composeOpts...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -346,6 +621,55 @@ func (r *Agent) Stream(ctx context.Context, input []*schema.Message, opts ...age
 	return res, nil
 }
 
+func (r *Agent) invoke(ctx context.Context, input []*schema.Message, opts ...compose.Option) (*schema.Message, error) {
+	output, err := r.runnable.Invoke(ctx, input, opts...)
+	if reply, ok := guardrail.AsBlocked(err); ok {
+		return reply, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.guardrail != nil {
+		if output, err = r.guardrail.Check(ctx, guardrail.StageOutput, output); err != nil {
+			if reply, ok := guardrail.AsBlocked(err); ok {
+				return reply, nil
+			}
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+func (r *Agent) stream(ctx context.Context, input []*schema.Message, forwardChunks bool, opts ...compose.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	run := func(ctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		output, err := r.runnable.Stream(ctx, input, opts...)
+		if reply, ok := guardrail.AsBlocked(err); ok {
+			return schema.StreamReaderFromArray([]*schema.Message{reply}), nil
+		}
+		return output, err
+	}
+
+	var (
+		output *schema.StreamReader[*schema.Message]
+		err    error
+	)
+	if forwardChunks {
+		output, err = forward.Stream(ctx, r.graph, run)
+	} else {
+		output, err = run(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.guardrail != nil {
+		output = r.guardrail.CheckStream(ctx, guardrail.StageOutput, output)
+	}
+	return output, nil
+}
+
 // ExportGraph exports the underlying graph from Agent, along with the []compose.GraphAddNodeOpt to be used when adding this graph to another graph.
 func (r *Agent) ExportGraph() (compose.AnyGraph, []compose.GraphAddNodeOpt) {
 	return r.graph, r.graphAddNodeOpts
@@ -22,16 +22,20 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/bytedance/sonic"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
+	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/flow/agent/memory"
 	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
 	"github.com/cloudwego/eino/schema"
 	template "github.com/cloudwego/eino/utils/callbacks"
@@ -337,6 +341,345 @@ func TestReactStream(t *testing.T) {
 	t.Log("parallel tool call with return directly: ", msg.Content)
 }
 
+func TestReactStreamTextBeforeToolCall(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	idx := 0
+	times := 0
+	cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (
+			*schema.StreamReader[*schema.Message], error) {
+			times++
+			if times == 1 {
+				// text chunks before the tool call chunks, e.g. Claude
+				return schema.StreamReaderFromArray([]*schema.Message{
+					schema.AssistantMessage("", nil),
+					schema.AssistantMessage("let me ", nil),
+					schema.AssistantMessage("greet max", nil),
+					schema.AssistantMessage("", []schema.ToolCall{{Index: &idx, ID: "1", Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": `}}}),
+					schema.AssistantMessage("", []schema.ToolCall{{Index: &idx, Function: schema.FunctionCall{Arguments: `"max"}`}}}),
+				}), nil
+			}
+
+			assert.Len(t, input, 3)
+			assert.Equal(t, "let me greet max", input[1].Content)
+			assert.Len(t, input[1].ToolCalls, 1)
+			assert.Equal(t, `{"name": "max"}`, input[1].ToolCalls[0].Function.Arguments)
+			assert.Equal(t, schema.Tool, input[2].Role)
+
+			return schema.StreamReaderFromArray([]*schema.Message{
+				schema.AssistantMessage("bye ", nil),
+				schema.AssistantMessage("max", nil),
+			}), nil
+		}).Times(2)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}},
+		},
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Stream(ctx, []*schema.Message{schema.UserMessage("greet max")})
+	assert.NoError(t, err)
+
+	defer out.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := out.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+
+	// the text before the tool call is streamed while the checker reads ahead
+	msg, err := schema.ConcatMessages(chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, "let me greet maxbye max", msg.Content)
+	assert.Equal(t, 2, times)
+}
+
+func TestReactStreamDefaultChecker(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	sr, sw := schema.Pipe[*schema.Message](2)
+	cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).Return(sr, nil).Times(1)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}},
+		},
+	})
+	assert.NoError(t, err)
+
+	sw.Send(schema.AssistantMessage("bye ", nil), nil)
+
+	out, err := a.Stream(ctx, []*schema.Message{schema.UserMessage("greet max")})
+	assert.NoError(t, err)
+	defer out.Close()
+
+	// the first chunk arrives while the model is still streaming
+	chunk, err := out.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "bye ", chunk.Content)
+
+	sw.Send(schema.AssistantMessage("max", nil), nil)
+	sw.Close()
+
+	chunk, err = out.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "max", chunk.Content)
+
+	_, err = out.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReactReturnDirectly(t *testing.T) {
+	ctx := context.Background()
+
+	twoToolCalls := func(greetName, streamName string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "greet", Arguments: fmt.Sprintf(`{"name": "%s"}`, greetName)}},
+			{ID: "2", Function: schema.FunctionCall{Name: "greet in stream", Arguments: fmt.Sprintf(`{"name": "%s"}`, streamName)}},
+		})
+	}
+
+	// the result returns directly if it greets bob
+	checker := func(ctx context.Context, toolCall schema.ToolCall, result *schema.Message) (bool, error) {
+		assert.Equal(t, toolCall.ID, result.ToolCallID)
+		return strings.Contains(result.Content, "bob"), nil
+	}
+
+	run := func(t *testing.T, first *schema.Message, returnDirectly map[string]struct{}, stream bool) *schema.Message {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockChatModel(ctrl)
+		cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+		times := 0
+		next := func() *schema.Message {
+			times++
+			if times == 1 {
+				return first
+			}
+			return schema.AssistantMessage("bye", nil)
+		}
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+				return next(), nil
+			}).AnyTimes()
+		cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+				return schema.StreamReaderFromArray([]*schema.Message{next()}), nil
+			}).AnyTimes()
+
+		a, err := NewAgent(ctx, &AgentConfig{
+			Model: cm,
+			ToolsConfig: compose.ToolsNodeConfig{
+				Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}, &fakeStreamToolGreetForTest{tarCount: 10}},
+			},
+			ToolReturnDirectly:    returnDirectly,
+			ReturnDirectlyChecker: checker,
+		})
+		assert.NoError(t, err)
+
+		if !stream {
+			out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("greet")})
+			assert.NoError(t, err)
+			return out
+		}
+
+		sr, err := a.Stream(ctx, []*schema.Message{schema.UserMessage("greet")})
+		assert.NoError(t, err)
+		defer sr.Close()
+
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			assert.NoError(t, err)
+			chunks = append(chunks, chunk)
+		}
+		out, err := schema.ConcatMessages(chunks)
+		assert.NoError(t, err)
+		return out
+	}
+
+	byName := map[string]struct{}{"greet": {}}
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			// both return directly, by name and by the checker
+			out := run(t, twoToolCalls("max", "bob"), byName, stream)
+			assert.Equal(t, schema.Assistant, out.Role)
+			assert.Equal(t, "{\"say\": \"hello max\"}\n{\"say\": \"hello bob\"}", out.Content)
+
+			// only the one by name returns directly
+			out = run(t, twoToolCalls("max", "alice"), byName, stream)
+			assert.Equal(t, schema.ToolMessage(`{"say": "hello max"}`, "1"), out)
+
+			// only the one by the checker returns directly
+			out = run(t, twoToolCalls("max", "bob"), nil, stream)
+			assert.Equal(t, schema.ToolMessage(`{"say": "hello bob"}`, "2"), out)
+
+			// none returns directly
+			out = run(t, twoToolCalls("max", "alice"), nil, stream)
+			assert.Equal(t, "bye", out.Content)
+		})
+	}
+}
+
+type guardrailCallbackForTest struct {
+	stages []guardrail.Stage
+}
+
+type guardrailStageKey struct{}
+
+// handler records the stages of the checks which don't pass
+func (g *guardrailCallbackForTest) handler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			if info.Component != guardrail.ComponentOfGuardrail {
+				return ctx
+			}
+			return context.WithValue(ctx, guardrailStageKey{}, guardrail.ConvCallbackInput(input).Stage)
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if info.Component != guardrail.ComponentOfGuardrail {
+				return ctx
+			}
+			if out := guardrail.ConvCallbackOutput(output); out.Decision.Action != guardrail.ActionPass {
+				g.stages = append(g.stages, ctx.Value(guardrailStageKey{}).(guardrail.Stage))
+			}
+			return ctx
+		}).Build()
+}
+
+func TestReactWithGuardrail(t *testing.T) {
+	ctx := context.Background()
+
+	// blocks "bomb" and "rm -rf", and redacts "secret"
+	checker := func(_ context.Context, stage guardrail.Stage, msg *schema.Message) (*guardrail.Decision, error) {
+		text := msg.Content
+		for _, tc := range msg.ToolCalls {
+			text += tc.Function.Arguments
+		}
+		if strings.Contains(text, "bomb") || strings.Contains(text, "rm -rf") {
+			return &guardrail.Decision{Action: guardrail.ActionBlock, Reply: "blocked at " + string(stage)}, nil
+		}
+		if !strings.Contains(text, "secret") {
+			return nil, nil
+		}
+
+		redacted := *msg
+		redacted.Content = strings.ReplaceAll(msg.Content, "secret", "***")
+		redacted.ToolCalls = nil
+		for _, tc := range msg.ToolCalls {
+			tc.Function.Arguments = strings.ReplaceAll(tc.Function.Arguments, "secret", "***")
+			redacted.ToolCalls = append(redacted.ToolCalls, tc)
+		}
+		return &guardrail.Decision{Action: guardrail.ActionRedact, Message: &redacted}, nil
+	}
+	g, err := guardrail.New(&guardrail.Config{Checkers: []guardrail.Checker{checker}, Lookahead: 4})
+	assert.NoError(t, err)
+
+	newAgent := func(t *testing.T, toolArgs, answer string) *Agent {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockChatModel(ctrl)
+		cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+		next := func(input []*schema.Message) *schema.Message {
+			if last := input[len(input)-1]; last.Role == schema.Tool {
+				return schema.AssistantMessage(answer+" "+last.Content, nil)
+			}
+			return schema.AssistantMessage("", []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "greet", Arguments: toolArgs}}})
+		}
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+				return next(input), nil
+			}).AnyTimes()
+		cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+				msg := next(input)
+				if len(msg.ToolCalls) > 0 {
+					return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+				}
+				var chunks []*schema.Message
+				for _, word := range strings.SplitAfter(msg.Content, " ") {
+					chunks = append(chunks, schema.AssistantMessage(word, nil))
+				}
+				return schema.StreamReaderFromArray(chunks), nil
+			}).AnyTimes()
+
+		a, err := NewAgent(ctx, &AgentConfig{
+			Model:       cm,
+			ToolsConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}}},
+			Guardrail:   g,
+		})
+		assert.NoError(t, err)
+		return a
+	}
+
+	generate := func(t *testing.T, a *Agent, input string, stream bool, opts ...agent.AgentOption) string {
+		if !stream {
+			out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage(input)}, opts...)
+			assert.NoError(t, err)
+			return out.Content
+		}
+
+		sr, err := a.Stream(ctx, []*schema.Message{schema.UserMessage(input)}, opts...)
+		assert.NoError(t, err)
+		defer sr.Close()
+		var sb strings.Builder
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return sb.String()
+			}
+			assert.NoError(t, err)
+			sb.WriteString(chunk.Content)
+		}
+	}
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			cb := &guardrailCallbackForTest{}
+			out := generate(t, newAgent(t, `{"name": "max"}`, "done"), "how to make a bomb", stream, guardrail.WithCallbacks(cb.handler()))
+			assert.Equal(t, "blocked at input", out)
+			assert.Equal(t, []guardrail.Stage{guardrail.StageInput}, cb.stages)
+
+			out = generate(t, newAgent(t, `{"name": "rm -rf"}`, "done"), "greet", stream)
+			assert.Equal(t, "blocked at tool_call", out)
+
+			// the tool is called with the redacted arguments, and the output is redacted
+			cb = &guardrailCallbackForTest{}
+			out = generate(t, newAgent(t, `{"name": "secret"}`, "the secret is"), "greet", stream, guardrail.WithCallbacks(cb.handler()))
+			assert.Equal(t, `the *** is {"say": "hello ***"}`, out)
+			assert.Equal(t, guardrail.StageToolCall, cb.stages[0])
+			assert.Contains(t, cb.stages, guardrail.StageOutput)
+
+			// the checks in the graph are reported to the handlers of the graph too
+			cb = &guardrailCallbackForTest{}
+			_ = generate(t, newAgent(t, `{"name": "secret"}`, "done"), "greet", stream,
+				agent.WithComposeOptions(compose.WithCallbacks(cb.handler())))
+			assert.Equal(t, []guardrail.Stage{guardrail.StageToolCall}, cb.stages)
+		})
+	}
+}
+
 func TestReactWithModifier(t *testing.T) {
 	ctx := context.Background()
 
@@ -545,6 +888,172 @@ func TestAgentInGraph(t *testing.T) {
 
 }
 
+func TestReactWithToolSelector(t *testing.T) {
+	ctx := context.Background()
+
+	greet := &fakeToolGreetForTest{tarCount: 1}
+	streamGreet := &fakeStreamToolGreetForTest{tarCount: 1}
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+
+	var seenTools [][]string
+	times := 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			var names []string
+			for _, ti := range model.GetCommonOptions(&model.Options{}, opts...).Tools {
+				names = append(names, ti.Name)
+			}
+			seenTools = append(seenTools, names)
+
+			times++
+			if times == 1 {
+				return schema.AssistantMessage("", []schema.ToolCall{{
+					ID:       randStr(),
+					Function: schema.FunctionCall{Name: "greet in stream", Arguments: `{"name": "max"}`},
+				}}), nil
+			}
+			return schema.AssistantMessage("bye", nil), nil
+		}).AnyTimes()
+
+	selector := func(ctx context.Context, tools []*schema.ToolInfo, input []*schema.Message) ([]*schema.ToolInfo, error) {
+		assert.Len(t, tools, 2)
+		if input[len(input)-1].Role == schema.User {
+			return []*schema.ToolInfo{tools[1]}, nil
+		}
+		return nil, nil
+	}
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{greet, streamGreet},
+		},
+		ToolSelector: selector,
+		PinnedTools:  []string{"greet"},
+		MaxStep:      40,
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("greet max in stream")})
+	assert.NoError(t, err)
+	assert.Equal(t, "bye", out.Content)
+	assert.Equal(t, [][]string{{"greet", "greet in stream"}, {"greet"}}, seenTools)
+
+	_, err = NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{greet},
+		},
+		ToolSelector: selector,
+		PinnedTools:  []string{"unknown"},
+	})
+	assert.Error(t, err)
+}
+
+func TestReactWithMemory(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	var inputs [][]*schema.Message
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			inputs = append(inputs, input)
+			if input[len(input)-1].Role == schema.User {
+				return schema.AssistantMessage("", []schema.ToolCall{{
+					ID:       randStr(),
+					Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`},
+				}}), nil
+			}
+			return schema.AssistantMessage("bye", nil), nil
+		}).AnyTimes()
+
+	mem, err := memory.NewMemory(&memory.Config{Store: memory.NewInMemoryStore()})
+	assert.NoError(t, err)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}},
+		},
+		Memory: mem,
+	})
+	assert.NoError(t, err)
+
+	_, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("greet max")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("greet max again")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bye", out.Content)
+
+	// user, tool call, tool result, answer of the first run, and the new user message
+	assert.Len(t, inputs[2], 5)
+	assert.Equal(t, schema.Tool, inputs[2][2].Role)
+
+	history, err := mem.Store().Load(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 8)
+
+	// without session id, the agent is stateless
+	_, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("hi")})
+	assert.NoError(t, err)
+	assert.Len(t, inputs[4], 1)
+}
+
+func TestReactReturnDirectlyWithMemory(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	var inputs [][]*schema.Message
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			inputs = append(inputs, input)
+			return schema.AssistantMessage("", []schema.ToolCall{
+				{ID: randStr(), Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`}},
+				{ID: randStr(), Function: schema.FunctionCall{Name: "greet in stream", Arguments: `{"name": "bob"}`}},
+			}), nil
+		}).Times(2)
+
+	mem, err := memory.NewMemory(&memory.Config{Store: memory.NewInMemoryStore()})
+	assert.NoError(t, err)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}, &fakeStreamToolGreetForTest{tarCount: 10}},
+		},
+		ToolReturnDirectly: map[string]struct{}{"greet": {}, "greet in stream": {}},
+		Memory:             mem,
+	})
+	assert.NoError(t, err)
+
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("greet max and bob")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+	assert.Equal(t, schema.AssistantMessage("{\"say\": \"hello max\"}\n{\"say\": \"hello bob\"}", nil), out)
+
+	_, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("again")}, memory.WithSessionID("s1"))
+	assert.NoError(t, err)
+
+	// user, tool calls, both tool results, and the combined answer as an assistant message of the first run,
+	// instead of a tool message answering no tool call, followed by the new user message
+	history := inputs[1]
+	if assert.Len(t, history, 6) {
+		assert.Len(t, history[1].ToolCalls, 2)
+		assert.Equal(t, history[1].ToolCalls[0].ID, history[2].ToolCallID)
+		assert.Equal(t, history[1].ToolCalls[1].ID, history[3].ToolCallID)
+		assert.Equal(t, out, history[4])
+		assert.Equal(t, schema.UserMessage("again"), history[5])
+	}
+}
+
 type fakeStreamToolGreetForTest struct {
 	tarCount int
 	curCount int
@@ -0,0 +1,271 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/guardrail"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// StepType is the type of Step.
+type StepType string
+
+const (
+	// StepModelChunk is a chunk of the chat model output calling tools, e.g. the thinking text before tool calls.
+	StepModelChunk StepType = "model_chunk"
+	// StepToolCall is the start of a tool call, with the arguments.
+	StepToolCall StepType = "tool_call"
+	// StepToolResult is the result of a tool call.
+	StepToolResult StepType = "tool_result"
+	// StepFinalAnswer is a chunk of the final answer of the agent, which is not streamed as StepModelChunk.
+	StepFinalAnswer StepType = "final_answer"
+)
+
+// Step is an intermediate step of the agent, streamed by Agent.StreamSteps.
+type Step struct {
+	Type StepType
+	// Index is the index of the step, which starts from 0 and increases by one on each call to the chat model.
+	// The tool calls and tool results share the index of the chat model output they are called by.
+	Index int
+
+	// ToolCallID and ToolName are set for StepToolCall and StepToolResult.
+	ToolCallID string
+	ToolName   string
+	// Arguments is the arguments in JSON of StepToolCall.
+	Arguments string
+	// Result is the result of StepToolResult, the chunks are concatenated for the streaming tools.
+	Result string
+
+	// Message is the message chunk of StepModelChunk and StepFinalAnswer.
+	Message *schema.Message
+}
+
+// StreamSteps calls the agent in stream mode, and returns the stream of the intermediate steps,
+// including the chunks of the chat model output, the tool calls, the tool results and the chunks of the final answer.
+// e.g.
+//
+//	steps, err := agent.StreamSteps(ctx, input)
+//	if err != nil {...}
+//	defer steps.Close()
+//	for {
+//		step, err := steps.Recv()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		if err != nil {...}
+//		switch step.Type {
+//		case react.StepToolCall:
+//			fmt.Printf("calling tool %s with %s\n", step.ToolName, step.Arguments)
+//		...
+//		}
+//	}
+//
+// The chat model output calling tools is streamed as StepModelChunk, and the final answer is streamed as StepFinalAnswer only,
+// so each chunk is streamed once. Whether the output calls tools is decided by the StreamToolCallChecker of the agent,
+// which runs again on a copy of the chat model output, so the StepModelChunk are delayed as long as the checker reads ahead.
+// With Guardrail, the chunks of the chat model are checked as the output too, before they are streamed.
+func (r *Agent) StreamSteps(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (
+	*schema.StreamReader[*Step], error) {
+	sr, sw := schema.Pipe[*Step](10)
+	c := &stepCollector{sw: sw, guardrail: r.guardrail, toolCallChecker: r.toolCallChecker, index: -1}
+
+	handler := BuildAgentCallback(&template.ModelCallbackHandler{
+		OnStart:               c.onModelStart,
+		OnEnd:                 c.onModelEnd,
+		OnEndWithStreamOutput: c.onModelEndWithStreamOutput,
+	}, &template.ToolCallbackHandler{
+		OnStart:               c.onToolStart,
+		OnEnd:                 c.onToolEnd,
+		OnEndWithStreamOutput: c.onToolEndWithStreamOutput,
+	})
+
+	opts = append(opts[:len(opts):len(opts)],
+		agent.WithComposeOptions(compose.WithCallbacks(handler).DesignateNode(nodeKeyModel, nodeKeyTools)))
+	out, err := r.streamWith(ctx, input, false, opts...)
+	if err != nil {
+		sw.Close()
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				_ = sw.Send(nil, safe.NewPanicErr(panicErr, debug.Stack()))
+			}
+			sw.Close()
+		}()
+		defer out.Close()
+
+		for {
+			msg, err := out.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				_ = sw.Send(nil, err)
+				return
+			}
+			if c.send(&Step{Type: StepFinalAnswer, Index: c.currentIndex(), Message: msg}) {
+				return
+			}
+		}
+	}()
+
+	return sr, nil
+}
+
+// stepCollector converts the callbacks of the chat model and the tools into steps.
+// The streams in the callbacks are read before returning, so that the steps keep the order they happen in.
+type stepCollector struct {
+	sw              *schema.StreamWriter[*Step]
+	guardrail       *guardrail.Guardrail
+	toolCallChecker func(ctx context.Context, modelOutput *schema.StreamReader[*schema.Message]) (bool, error)
+
+	mu    sync.Mutex
+	index int
+}
+
+func (c *stepCollector) currentIndex() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.index
+}
+
+// send returns true if the reader is closed.
+func (c *stepCollector) send(step *Step) bool {
+	return c.sw.Send(step, nil)
+}
+
+func (c *stepCollector) onModelStart(ctx context.Context, _ *callbacks.RunInfo, _ *model.CallbackInput) context.Context {
+	c.mu.Lock()
+	c.index++
+	c.mu.Unlock()
+	return ctx
+}
+
+func (c *stepCollector) onModelEnd(ctx context.Context, _ *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+	if output == nil || output.Message == nil {
+		return ctx
+	}
+
+	msg := output.Message
+	if len(msg.ToolCalls) == 0 { // the final answer is streamed by the output of the agent
+		return ctx
+	}
+	if c.guardrail != nil {
+		var err error
+		if msg, err = c.guardrail.Check(ctx, guardrail.StageOutput, msg); err != nil {
+			reply, ok := guardrail.AsBlocked(err)
+			if !ok { // the error is returned by the output of the agent
+				return ctx
+			}
+			msg = reply
+		}
+	}
+
+	c.send(&Step{Type: StepModelChunk, Index: c.currentIndex(), Message: msg})
+	return ctx
+}
+
+func (c *stepCollector) onModelEndWithStreamOutput(ctx context.Context, _ *callbacks.RunInfo,
+	output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+	msgs := schema.StreamReaderWithConvert(output, func(chunk *model.CallbackOutput) (*schema.Message, error) {
+		if chunk == nil || chunk.Message == nil {
+			return nil, schema.ErrNoValue
+		}
+		return chunk.Message, nil
+	})
+
+	copies := msgs.Copy(2)
+	msgs = copies[1]
+	isToolCall, err := c.toolCallChecker(ctx, copies[0])
+	copies[0].Close()
+	if err != nil || !isToolCall { // the final answer and the error are streamed by the output of the agent
+		msgs.Close()
+		return ctx
+	}
+
+	if c.guardrail != nil {
+		msgs = c.guardrail.CheckStream(ctx, guardrail.StageOutput, msgs)
+	}
+	defer msgs.Close()
+
+	index := c.currentIndex()
+	for {
+		msg, err := msgs.Recv()
+		if err != nil { // the error is returned by the output of the agent
+			return ctx
+		}
+		if c.send(&Step{Type: StepModelChunk, Index: index, Message: msg}) {
+			return ctx
+		}
+	}
+}
+
+func (c *stepCollector) onToolStart(ctx context.Context, info *callbacks.RunInfo, input *tool.CallbackInput) context.Context {
+	step := &Step{Type: StepToolCall, Index: c.currentIndex(), ToolCallID: compose.GetToolCallID(ctx), ToolName: info.Name}
+	if input != nil {
+		step.Arguments = input.ArgumentsInJSON
+	}
+	c.send(step)
+	return ctx
+}
+
+func (c *stepCollector) onToolEnd(ctx context.Context, info *callbacks.RunInfo, output *tool.CallbackOutput) context.Context {
+	step := &Step{Type: StepToolResult, Index: c.currentIndex(), ToolCallID: compose.GetToolCallID(ctx), ToolName: info.Name}
+	if output != nil {
+		step.Result = output.Response
+	}
+	c.send(step)
+	return ctx
+}
+
+func (c *stepCollector) onToolEndWithStreamOutput(ctx context.Context, info *callbacks.RunInfo,
+	output *schema.StreamReader[*tool.CallbackOutput]) context.Context {
+	defer output.Close()
+
+	var sb strings.Builder
+	for {
+		chunk, err := output.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil { // the error is returned by the output of the agent
+			return ctx
+		}
+		if chunk != nil {
+			sb.WriteString(chunk.Response)
+		}
+	}
+
+	c.send(&Step{Type: StepToolResult, Index: c.currentIndex(), ToolCallID: compose.GetToolCallID(ctx), ToolName: info.Name, Result: sb.String()})
+	return ctx
+}
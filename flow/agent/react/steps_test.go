@@ -0,0 +1,157 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestStreamSteps(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	outputs := [][]*schema.Message{
+		{
+			schema.AssistantMessage("let me ", nil),
+			schema.AssistantMessage("greet", nil),
+			schema.AssistantMessage("", []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`}}}),
+		},
+		{
+			schema.AssistantMessage("", []schema.ToolCall{{ID: "call_2", Function: schema.FunctionCall{Name: "greet in stream", Arguments: `{"name": "bob"}`}}}),
+		},
+		{
+			schema.AssistantMessage("bye ", nil),
+			schema.AssistantMessage("all", nil),
+		},
+	}
+	times := 0
+	cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+			times++
+			return schema.StreamReaderFromArray(outputs[times-1]), nil
+		}).Times(3)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}, &fakeStreamToolGreetForTest{tarCount: 10}},
+		},
+	})
+	assert.NoError(t, err)
+
+	sr, err := a.StreamSteps(ctx, []*schema.Message{schema.UserMessage("greet max and bob")})
+	assert.NoError(t, err)
+	defer sr.Close()
+
+	var steps []*Step
+	for {
+		step, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		steps = append(steps, step)
+	}
+
+	assert.Equal(t, []*Step{
+		{Type: StepModelChunk, Index: 0, Message: outputs[0][0]},
+		{Type: StepModelChunk, Index: 0, Message: outputs[0][1]},
+		{Type: StepModelChunk, Index: 0, Message: outputs[0][2]},
+		{Type: StepToolCall, Index: 0, ToolCallID: "call_1", ToolName: "greet", Arguments: `{"name": "max"}`},
+		{Type: StepToolResult, Index: 0, ToolCallID: "call_1", ToolName: "greet", Result: `{"say": "hello max"}`},
+		{Type: StepModelChunk, Index: 1, Message: outputs[1][0]},
+		{Type: StepToolCall, Index: 1, ToolCallID: "call_2", ToolName: "greet in stream", Arguments: `{"name": "bob"}`},
+		{Type: StepToolResult, Index: 1, ToolCallID: "call_2", ToolName: "greet in stream", Result: `{"say": "hello bob"}`},
+		{Type: StepFinalAnswer, Index: 2, Message: outputs[2][0]},
+		{Type: StepFinalAnswer, Index: 2, Message: outputs[2][1]},
+	}, steps)
+}
+
+func TestStreamStepsFinalAnswerOnce(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	outputs := [][]*schema.Message{
+		{
+			schema.AssistantMessage("", []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`}}}),
+		},
+		{
+			schema.AssistantMessage("hello ", nil),
+			schema.AssistantMessage("max", nil),
+		},
+	}
+	times := 0
+	cm.EXPECT().Stream(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+			times++
+			return schema.StreamReaderFromArray(outputs[times-1]), nil
+		}).Times(2)
+
+	a, err := NewAgent(ctx, &AgentConfig{
+		Model: cm,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools: []tool.BaseTool{&fakeToolGreetForTest{tarCount: 10}},
+		},
+	})
+	assert.NoError(t, err)
+
+	sr, err := a.StreamSteps(ctx, []*schema.Message{schema.UserMessage("greet max")})
+	assert.NoError(t, err)
+	defer sr.Close()
+
+	var (
+		modelChunks int
+		answer      []*schema.Message
+	)
+	for {
+		step, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		switch step.Type {
+		case StepModelChunk:
+			modelChunks++
+			assert.Equal(t, 0, step.Index)
+		case StepFinalAnswer:
+			answer = append(answer, step.Message)
+		}
+	}
+
+	assert.Equal(t, 1, modelChunks)
+	msg, err := schema.ConcatMessages(answer)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello max", msg.Content)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToolSelector selects the tools visible to the chat model for the current round, from all the tools in ToolsConfig.
+// input is the messages the chat model is called with, after MessageModifier is applied.
+// e.g. toolselect.NewEmbeddingSelector selects the tools most relevant to the conversation by embedding.
+type ToolSelector func(ctx context.Context, tools []*schema.ToolInfo, input []*schema.Message) ([]*schema.ToolInfo, error)
+
+// toolSelectModel selects the tools before each call to the chat model, and passes them by model.WithTools,
+// instead of binding all the tools to the chat model.
+type toolSelectModel struct {
+	model.ChatModel
+
+	tools    []*schema.ToolInfo
+	pinned   map[string]bool
+	selector ToolSelector
+}
+
+func newToolSelectModel(cm model.ChatModel, tools []*schema.ToolInfo, pinnedTools []string, selector ToolSelector) (*toolSelectModel, error) {
+	names := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		names[t.Name] = true
+	}
+
+	pinned := make(map[string]bool, len(pinnedTools))
+	for _, name := range pinnedTools {
+		if !names[name] {
+			return nil, fmt.Errorf("pinned tool %s not found in tools config", name)
+		}
+		pinned[name] = true
+	}
+
+	return &toolSelectModel{
+		ChatModel: cm,
+		tools:     tools,
+		pinned:    pinned,
+		selector:  selector,
+	}, nil
+}
+
+func (t *toolSelectModel) selectTools(ctx context.Context, input []*schema.Message) ([]*schema.ToolInfo, error) {
+	selected, err := t.selector(ctx, t.tools, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tools: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(selected)+len(t.pinned))
+	for name := range t.pinned {
+		wanted[name] = true
+	}
+	for _, s := range selected {
+		if s != nil {
+			wanted[s.Name] = true
+		}
+	}
+
+	// keep the order of tools config, so that the tools given to the chat model are stable
+	ret := make([]*schema.ToolInfo, 0, len(wanted))
+	for _, ti := range t.tools {
+		if wanted[ti.Name] {
+			ret = append(ret, ti)
+		}
+	}
+
+	return ret, nil
+}
+
+func (t *toolSelectModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	tools, err := t.selectTools(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.ChatModel.Generate(ctx, input, append(opts, model.WithTools(tools))...)
+}
+
+func (t *toolSelectModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	tools, err := t.selectTools(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.ChatModel.Stream(ctx, input, append(opts, model.WithTools(tools))...)
+}
+
+func (t *toolSelectModel) GetType() string {
+	typ, _ := components.GetType(t.ChatModel)
+	return typ
+}
+
+func (t *toolSelectModel) IsCallbacksEnabled() bool {
+	return components.IsCallbacksEnabled(t.ChatModel)
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// FinalAnswerToolName is the name of the tool TypedAgent gives the final answer by, in StructuredOutputToolCall mode.
+const FinalAnswerToolName = "final_answer"
+
+const defaultFinalAnswerDesc = "give the final answer to the user, call it once the task is done, instead of answering in text"
+
+// StructuredOutputMode is the way TypedAgent gets the structured final answer from the chat model.
+type StructuredOutputMode string
+
+const (
+	// StructuredOutputToolCall forces the final answer through the final_answer tool,
+	// whose parameters are inferred from the type of the final answer.
+	StructuredOutputToolCall StructuredOutputMode = "tool_call"
+	// StructuredOutputJSON parses the content of the final message as JSON,
+	// which works with the chat models configured to respond in JSON format, e.g. by the response format option of the model.
+	StructuredOutputJSON StructuredOutputMode = "json"
+)
+
+// TypedAgentConfig is the config for TypedAgent.
+type TypedAgentConfig struct {
+	AgentConfig
+
+	// Mode is the way to get the structured final answer.
+	// Optional. Default is StructuredOutputToolCall.
+	Mode StructuredOutputMode
+	// FinalAnswerDesc is the description of the final_answer tool in StructuredOutputToolCall mode.
+	// Optional.
+	FinalAnswerDesc string
+}
+
+// TypedAgent is a ReAct agent whose final answer is parsed into T.
+// If the final answer fails to be parsed, the chat model is asked once more with the conversation of the run and the parse error,
+// without running the agent again, so the tools are never called twice.
+// The extra call to the chat model is not saved to the memory of the agent.
+// e.g.
+//
+//	type Weather struct {
+//		City        string  `json:"city" jsonschema:"description=the city"`
+//		Temperature float64 `json:"temperature" jsonschema:"description=the temperature in celsius"`
+//	}
+//
+//	a, err := react.NewTypedAgent[*Weather](ctx, &react.TypedAgentConfig{AgentConfig: react.AgentConfig{...}})
+//	if err != nil {...}
+//	weather, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Beijing")})
+type TypedAgent[T any] struct {
+	agent  *Agent
+	mode   StructuredOutputMode
+	parser schema.MessageParser[T]
+
+	// repairModel is the chat model asked once more, which can only give the final answer.
+	repairModel model.ChatModel
+}
+
+// NewTypedAgent creates a TypedAgent, the schema of the final_answer tool is inferred from T by utils.GoStruct2ParamsOneOf.
+func NewTypedAgent[T any](ctx context.Context, config *TypedAgentConfig) (*TypedAgent[T], error) {
+	if config == nil {
+		return nil, errors.New("typed agent config is nil")
+	}
+
+	mode := config.Mode
+	if mode == "" {
+		mode = StructuredOutputToolCall
+	}
+
+	agentConfig := config.AgentConfig
+	repairModel := newWithOptionsModel(agentConfig.Model, model.WithTools(nil), model.WithToolChoice(schema.ToolChoiceForbidden))
+	switch mode {
+	case StructuredOutputToolCall:
+		params, err := utils.GoStruct2ParamsOneOf[T]()
+		if err != nil {
+			return nil, fmt.Errorf("failed to infer the parameters of final answer tool: %w", err)
+		}
+
+		desc := config.FinalAnswerDesc
+		if desc == "" {
+			desc = defaultFinalAnswerDesc
+		}
+
+		info := &schema.ToolInfo{Name: FinalAnswerToolName, Desc: desc, ParamsOneOf: params}
+		agentConfig.ToolsConfig.Tools = append(append([]tool.BaseTool{}, agentConfig.ToolsConfig.Tools...), &finalAnswerTool{info: info})
+		repairModel = newWithOptionsModel(agentConfig.Model,
+			model.WithTools([]*schema.ToolInfo{info}), model.WithToolChoice(schema.ToolChoiceForced))
+
+		returnDirectly := make(map[string]struct{}, len(agentConfig.ToolReturnDirectly)+1)
+		for name := range agentConfig.ToolReturnDirectly {
+			returnDirectly[name] = struct{}{}
+		}
+		returnDirectly[FinalAnswerToolName] = struct{}{}
+		agentConfig.ToolReturnDirectly = returnDirectly
+	case StructuredOutputJSON:
+	default:
+		return nil, fmt.Errorf("unknown structured output mode: %s", mode)
+	}
+
+	a, err := NewAgent(ctx, &agentConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedAgent[T]{
+		agent:       a,
+		mode:        mode,
+		parser:      schema.NewMessageJSONParser[T](&schema.MessageJSONParseConfig{ParseFrom: schema.MessageParseFromContent}),
+		repairModel: repairModel,
+	}, nil
+}
+
+// Generate runs the agent and returns the final answer parsed into T.
+func (a *TypedAgent[T]) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (T, error) {
+	var zero T
+
+	last := &lastModelCall{}
+	opts = append(opts[:len(opts):len(opts)],
+		agent.WithComposeOptions(compose.WithCallbacks(last.handler()).DesignateNode(nodeKeyModel)))
+	output, err := a.agent.Generate(ctx, input, opts...)
+	if err != nil {
+		return zero, err
+	}
+
+	answer, parseErr := a.parse(ctx, output)
+	if parseErr == nil {
+		return answer, nil
+	}
+
+	retry, ok := last.repairInput(a.feedback(parseErr))
+	if !ok {
+		return zero, fmt.Errorf("failed to parse final answer: %w", parseErr)
+	}
+
+	output, err = a.repairModel.Generate(ctx, retry)
+	if err != nil {
+		return zero, err
+	}
+
+	answer, err = a.parse(ctx, a.finalAnswerOf(output))
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse final answer: %w", err)
+	}
+
+	return answer, nil
+}
+
+// Agent returns the underlying ReAct agent, e.g. to export its graph.
+func (a *TypedAgent[T]) Agent() *Agent {
+	return a.agent
+}
+
+func (a *TypedAgent[T]) parse(ctx context.Context, output *schema.Message) (T, error) {
+	if a.mode == StructuredOutputToolCall && output.Role != schema.Tool {
+		var zero T
+		return zero, fmt.Errorf("the final answer is not given by the %s tool", FinalAnswerToolName)
+	}
+
+	if len(strings.TrimSpace(output.Content)) == 0 {
+		var zero T
+		return zero, errors.New("the final answer is empty")
+	}
+
+	return a.parser.Parse(ctx, &schema.Message{Role: output.Role, Content: utils.RepairJSON(output.Content)})
+}
+
+// finalAnswerOf returns the final answer in the output of the repair model,
+// which is the arguments of the final_answer tool call in StructuredOutputToolCall mode.
+func (a *TypedAgent[T]) finalAnswerOf(output *schema.Message) *schema.Message {
+	if a.mode != StructuredOutputToolCall {
+		return output
+	}
+
+	for _, toolCall := range output.ToolCalls {
+		if toolCall.Function.Name == FinalAnswerToolName {
+			return schema.ToolMessage(toolCall.Function.Arguments, toolCall.ID)
+		}
+	}
+	return output
+}
+
+func (a *TypedAgent[T]) feedback(err error) string {
+	if a.mode == StructuredOutputToolCall {
+		return fmt.Sprintf("Failed to get the final answer: %v. Call the %s tool with the arguments matching its parameters to give the final answer.",
+			err, FinalAnswerToolName)
+	}
+	return fmt.Sprintf("Failed to parse the answer: %v. Answer again in valid JSON only.", err)
+}
+
+// lastModelCall records the last call to the chat model in the run, whose input is the conversation so far.
+type lastModelCall struct {
+	mu     sync.Mutex
+	input  []*schema.Message
+	output *schema.Message
+}
+
+func (l *lastModelCall) handler() callbacks.Handler {
+	return BuildAgentCallback(&template.ModelCallbackHandler{
+		OnStart: func(ctx context.Context, _ *callbacks.RunInfo, input *model.CallbackInput) context.Context {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.input, l.output = input.Messages, nil
+			return ctx
+		},
+		OnEnd: func(ctx context.Context, _ *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.output = output.Message
+			return ctx
+		},
+	}, nil)
+}
+
+// repairInput returns the conversation of the last call followed by the feedback,
+// where the tool calls of the last output, e.g. the final_answer tool call with invalid arguments, are answered by the feedback.
+func (l *lastModelCall) repairInput(feedback string) ([]*schema.Message, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.input) == 0 || l.output == nil {
+		return nil, false
+	}
+
+	msgs := make([]*schema.Message, 0, len(l.input)+len(l.output.ToolCalls)+2)
+	msgs = append(msgs, l.input...)
+	msgs = append(msgs, l.output)
+	if len(l.output.ToolCalls) == 0 {
+		return append(msgs, schema.UserMessage(feedback)), true
+	}
+
+	for _, toolCall := range l.output.ToolCalls {
+		msgs = append(msgs, schema.ToolMessage(feedback, toolCall.ID))
+	}
+	return msgs, true
+}
+
+// finalAnswerTool returns its arguments as is, which are returned directly by the agent as the final answer.
+type finalAnswerTool struct {
+	info *schema.ToolInfo
+}
+
+func (f *finalAnswerTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return f.info, nil
+}
+
+func (f *finalAnswerTool) InvokableRun(_ context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	return argumentsInJSON, nil
+}
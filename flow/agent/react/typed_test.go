@@ -0,0 +1,193 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package react
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type typedWeather struct {
+	City        string  `json:"city" jsonschema:"description=the city"`
+	Temperature float64 `json:"temperature" jsonschema:"description=the temperature in celsius"`
+}
+
+func TestTypedAgentToolCall(t *testing.T) {
+	ctx := context.Background()
+
+	fakeTool := &fakeToolGreetForTest{tarCount: 100}
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+
+	var boundTools []*schema.ToolInfo
+	cm.EXPECT().BindTools(gomock.Any()).DoAndReturn(func(tools []*schema.ToolInfo) error {
+		boundTools = tools
+		return nil
+	}).AnyTimes()
+
+	times := 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			times++
+			switch times {
+			case 1:
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "1", Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`}},
+				}), nil
+			case 2:
+				// answers in text instead of calling the final answer tool
+				return schema.AssistantMessage("it's sunny", nil), nil
+			default:
+				// only the chat model is asked once more, with the conversation so far, and it can only give the final answer
+				if assert.Len(t, input, 5) {
+					assert.Equal(t, `{"say": "hello max"}`, input[2].Content)
+					assert.Equal(t, "it's sunny", input[3].Content)
+					assert.Equal(t, schema.User, input[4].Role)
+					assert.Contains(t, input[4].Content, FinalAnswerToolName)
+				}
+				o := model.GetCommonOptions(nil, opts...)
+				if assert.Len(t, o.Tools, 1) {
+					assert.Equal(t, FinalAnswerToolName, o.Tools[0].Name)
+				}
+				assert.Equal(t, schema.ToolChoiceForced, *o.ToolChoice)
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "2", Function: schema.FunctionCall{Name: FinalAnswerToolName, Arguments: `{"city": "Beijing", "temperature": 25.5,}`}},
+				}), nil
+			}
+		}).AnyTimes()
+
+	a, err := NewTypedAgent[*typedWeather](ctx, &TypedAgentConfig{
+		AgentConfig: AgentConfig{
+			Model:       cm,
+			ToolsConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{fakeTool}},
+			MaxStep:     40,
+		},
+	})
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(boundTools))
+	for _, info := range boundTools {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"greet", FinalAnswerToolName}, names)
+	assert.NotNil(t, boundTools[1].ParamsOneOf)
+
+	weather, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Beijing")})
+	assert.NoError(t, err)
+	assert.Equal(t, &typedWeather{City: "Beijing", Temperature: 25.5}, weather)
+	assert.Equal(t, 3, times)
+	// the tools run only once across the retry
+	assert.Equal(t, 1, fakeTool.curCount)
+}
+
+func TestTypedAgentInvalidFinalAnswer(t *testing.T) {
+	ctx := context.Background()
+
+	fakeTool := &fakeToolGreetForTest{tarCount: 100}
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	times := 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			times++
+			switch times {
+			case 1:
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "1", Function: schema.FunctionCall{Name: "greet", Arguments: `{"name": "max"}`}},
+				}), nil
+			case 2:
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "2", Function: schema.FunctionCall{Name: FinalAnswerToolName, Arguments: `"sunny"`}},
+				}), nil
+			default:
+				// the invalid final answer tool call is answered by the feedback
+				if assert.Len(t, input, 5) {
+					assert.Equal(t, schema.Tool, input[4].Role)
+					assert.Equal(t, "2", input[4].ToolCallID)
+					assert.Contains(t, input[4].Content, "Failed to get the final answer")
+				}
+				return schema.AssistantMessage("", []schema.ToolCall{
+					{ID: "3", Function: schema.FunctionCall{Name: FinalAnswerToolName, Arguments: `{"city": "Beijing", "temperature": 20}`}},
+				}), nil
+			}
+		}).Times(3)
+
+	a, err := NewTypedAgent[typedWeather](ctx, &TypedAgentConfig{
+		AgentConfig: AgentConfig{
+			Model:       cm,
+			ToolsConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{fakeTool}},
+		},
+	})
+	assert.NoError(t, err)
+
+	weather, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Beijing")})
+	assert.NoError(t, err)
+	assert.Equal(t, typedWeather{City: "Beijing", Temperature: 20}, weather)
+	assert.Equal(t, 1, fakeTool.curCount)
+}
+
+func TestTypedAgentJSON(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	cm := mockModel.NewMockChatModel(ctrl)
+	cm.EXPECT().BindTools(gomock.Any()).Return(nil).AnyTimes()
+
+	answers := []string{"not json", "still not json"}
+	times := 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			times++
+			return schema.AssistantMessage(answers[times-1], nil), nil
+		}).Times(2)
+
+	a, err := NewTypedAgent[typedWeather](ctx, &TypedAgentConfig{
+		AgentConfig: AgentConfig{Model: cm},
+		Mode:        StructuredOutputJSON,
+	})
+	assert.NoError(t, err)
+
+	_, err = a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Beijing")})
+	assert.ErrorContains(t, err, "failed to parse final answer")
+
+	answers = []string{"", `{"city": "Beijing", "temperature": 20}`}
+	times = 0
+	cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+			times++
+			return schema.AssistantMessage(answers[times-1], nil), nil
+		}).Times(2)
+	weather, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("what's the weather in Beijing")})
+	assert.NoError(t, err)
+	assert.Equal(t, typedWeather{City: "Beijing", Temperature: 20}, weather)
+
+	_, err = NewTypedAgent[typedWeather](ctx, &TypedAgentConfig{AgentConfig: AgentConfig{Model: cm}, Mode: "xml"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflection
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/flow/agent"
+	template "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// ReflectionCallback is the callback interface for reflection agent.
+type ReflectionCallback interface {
+	// OnRound is called when a draft is critiqued.
+	OnRound(ctx context.Context, round *Round) context.Context
+}
+
+// ConvertCallbackHandlers converts []reflection.ReflectionCallback to callbacks.Handler.
+func ConvertCallbackHandlers(handlers ...ReflectionCallback) callbacks.Handler {
+	onEnd := func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+		if info == nil || info.Name != CriticNodeName {
+			return ctx
+		}
+
+		round, ok := output.(*Round)
+		if !ok || round == nil {
+			return ctx
+		}
+		for _, cb := range handlers {
+			ctx = cb.OnRound(ctx, round)
+		}
+		return ctx
+	}
+
+	return template.NewHandlerHelper().Lambda(callbacks.NewHandlerBuilder().OnEndFn(onEnd).Build()).Handler()
+}
+
+// convertCallbacks reads graph call options, extract reflection.ReflectionCallback and convert it to callbacks.Handler.
+func convertCallbacks(opts ...agent.AgentOption) callbacks.Handler {
+	agentOptions := agent.GetImplSpecificOptions(&options{}, opts...)
+	if len(agentOptions.agentCallbacks) == 0 {
+		return nil
+	}
+
+	return ConvertCallbackHandlers(agentOptions.agentCallbacks...)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DefaultCriticPrompt is the default system prompt of the critic created by NewModelCritic.
+const DefaultCriticPrompt = `You are a strict reviewer. Critique the answer to the request, checking its correctness, completeness and clarity.
+Score the answer from 0 to 10, accept it only if it needs no further revision, and give specific, actionable feedback otherwise.
+Respond with a JSON object only, in the format {"accepted": false, "score": 6, "feedback": "the issues and how to fix them"}.`
+
+// ModelCriticConfig is the config for NewModelCritic.
+type ModelCriticConfig struct {
+	// Model critiques the draft, in the format of Critique in JSON.
+	// Required.
+	Model model.ChatModel
+	// Prompt is the system prompt of the critic.
+	// Optional. Default is DefaultCriticPrompt.
+	Prompt string
+	// AcceptScore is the min score for a draft to be accepted, regardless of the accepted field returned by Model.
+	// Optional. By default, only the accepted field is used.
+	AcceptScore float64
+}
+
+// NewModelCritic creates a Critic with a chat model, which is given the request and the draft,
+// and returns the critique in JSON.
+func NewModelCritic(_ context.Context, config *ModelCriticConfig) (Critic, error) {
+	if config == nil || config.Model == nil {
+		return nil, errors.New("model is required")
+	}
+
+	prompt := config.Prompt
+	if prompt == "" {
+		prompt = DefaultCriticPrompt
+	}
+	parser := schema.NewMessageJSONParser[*Critique](nil)
+
+	return func(ctx context.Context, input []*schema.Message, draft *schema.Message) (*Critique, error) {
+		var sb strings.Builder
+		sb.WriteString("The request:\n")
+		for _, msg := range input {
+			if msg == nil || msg.Role == schema.System {
+				continue
+			}
+			fmt.Fprintf(&sb, "%s: %s\n", msg.Role, msg.Content)
+		}
+		sb.WriteString("\nThe answer:\n")
+		sb.WriteString(draft.Content)
+
+		output, err := config.Model.Generate(ctx, []*schema.Message{
+			schema.SystemMessage(prompt),
+			schema.UserMessage(sb.String()),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := parser.Parse(ctx, &schema.Message{Role: output.Role, Content: utils.RepairJSON(output.Content)})
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return nil, errors.New("empty critique")
+		}
+		if config.AcceptScore > 0 && c.Score >= config.AcceptScore {
+			c.Accepted = true
+		}
+		return c, nil
+	}, nil
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflection
+
+import (
+	"github.com/cloudwego/eino/flow/agent"
+)
+
+type options struct {
+	agentCallbacks   []ReflectionCallback
+	generatorOptions []agent.AgentOption
+}
+
+// WithAgentCallbacks sets the callbacks to be notified of each round.
+func WithAgentCallbacks(agentCallbacks ...ReflectionCallback) agent.AgentOption {
+	return agent.WrapImplSpecificOptFn(func(opts *options) {
+		opts.agentCallbacks = append(opts.agentCallbacks, agentCallbacks...)
+	})
+}
+
+// WithGeneratorOptions sets the options passed to Generator on each round.
+func WithGeneratorOptions(opts ...agent.AgentOption) agent.AgentOption {
+	return agent.WrapImplSpecificOptFn(func(o *options) {
+		o.generatorOptions = append(o.generatorOptions, opts...)
+	})
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reflection implements a reflection agent, which generates a draft, critiques it, and revises it
+// according to the feedback, until the critic accepts it or the max number of rounds is reached.
+package reflection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	GraphName         = "ReflectionAgent"
+	GeneratorNodeName = "Generator"
+	CriticNodeName    = "Critic"
+	ReviseNodeName    = "Revise"
+	FinishNodeName    = "Finish"
+)
+
+const (
+	nodeKeyGenerator = "generator"
+	nodeKeyCritic    = "critic"
+	nodeKeyRevise    = "revise"
+	nodeKeyFinish    = "finish"
+
+	defaultMaxRounds = 3
+)
+
+// Generator generates the drafts, e.g. a *react.Agent.
+// On revision, it's given the input messages, followed by each draft as an assistant message and its feedback as a user message.
+type Generator interface {
+	Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error)
+}
+
+// Critique is the structured feedback of the critic on a draft.
+type Critique struct {
+	// Accepted is true if the draft is good enough to be the final answer.
+	Accepted bool `json:"accepted"`
+	// Score is the score of the draft, its range is defined by the critic.
+	Score float64 `json:"score"`
+	// Feedback is the issues of the draft, and the suggestions to revise it.
+	Feedback string `json:"feedback"`
+}
+
+// Critic critiques the draft generated for the input messages, e.g. by a chat model with NewModelCritic, or by rules.
+type Critic func(ctx context.Context, input []*schema.Message, draft *schema.Message) (*Critique, error)
+
+// Round is a round of generation and critique.
+type Round struct {
+	// Index is the index of the round, starting from 0.
+	Index    int
+	Draft    *schema.Message
+	Critique *Critique
+}
+
+// State is the local state of the agent's graph, keeping the draft history.
+type State struct {
+	// Input is the input messages of the agent.
+	Input []*schema.Message
+	// Rounds are the rounds so far, the last of which is the current one.
+	Rounds []*Round
+}
+
+func init() {
+	_ = compose.RegisterSerializableType[State]("_eino_reflection_state")
+	_ = compose.RegisterSerializableType[Round]("_eino_reflection_round")
+	_ = compose.RegisterSerializableType[Critique]("_eino_reflection_critique")
+}
+
+// Config is the config for reflection agent.
+type Config struct {
+	// Generator generates the drafts.
+	// Required.
+	Generator Generator
+	// Critic critiques each draft.
+	// Required.
+	Critic Critic
+	// MaxRounds is the max number of drafts generated. If no draft is accepted, the last one is returned.
+	// Optional. Default is 3.
+	MaxRounds int
+	// ReviseMessage builds the message asking the generator to revise the draft according to the critique.
+	// Optional. By default, a user message with the feedback is used.
+	ReviseMessage func(ctx context.Context, critique *Critique) *schema.Message
+}
+
+// Agent is the reflection agent.
+// e.g.
+//
+//	critic, err := reflection.NewModelCritic(ctx, &reflection.ModelCriticConfig{Model: criticModel})
+//	if err != nil {...}
+//	a, err := reflection.NewAgent(ctx, &reflection.Config{Generator: reactAgent, Critic: critic})
+//	if err != nil {...}
+//	msg, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("write a poem about golang")})
+type Agent struct {
+	runnable         compose.Runnable[[]*schema.Message, *schema.Message]
+	graph            *compose.Graph[[]*schema.Message, *schema.Message]
+	graphAddNodeOpts []compose.GraphAddNodeOpt
+}
+
+// NewAgent creates a reflection agent.
+func NewAgent(ctx context.Context, config *Config) (*Agent, error) {
+	if config == nil || config.Generator == nil {
+		return nil, errors.New("generator is required")
+	}
+	if config.Critic == nil {
+		return nil, errors.New("critic is required")
+	}
+
+	var (
+		generator     = config.Generator
+		critic        = config.Critic
+		maxRounds     = config.MaxRounds
+		reviseMessage = config.ReviseMessage
+	)
+	if maxRounds <= 0 {
+		maxRounds = defaultMaxRounds
+	}
+	if reviseMessage == nil {
+		reviseMessage = defaultReviseMessage
+	}
+
+	graph := compose.NewGraph[[]*schema.Message, *schema.Message](compose.WithGenLocalState(func(ctx context.Context) *State {
+		return &State{}
+	}))
+
+	generatorPreHandle := func(_ context.Context, input []*schema.Message, state *State) ([]*schema.Message, error) {
+		if len(state.Rounds) == 0 {
+			state.Input = input
+		}
+		return input, nil
+	}
+	generate := func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+		draft, err := generator.Generate(ctx, input, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate draft: %w", err)
+		}
+		return draft, nil
+	}
+
+	critique := func(ctx context.Context, draft *schema.Message) (*Round, error) {
+		var input []*schema.Message
+		var index int
+		err := compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			input, index = state.Input, len(state.Rounds)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := critic(ctx, input, draft)
+		if err != nil {
+			return nil, fmt.Errorf("failed to critique draft: %w", err)
+		}
+		if c == nil {
+			return nil, errors.New("critic returned nil critique")
+		}
+
+		round := &Round{Index: index, Draft: draft, Critique: c}
+		err = compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			state.Rounds = append(state.Rounds, round)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return round, nil
+	}
+
+	revise := func(ctx context.Context, _ *Round) ([]*schema.Message, error) {
+		var input []*schema.Message
+		err := compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+			input = make([]*schema.Message, 0, len(state.Input)+2*len(state.Rounds))
+			input = append(input, state.Input...)
+			for _, round := range state.Rounds {
+				input = append(input, round.Draft, reviseMessage(ctx, round.Critique))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return input, nil
+	}
+
+	finish := func(_ context.Context, round *Round) (*schema.Message, error) {
+		return round.Draft, nil
+	}
+
+	if err := graph.AddLambdaNode(nodeKeyGenerator, compose.InvokableLambdaWithOption(generate),
+		compose.WithStatePreHandler(generatorPreHandle), compose.WithNodeName(GeneratorNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyCritic, compose.InvokableLambda(critique), compose.WithNodeName(CriticNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyRevise, compose.InvokableLambda(revise), compose.WithNodeName(ReviseNodeName)); err != nil {
+		return nil, err
+	}
+	if err := graph.AddLambdaNode(nodeKeyFinish, compose.InvokableLambda(finish), compose.WithNodeName(FinishNodeName)); err != nil {
+		return nil, err
+	}
+
+	if err := graph.AddEdge(compose.START, nodeKeyGenerator); err != nil {
+		return nil, err
+	}
+	if err := graph.AddEdge(nodeKeyGenerator, nodeKeyCritic); err != nil {
+		return nil, err
+	}
+
+	criticBranch := func(_ context.Context, round *Round) (string, error) {
+		if round.Critique.Accepted || round.Index+1 >= maxRounds {
+			return nodeKeyFinish, nil
+		}
+		return nodeKeyRevise, nil
+	}
+	if err := graph.AddBranch(nodeKeyCritic, compose.NewGraphBranch(criticBranch,
+		map[string]bool{nodeKeyFinish: true, nodeKeyRevise: true})); err != nil {
+		return nil, err
+	}
+	if err := graph.AddEdge(nodeKeyRevise, nodeKeyGenerator); err != nil {
+		return nil, err
+	}
+	if err := graph.AddEdge(nodeKeyFinish, compose.END); err != nil {
+		return nil, err
+	}
+
+	compileOpts := []compose.GraphCompileOption{
+		// (generator, critic) * maxRounds, revise * (maxRounds - 1), and finish
+		compose.WithMaxRunSteps(3 * maxRounds),
+		compose.WithNodeTriggerMode(compose.AnyPredecessor),
+		compose.WithGraphName(GraphName),
+	}
+	runnable, err := graph.Compile(ctx, compileOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{
+		runnable:         runnable,
+		graph:            graph,
+		graphAddNodeOpts: []compose.GraphAddNodeOpt{compose.WithGraphCompileOptions(compileOpts...)},
+	}, nil
+}
+
+func defaultReviseMessage(_ context.Context, critique *Critique) *schema.Message {
+	return schema.UserMessage("Please revise your answer according to the feedback below, " +
+		"and answer with the revised answer only.\n\nFeedback:\n" + critique.Feedback)
+}
+
+// Generate generates a response from the agent, which is the accepted draft, or the last one if none is accepted.
+func (a *Agent) Generate(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	return a.runnable.Invoke(ctx, input, a.composeOptions(opts...)...)
+}
+
+// Stream calls the agent and returns a stream response.
+// As every draft has to be complete to be critiqued, the final answer is streamed after it's accepted.
+func (a *Agent) Stream(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+	return a.runnable.Stream(ctx, input, a.composeOptions(opts...)...)
+}
+
+// ExportGraph exports the underlying graph from Agent, along with the []compose.GraphAddNodeOpt to be used when adding this graph to another graph.
+func (a *Agent) ExportGraph() (compose.AnyGraph, []compose.GraphAddNodeOpt) {
+	return a.graph, a.graphAddNodeOpts
+}
+
+func (a *Agent) composeOptions(opts ...agent.AgentOption) []compose.Option {
+	composeOpts := agent.GetComposeOptions(opts...)
+
+	o := agent.GetImplSpecificOptions(&options{}, opts...)
+	if len(o.generatorOptions) > 0 {
+		lambdaOpts := make([]any, len(o.generatorOptions))
+		for i := range o.generatorOptions {
+			lambdaOpts[i] = o.generatorOptions[i]
+		}
+		composeOpts = append(composeOpts, compose.WithLambdaOption(lambdaOpts...).DesignateNode(nodeKeyGenerator))
+	}
+
+	if handler := convertCallbacks(opts...); handler != nil {
+		composeOpts = append(composeOpts, compose.WithCallbacks(handler))
+	}
+
+	return composeOpts
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflection
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+)
+
+// draftModel generates "draft N", N being the number of the drafts in the input.
+type draftModel struct {
+	inputs [][]*schema.Message
+}
+
+func (d *draftModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	d.inputs = append(d.inputs, input)
+	n := 0
+	for _, msg := range input {
+		if msg.Role == schema.Assistant {
+			n++
+		}
+	}
+	return schema.AssistantMessage(fmt.Sprintf("draft %d", n), nil), nil
+}
+
+func (d *draftModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := d.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (d *draftModel) BindTools(_ []*schema.ToolInfo) error {
+	return nil
+}
+
+type modelGenerator struct {
+	model *draftModel
+}
+
+func (m *modelGenerator) Generate(ctx context.Context, input []*schema.Message, _ ...agent.AgentOption) (*schema.Message, error) {
+	return m.model.Generate(ctx, input)
+}
+
+// acceptDraft accepts the draft with the given content.
+func acceptDraft(content string) Critic {
+	return func(_ context.Context, _ []*schema.Message, draft *schema.Message) (*Critique, error) {
+		if draft.Content == content {
+			return &Critique{Accepted: true, Score: 10}, nil
+		}
+		return &Critique{Score: 1, Feedback: "not " + content}, nil
+	}
+}
+
+type roundRecorder struct {
+	rounds []*Round
+}
+
+func (r *roundRecorder) OnRound(ctx context.Context, round *Round) context.Context {
+	r.rounds = append(r.rounds, round)
+	return ctx
+}
+
+func TestReflectionAgent(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("write a poem")}
+
+	_, err := NewAgent(ctx, &Config{Critic: acceptDraft("")})
+	assert.Error(t, err)
+	_, err = NewAgent(ctx, &Config{Generator: &modelGenerator{}})
+	assert.Error(t, err)
+
+	t.Run("react generator", func(t *testing.T) {
+		cm := &draftModel{}
+		generator, err := react.NewAgent(ctx, &react.AgentConfig{Model: cm})
+		assert.NoError(t, err)
+
+		a, err := NewAgent(ctx, &Config{Generator: generator, Critic: acceptDraft("draft 2")})
+		assert.NoError(t, err)
+
+		recorder := &roundRecorder{}
+		output, err := a.Generate(ctx, input, WithAgentCallbacks(recorder))
+		assert.NoError(t, err)
+		assert.Equal(t, "draft 2", output.Content)
+
+		assert.Len(t, recorder.rounds, 3)
+		for i, round := range recorder.rounds {
+			assert.Equal(t, i, round.Index)
+			assert.Equal(t, fmt.Sprintf("draft %d", i), round.Draft.Content)
+		}
+		assert.True(t, recorder.rounds[2].Critique.Accepted)
+
+		assert.Len(t, cm.inputs, 3)
+		revised := cm.inputs[2]
+		assert.Len(t, revised, 5)
+		assert.Equal(t, input[0], revised[0])
+		assert.Equal(t, "draft 0", revised[1].Content)
+		assert.True(t, strings.HasSuffix(revised[2].Content, "Feedback:\nnot draft 2"))
+		assert.Equal(t, "draft 1", revised[3].Content)
+	})
+
+	t.Run("max rounds", func(t *testing.T) {
+		a, err := NewAgent(ctx, &Config{
+			Generator: &modelGenerator{model: &draftModel{}},
+			Critic:    acceptDraft("never"),
+			MaxRounds: 2,
+		})
+		assert.NoError(t, err)
+
+		sr, err := a.Stream(ctx, input)
+		assert.NoError(t, err)
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			chunks = append(chunks, chunk)
+		}
+		output, err := schema.ConcatMessages(chunks)
+		assert.NoError(t, err)
+		assert.Equal(t, "draft 1", output.Content)
+	})
+}
+
+type criticModel struct {
+	output string
+	input  []*schema.Message
+}
+
+func (c *criticModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	c.input = input
+	return schema.AssistantMessage(c.output, nil), nil
+}
+
+func (c *criticModel) Stream(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, fmt.Errorf("unexpected")
+}
+
+func (c *criticModel) BindTools(_ []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestModelCritic(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewModelCritic(ctx, &ModelCriticConfig{})
+	assert.Error(t, err)
+
+	cm := &criticModel{output: "```json\n{\"accepted\": false, \"score\": 8, \"feedback\": \"too short\"}\n```"}
+	critic, err := NewModelCritic(ctx, &ModelCriticConfig{Model: cm})
+	assert.NoError(t, err)
+
+	c, err := critic(ctx, []*schema.Message{schema.SystemMessage("be nice"), schema.UserMessage("write a poem")},
+		schema.AssistantMessage("roses are red", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, &Critique{Score: 8, Feedback: "too short"}, c)
+	assert.Equal(t, DefaultCriticPrompt, cm.input[0].Content)
+	assert.Equal(t, "The request:\nuser: write a poem\n\nThe answer:\nroses are red", cm.input[1].Content)
+
+	critic, err = NewModelCritic(ctx, &ModelCriticConfig{Model: cm, AcceptScore: 8})
+	assert.NoError(t, err)
+	c, err = critic(ctx, nil, schema.AssistantMessage("roses are red", nil))
+	assert.NoError(t, err)
+	assert.True(t, c.Accepted)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/cloudwego/eino/flow/agent/internal/forward"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ReadAheadStreamToolCallChecker is the default StreamToolCallChecker of the agents,
+// which reads ahead the streaming output of the chat model until a chunk with tool calls or the end of the stream.
+// It works with both the models outputting tool calls in the first chunk (e.g. OpenAI)
+// and the models outputting text before tool calls (e.g. Claude).
+//
+// When the agent is called by Stream, the text chunks read ahead are forwarded to the caller as they arrive,
+// without waiting for the checker to decide, so the text before the tool calls is streamed to the caller too.
+// When the agent is exported as a graph, the branch after the chat model waits for the checker as usual,
+// so the final answer is streamed once the chat model finishes.
+//
+// If there are tool calls, the whole output, concatenated into one message, is passed to the tools node.
+// It returns as soon as the first tool call chunk arrives, and closes the stream before returning.
+func ReadAheadStreamToolCallChecker(ctx context.Context, sr *schema.StreamReader[*schema.Message]) (bool, error) {
+	defer sr.Close()
+
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if msg == nil {
+			continue
+		}
+
+		if len(msg.ToolCalls) > 0 {
+			return true, nil
+		}
+		forward.Chunk(ctx, msg)
+	}
+}
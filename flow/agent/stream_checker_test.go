@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestReadAheadStreamToolCallChecker(t *testing.T) {
+	ctx := context.Background()
+	toolCall := schema.ToolCall{ID: "1", Function: schema.FunctionCall{Name: "search"}}
+
+	t.Run("tool call first", func(t *testing.T) {
+		sr := schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("", []schema.ToolCall{toolCall}),
+		})
+		isToolCall, err := ReadAheadStreamToolCallChecker(ctx, sr)
+		assert.NoError(t, err)
+		assert.True(t, isToolCall)
+	})
+
+	t.Run("text then tool call", func(t *testing.T) {
+		sr := schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("", nil),
+			schema.AssistantMessage("let me ", nil),
+			schema.AssistantMessage("search", nil),
+			schema.AssistantMessage("", []schema.ToolCall{toolCall}),
+		})
+		isToolCall, err := ReadAheadStreamToolCallChecker(ctx, sr)
+		assert.NoError(t, err)
+		assert.True(t, isToolCall)
+	})
+
+	t.Run("text only", func(t *testing.T) {
+		sr := schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("hello ", nil),
+			schema.AssistantMessage("world", nil),
+		})
+		isToolCall, err := ReadAheadStreamToolCallChecker(ctx, sr)
+		assert.NoError(t, err)
+		assert.False(t, isToolCall)
+	})
+
+	t.Run("returns at the first tool call", func(t *testing.T) {
+		sr, sw := schema.Pipe[*schema.Message](3)
+		sw.Send(schema.AssistantMessage("thinking", nil), nil)
+		sw.Send(schema.AssistantMessage("", []schema.ToolCall{toolCall}), nil)
+
+		// the writer is not closed, the checker must not wait for the end of the stream
+		isToolCall, err := ReadAheadStreamToolCallChecker(ctx, sr)
+		assert.NoError(t, err)
+		assert.True(t, isToolCall)
+		sw.Close()
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sr, sw := schema.Pipe[*schema.Message](2)
+		sw.Send(schema.AssistantMessage("hello", nil), nil)
+		sw.Send(nil, errors.New("broken"))
+		sw.Close()
+
+		_, err := ReadAheadStreamToolCallChecker(ctx, sr)
+		assert.EqualError(t, err, "broken")
+	})
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package toolselect provides tool selectors which pick the tools relevant to the conversation from a large toolset,
+// e.g. to be used as react.AgentConfig.ToolSelector.
+package toolselect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+const defaultTopK = 5
+
+// EmbeddingSelectorConfig is the config for NewEmbeddingSelector.
+type EmbeddingSelectorConfig struct {
+	// Embedder embeds the descriptions of the tools and the query built from the conversation.
+	Embedder embedding.Embedder
+	// TopK is the max number of tools selected.
+	// Optional. Default is 5.
+	TopK int
+	// MinScore is the min cosine similarity of the selected tools.
+	// Optional. By default, the top k tools are selected regardless of the score.
+	MinScore float64
+	// QueryBuilder builds the text to search the tools with from the conversation.
+	// Optional. By default, the content of the last user message is used, followed by the messages after it.
+	QueryBuilder func(ctx context.Context, input []*schema.Message) (string, error)
+	// ToolText builds the text to be embedded for each tool.
+	// Optional. By default, the name and the description of the tool are used.
+	ToolText func(tool *schema.ToolInfo) string
+}
+
+// EmbeddingSelector selects the tools whose descriptions are most similar to the conversation.
+// The embeddings of the tools are computed on first use and cached, until the text of the tool changes.
+type EmbeddingSelector struct {
+	embedder     embedding.Embedder
+	topK         int
+	minScore     float64
+	queryBuilder func(ctx context.Context, input []*schema.Message) (string, error)
+	toolText     func(tool *schema.ToolInfo) string
+
+	mu    sync.Mutex
+	index map[string]*toolVector
+}
+
+type toolVector struct {
+	text   string
+	vector []float64
+}
+
+// NewEmbeddingSelector creates an EmbeddingSelector, use its Select method as react.AgentConfig.ToolSelector.
+func NewEmbeddingSelector(_ context.Context, config *EmbeddingSelectorConfig) (*EmbeddingSelector, error) {
+	if config == nil || config.Embedder == nil {
+		return nil, errors.New("embedder is required")
+	}
+
+	s := &EmbeddingSelector{
+		embedder:     config.Embedder,
+		topK:         config.TopK,
+		minScore:     config.MinScore,
+		queryBuilder: config.QueryBuilder,
+		toolText:     config.ToolText,
+		index:        make(map[string]*toolVector),
+	}
+	if s.topK <= 0 {
+		s.topK = defaultTopK
+	}
+	if s.queryBuilder == nil {
+		s.queryBuilder = defaultQueryBuilder
+	}
+	if s.toolText == nil {
+		s.toolText = defaultToolText
+	}
+
+	return s, nil
+}
+
+// Index embeds the tools ahead of time, it's optional as Select indexes the tools not indexed yet.
+func (s *EmbeddingSelector) Index(ctx context.Context, tools []*schema.ToolInfo) error {
+	_, err := s.getVectors(ctx, tools)
+	return err
+}
+
+// Select returns at most TopK tools most relevant to the input messages, ordered by relevance.
+func (s *EmbeddingSelector) Select(ctx context.Context, tools []*schema.ToolInfo, input []*schema.Message) ([]*schema.ToolInfo, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+
+	query, err := s.queryBuilder(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	if len(strings.TrimSpace(query)) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := s.getVectors(ctx, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors, err := s.embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(queryVectors) != 1 {
+		return nil, fmt.Errorf("invalid query embedding result, expected 1 vector, got %d", len(queryVectors))
+	}
+
+	type scored struct {
+		tool  *schema.ToolInfo
+		score float64
+	}
+	candidates := make([]scored, 0, len(tools))
+	for i, t := range tools {
+		score := cosineSimilarity(queryVectors[0], vectors[i])
+		if score < s.minScore {
+			continue
+		}
+		candidates = append(candidates, scored{tool: t, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > s.topK {
+		candidates = candidates[:s.topK]
+	}
+
+	ret := make([]*schema.ToolInfo, len(candidates))
+	for i := range candidates {
+		ret[i] = candidates[i].tool
+	}
+
+	return ret, nil
+}
+
+// getVectors returns the embeddings of the tools in the same order, embedding the ones not cached in one batch.
+func (s *EmbeddingSelector) getVectors(ctx context.Context, tools []*schema.ToolInfo) ([][]float64, error) {
+	texts := make([]string, len(tools))
+	vectors := make([][]float64, len(tools))
+
+	var missing []int
+	s.mu.Lock()
+	for i, t := range tools {
+		texts[i] = s.toolText(t)
+		if v, ok := s.index[t.Name]; ok && v.text == texts[i] {
+			vectors[i] = v.vector
+			continue
+		}
+		missing = append(missing, i)
+	}
+	s.mu.Unlock()
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	missingTexts := make([]string, len(missing))
+	for i, idx := range missing {
+		missingTexts[i] = texts[idx]
+	}
+
+	embedded, err := s.embedder.EmbedStrings(ctx, missingTexts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed tools: %w", err)
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("invalid tool embedding result, expected %d vectors, got %d", len(missing), len(embedded))
+	}
+
+	s.mu.Lock()
+	for i, idx := range missing {
+		vectors[idx] = embedded[i]
+		s.index[tools[idx].Name] = &toolVector{text: texts[idx], vector: embedded[i]}
+	}
+	s.mu.Unlock()
+
+	return vectors, nil
+}
+
+func defaultToolText(tool *schema.ToolInfo) string {
+	return tool.Name + ": " + tool.Desc
+}
+
+func defaultQueryBuilder(_ context.Context, input []*schema.Message) (string, error) {
+	start := 0
+	for i := len(input) - 1; i >= 0; i-- {
+		if input[i] != nil && input[i].Role == schema.User {
+			start = i
+			break
+		}
+	}
+
+	sb := strings.Builder{}
+	for _, msg := range input[start:] {
+		if msg == nil || msg.Role == schema.System || len(msg.Content) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(msg.Content)
+	}
+
+	return sb.String(), nil
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package toolselect
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+var keywords = []string{"weather", "stock", "email", "calendar"}
+
+// keywordEmbedder embeds the texts by the occurrences of keywords.
+type keywordEmbedder struct {
+	calls int
+	texts int
+}
+
+func (k *keywordEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	k.calls++
+	k.texts += len(texts)
+
+	ret := make([][]float64, len(texts))
+	for i, text := range texts {
+		ret[i] = make([]float64, len(keywords))
+		for j, kw := range keywords {
+			ret[i][j] = float64(strings.Count(strings.ToLower(text), kw))
+		}
+	}
+	return ret, nil
+}
+
+func TestEmbeddingSelector(t *testing.T) {
+	ctx := context.Background()
+
+	tools := []*schema.ToolInfo{
+		{Name: "get_weather", Desc: "get the weather forecast of a city"},
+		{Name: "get_stock", Desc: "get the stock price"},
+		{Name: "send_email", Desc: "send an email"},
+		{Name: "add_event", Desc: "add an event to the calendar, and notify by email"},
+	}
+
+	emb := &keywordEmbedder{}
+	s, err := NewEmbeddingSelector(ctx, &EmbeddingSelectorConfig{Embedder: emb, TopK: 2, MinScore: 0.1})
+	assert.NoError(t, err)
+
+	selected, err := s.Select(ctx, tools, []*schema.Message{
+		schema.SystemMessage("you are a helpful assistant about weather"),
+		schema.UserMessage("what's the weather today?"),
+		schema.AssistantMessage("sunny", nil),
+		schema.UserMessage("email the report to bob"),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, selected, 2) {
+		assert.Equal(t, "send_email", selected[0].Name)
+		assert.Equal(t, "add_event", selected[1].Name)
+	}
+	assert.Equal(t, 2, emb.calls)
+	assert.Equal(t, 5, emb.texts)
+
+	// the tools are embedded only once, unless changed
+	tools[1] = &schema.ToolInfo{Name: "get_stock", Desc: "get the stock price and the weather of the exchange"}
+	selected, err = s.Select(ctx, tools, []*schema.Message{schema.UserMessage("weather of the stock exchange")})
+	assert.NoError(t, err)
+	assert.Equal(t, "get_stock", selected[0].Name)
+	assert.Equal(t, 4, emb.calls)
+	assert.Equal(t, 7, emb.texts)
+
+	selected, err = s.Select(ctx, tools, []*schema.Message{schema.UserMessage("hello")})
+	assert.NoError(t, err)
+	assert.Len(t, selected, 0)
+
+	_, err = NewEmbeddingSelector(ctx, &EmbeddingSelectorConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,344 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package window
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent/memory"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DefaultSummaryPrompt is the default system prompt of the summarization.
+const DefaultSummaryPrompt = `You are summarizing the earlier part of a conversation between a user and an AI assistant, ` +
+	`so that the assistant can continue the conversation without it.
+Keep the facts, decisions, open questions, and the results of the tool calls which are still relevant. ` +
+	`Be concise, and answer with the summary only.`
+
+const (
+	defaultSummaryCacheSize = 1024
+	summaryPrefix           = "Summary of the earlier conversation:\n"
+)
+
+// SummarizerConfig is the config for NewSummarizer.
+type SummarizerConfig struct {
+	// Model summarizes the oldest messages.
+	// Required.
+	Model model.ChatModel
+	// MaxTokens is the number of tokens of the conversation above which the oldest messages are summarized.
+	// Required.
+	MaxTokens int
+	// KeepTokens is the max number of tokens of the latest messages kept as is.
+	// Optional. Default is MaxTokens / 2.
+	KeepTokens int
+	// Tokenizer counts the tokens of the messages.
+	// Optional. Default is NewHeuristicTokenizer().
+	Tokenizer Tokenizer
+	// Prompt is the system prompt of the summarization.
+	// Optional. Default is DefaultSummaryPrompt.
+	Prompt string
+	// SummaryMessage builds the message replacing the summarized messages.
+	// Optional. By default, a system message with the summary is used.
+	SummaryMessage func(summary string) *schema.Message
+	// SessionID returns the session the summaries are cached for.
+	// Optional. By default, memory.SessionIDFromContext is used, sharing one cache for the runs without a session.
+	SessionID func(ctx context.Context) string
+	// CacheSize is the max number of sessions whose summaries are cached, the least recently used ones are evicted.
+	// Optional. Default is 1024.
+	CacheSize int
+}
+
+// Summarizer compresses the conversation when it exceeds MaxTokens, by replacing the oldest messages with a summary.
+//
+// The leading system messages and the latest messages within KeepTokens are kept as is, and the messages between them
+// are summarized by Model. An assistant message with tool calls and the tool messages following it are always
+// summarized or kept together.
+//
+// The summary is cached per session. As the conversation grows, the cached summary is extended with the newly
+// summarized messages, rather than summarizing all of them again.
+type Summarizer struct {
+	model          model.ChatModel
+	maxTokens      int
+	keepTokens     int
+	tokenizer      Tokenizer
+	prompt         string
+	summaryMessage func(summary string) *schema.Message
+	sessionID      func(ctx context.Context) string
+	cacheSize      int
+
+	mu    sync.Mutex
+	lru   *list.List
+	cache map[string]*list.Element
+}
+
+type summaryEntry struct {
+	sessionID string
+	// count is the number of messages summarized, and digest is the digest of them.
+	count   int
+	digest  string
+	summary string
+}
+
+// NewSummarizer creates a Summarizer.
+func NewSummarizer(_ context.Context, config *SummarizerConfig) (*Summarizer, error) {
+	if config == nil || config.Model == nil {
+		return nil, errors.New("model is required")
+	}
+	if config.MaxTokens <= 0 {
+		return nil, errors.New("max tokens must be positive")
+	}
+
+	s := &Summarizer{
+		model:          config.Model,
+		maxTokens:      config.MaxTokens,
+		keepTokens:     config.KeepTokens,
+		tokenizer:      config.Tokenizer,
+		prompt:         config.Prompt,
+		summaryMessage: config.SummaryMessage,
+		sessionID:      config.SessionID,
+		cacheSize:      config.CacheSize,
+		lru:            list.New(),
+		cache:          make(map[string]*list.Element),
+	}
+
+	if s.keepTokens <= 0 {
+		s.keepTokens = s.maxTokens / 2
+	}
+	if s.tokenizer == nil {
+		s.tokenizer = NewHeuristicTokenizer()
+	}
+	if s.prompt == "" {
+		s.prompt = DefaultSummaryPrompt
+	}
+	if s.summaryMessage == nil {
+		s.summaryMessage = func(summary string) *schema.Message {
+			return schema.SystemMessage(summaryPrefix + summary)
+		}
+	}
+	if s.sessionID == nil {
+		s.sessionID = memory.SessionIDFromContext
+	}
+	if s.cacheSize <= 0 {
+		s.cacheSize = defaultSummaryCacheSize
+	}
+
+	return s, nil
+}
+
+// Summarize returns the messages of input to be sent to the model, with the oldest ones replaced by a summary.
+// input is returned as is if it is within MaxTokens.
+func (s *Summarizer) Summarize(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
+	total, err := s.tokenizer.CountTokens(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if total <= s.maxTokens {
+		return input, nil
+	}
+
+	head := 0
+	for head < len(input) && input[head] != nil && input[head].Role == schema.System {
+		head++
+	}
+
+	blocks := splitBlocks(input[head:])
+	if len(blocks) < 2 {
+		return input, nil
+	}
+
+	// keep the latest blocks within KeepTokens, and at least the latest one
+	tail := len(blocks) - 1
+	budget := s.keepTokens
+	for ; tail >= 0; tail-- {
+		b := blocks[tail]
+		tokens, err := s.tokenizer.CountTokens(ctx, input[head+b.start:head+b.end])
+		if err != nil {
+			return nil, err
+		}
+		if tokens > budget && tail < len(blocks)-1 {
+			break
+		}
+		budget -= tokens
+	}
+	if tail < 0 {
+		return input, nil
+	}
+
+	segment := input[head : head+blocks[tail].end]
+	summary, err := s.summarize(ctx, segment)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*schema.Message, 0, head+1+len(input)-head-len(segment))
+	output = append(output, input[:head]...)
+	output = append(output, s.summaryMessage(summary))
+	output = append(output, input[head+len(segment):]...)
+
+	return output, nil
+}
+
+// MessageModifier returns the Summarizer as a message modifier, e.g. to be used as react.AgentConfig.MessageModifier.
+// The messages are passed through as is if the summarization fails.
+func (s *Summarizer) MessageModifier() func(ctx context.Context, input []*schema.Message) []*schema.Message {
+	return func(ctx context.Context, input []*schema.Message) []*schema.Message {
+		output, err := s.Summarize(ctx, input)
+		if err != nil {
+			return input
+		}
+		return output
+	}
+}
+
+// Lambda returns the Summarizer as a Lambda node, taking and returning []*schema.Message.
+func (s *Summarizer) Lambda(opts ...compose.LambdaOpt) *compose.Lambda {
+	return compose.InvokableLambda(s.Summarize, opts...)
+}
+
+func (s *Summarizer) summarize(ctx context.Context, segment []*schema.Message) (string, error) {
+	sessionID := s.sessionID(ctx)
+
+	h := sha256.New()
+	digests := make([]string, len(segment)+1)
+	digests[0] = hex.EncodeToString(h.Sum(nil))
+	for i, msg := range segment {
+		if err := writeMessage(h, msg); err != nil {
+			return "", err
+		}
+		digests[i+1] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	var (
+		previous string
+		from     int
+	)
+	if entry, ok := s.load(sessionID); ok && entry.count <= len(segment) && digests[entry.count] == entry.digest {
+		if entry.count == len(segment) {
+			return entry.summary, nil
+		}
+		previous, from = entry.summary, entry.count
+	}
+
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("Summary so far:\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\nNew messages:\n")
+	}
+	writeTranscript(&sb, segment[from:])
+
+	resp, err := s.model.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(s.prompt),
+		schema.UserMessage(sb.String()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize messages: %w", err)
+	}
+
+	s.store(&summaryEntry{
+		sessionID: sessionID,
+		count:     len(segment),
+		digest:    digests[len(segment)],
+		summary:   resp.Content,
+	})
+
+	return resp.Content, nil
+}
+
+func (s *Summarizer) load(sessionID string) (summaryEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.cache[sessionID]
+	if !ok {
+		return summaryEntry{}, false
+	}
+	s.lru.MoveToFront(elem)
+	return *elem.Value.(*summaryEntry), true
+}
+
+func (s *Summarizer) store(entry *summaryEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.cache[entry.sessionID]; ok {
+		elem.Value = entry
+		s.lru.MoveToFront(elem)
+		return
+	}
+
+	s.cache[entry.sessionID] = s.lru.PushFront(entry)
+	for s.lru.Len() > s.cacheSize {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.cache, oldest.Value.(*summaryEntry).sessionID)
+	}
+}
+
+func writeMessage(h hash.Hash, msg *schema.Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	_, _ = h.Write(b)
+	_, _ = h.Write([]byte{'\n'})
+	return nil
+}
+
+func writeTranscript(sb *strings.Builder, msgs []*schema.Message) {
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+
+		sb.WriteString(string(msg.Role))
+		if msg.Role == schema.Tool && msg.ToolCallID != "" {
+			sb.WriteString(" (")
+			sb.WriteString(msg.ToolCallID)
+			sb.WriteString(")")
+		}
+		sb.WriteString(": ")
+
+		if len(msg.MultiContent) > 0 {
+			for _, part := range msg.MultiContent {
+				if part.Type == schema.ChatMessagePartTypeText {
+					sb.WriteString(part.Text)
+				} else {
+					sb.WriteString("[" + string(part.Type) + "]")
+				}
+			}
+		} else {
+			sb.WriteString(msg.Content)
+		}
+
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(sb, "\n  call %s (%s): %s", call.Function.Name, call.ID, call.Function.Arguments)
+		}
+		sb.WriteString("\n")
+	}
+}
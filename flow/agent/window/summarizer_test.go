@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type summaryModel struct {
+	inputs []string
+}
+
+func (s *summaryModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	s.inputs = append(s.inputs, input[len(input)-1].Content)
+	return schema.AssistantMessage(fmt.Sprintf("S%d", len(s.inputs)), nil), nil
+}
+
+func (s *summaryModel) Stream(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, fmt.Errorf("unexpected")
+}
+
+func (s *summaryModel) BindTools(_ []*schema.ToolInfo) error {
+	return nil
+}
+
+type sessionKey struct{}
+
+func TestSummarizer(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewSummarizer(ctx, &SummarizerConfig{MaxTokens: 50})
+	assert.Error(t, err)
+
+	cm := &summaryModel{}
+	s, err := NewSummarizer(ctx, &SummarizerConfig{
+		Model:      cm,
+		MaxTokens:  60,
+		KeepTokens: 20,
+		Tokenizer:  perMessageTokenizer{},
+		SessionID: func(ctx context.Context) string {
+			id, _ := ctx.Value(sessionKey{}).(string)
+			return id
+		},
+	})
+	assert.NoError(t, err)
+
+	history := []*schema.Message{
+		schema.SystemMessage("system"),
+		schema.UserMessage("q1"),
+		schema.AssistantMessage("a1", nil),
+		schema.UserMessage("q2"),
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "search", Arguments: "{}"}}}),
+		schema.ToolMessage("r1", "1"),
+	}
+
+	// within MaxTokens
+	output, err := s.Summarize(ctx, history)
+	assert.NoError(t, err)
+	assert.Equal(t, history, output)
+	assert.Len(t, cm.inputs, 0)
+
+	// the tool call and its result are summarized together
+	history = append(history, schema.AssistantMessage("a2", nil))
+	output, err = s.Summarize(ctx, history)
+	assert.NoError(t, err)
+	assert.Equal(t, []*schema.Message{history[0], schema.SystemMessage(summaryPrefix + "S1"), history[6]}, output)
+	assert.Len(t, cm.inputs, 1)
+	assert.Contains(t, cm.inputs[0], "user: q1\nassistant: a1\nuser: q2\nassistant: \n  call search (1): {}\ntool (1): r1\n")
+
+	// the cached summary is reused
+	history = append(history, schema.UserMessage("q3"))
+	output, err = s.Summarize(ctx, history)
+	assert.NoError(t, err)
+	assert.Equal(t, []*schema.Message{history[0], schema.SystemMessage(summaryPrefix + "S1"), history[6], history[7]}, output)
+	assert.Len(t, cm.inputs, 1)
+
+	// the cached summary is extended
+	history = append(history, schema.AssistantMessage("a3", nil))
+	output, err = s.Summarize(ctx, history)
+	assert.NoError(t, err)
+	assert.Equal(t, []*schema.Message{history[0], schema.SystemMessage(summaryPrefix + "S2"), history[7], history[8]}, output)
+	assert.Len(t, cm.inputs, 2)
+	assert.Equal(t, "Summary so far:\nS1\n\nNew messages:\nassistant: a2\n", cm.inputs[1])
+
+	// the summaries are cached per session
+	output = s.MessageModifier()(context.WithValue(ctx, sessionKey{}, "other"), history)
+	assert.Equal(t, schema.SystemMessage(summaryPrefix+"S3"), output[1])
+	assert.Len(t, cm.inputs, 3)
+
+	// the history is changed
+	history[1] = schema.UserMessage("q1 changed")
+	output, err = s.Summarize(ctx, history)
+	assert.NoError(t, err)
+	assert.Equal(t, schema.SystemMessage(summaryPrefix+"S4"), output[1])
+	assert.NotContains(t, cm.inputs[3], "Summary so far")
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package window
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Tokenizer counts the tokens of messages, to keep the conversation within the context window of the model.
+type Tokenizer interface {
+	// CountTokens returns the number of tokens msgs take up when sent to the model,
+	// including the content, the multi content parts and the tool calls of each message.
+	CountTokens(ctx context.Context, msgs []*schema.Message) (int, error)
+}
+
+const (
+	defaultCharsPerToken   = 4
+	defaultMessageOverhead = 4
+	defaultMediaTokens     = 512
+)
+
+// HeuristicTokenizer estimates the number of tokens without a vocabulary of the model.
+// ASCII text is counted as CharsPerToken characters per token, and any other character, e.g. CJK,
+// is counted as one token, which tends to overestimate rather than underestimate.
+type HeuristicTokenizer struct {
+	// CharsPerToken is the number of ASCII characters per token.
+	// Optional. Default is 4.
+	CharsPerToken int
+	// MessageOverhead is the number of tokens added for each message, accounting for the role and the separators.
+	// Optional. Default is 4.
+	MessageOverhead int
+	// MediaTokens is the number of tokens counted for each image, audio, video or file part of MultiContent.
+	// Optional. Default is 512.
+	MediaTokens int
+}
+
+// NewHeuristicTokenizer creates a HeuristicTokenizer with the default settings.
+func NewHeuristicTokenizer() *HeuristicTokenizer {
+	return &HeuristicTokenizer{}
+}
+
+// CountTokens implements Tokenizer.
+func (h *HeuristicTokenizer) CountTokens(_ context.Context, msgs []*schema.Message) (int, error) {
+	total := 0
+	for _, msg := range msgs {
+		total += h.countMessage(msg)
+	}
+	return total, nil
+}
+
+func (h *HeuristicTokenizer) countMessage(msg *schema.Message) int {
+	if msg == nil {
+		return 0
+	}
+
+	overhead := h.MessageOverhead
+	if overhead <= 0 {
+		overhead = defaultMessageOverhead
+	}
+	mediaTokens := h.MediaTokens
+	if mediaTokens <= 0 {
+		mediaTokens = defaultMediaTokens
+	}
+
+	n := overhead + h.countText(msg.Name)
+	if len(msg.MultiContent) > 0 {
+		for _, part := range msg.MultiContent {
+			if part.Type == schema.ChatMessagePartTypeText {
+				n += h.countText(part.Text)
+			} else {
+				n += mediaTokens
+			}
+		}
+	} else {
+		n += h.countText(msg.Content)
+	}
+
+	for _, call := range msg.ToolCalls {
+		n += h.countText(call.ID) + h.countText(call.Function.Name) + h.countText(call.Function.Arguments)
+	}
+	n += h.countText(msg.ToolCallID)
+
+	return n
+}
+
+func (h *HeuristicTokenizer) countText(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+
+	charsPerToken := h.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = defaultCharsPerToken
+	}
+
+	ascii, others := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			others++
+		}
+	}
+
+	return (ascii+charsPerToken-1)/charsPerToken + others
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package window provides token counting, history trimming and summarization, to keep the conversation of an agent
+// within the context window of the model.
+package window
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// TrimmerConfig is the config for NewTrimmer.
+type TrimmerConfig struct {
+	// MaxTokens is the max number of tokens of the trimmed messages.
+	// Required.
+	MaxTokens int
+	// Tokenizer counts the tokens of the messages.
+	// Optional. Default is NewHeuristicTokenizer().
+	Tokenizer Tokenizer
+}
+
+// Trimmer trims the conversation to MaxTokens, dropping the oldest messages first.
+//
+// The messages are trimmed as follows:
+//   - system messages are always kept.
+//   - an assistant message with tool calls and the tool messages following it are kept or dropped together,
+//     so that the model never sees a tool call without its results, or the other way around.
+//   - the oldest turns are dropped first, a turn being a user message and the messages answering it.
+//   - if the latest turn alone exceeds the budget, e.g. in a long ReAct loop, the user message starting it
+//     and its latest messages are kept, dropping the messages in between.
+//   - the latest message, together with the tool calls it belongs to, is always kept, even if it exceeds the budget.
+type Trimmer struct {
+	maxTokens int
+	tokenizer Tokenizer
+}
+
+// NewTrimmer creates a Trimmer.
+func NewTrimmer(_ context.Context, config *TrimmerConfig) (*Trimmer, error) {
+	if config == nil || config.MaxTokens <= 0 {
+		return nil, errors.New("max tokens must be positive")
+	}
+
+	tokenizer := config.Tokenizer
+	if tokenizer == nil {
+		tokenizer = NewHeuristicTokenizer()
+	}
+
+	return &Trimmer{
+		maxTokens: config.MaxTokens,
+		tokenizer: tokenizer,
+	}, nil
+}
+
+// Trim returns the messages of input to be sent to the model, in their original order.
+// input is returned as is if it is within the budget, otherwise the nil messages in it are skipped.
+func (t *Trimmer) Trim(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
+	total, err := t.tokenizer.CountTokens(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if total <= t.maxTokens {
+		return input, nil
+	}
+
+	input = skipNilMessages(input)
+	blocks := splitBlocks(input)
+	for i := range blocks {
+		b := &blocks[i]
+		if b.tokens, err = t.tokenizer.CountTokens(ctx, input[b.start:b.end]); err != nil {
+			return nil, err
+		}
+	}
+
+	keep := make([]bool, len(blocks))
+	budget := t.maxTokens
+
+	var turns [][]int
+	for i, b := range blocks {
+		if b.system {
+			keep[i] = true
+			budget -= b.tokens
+			continue
+		}
+		if len(turns) == 0 || input[b.start].Role == schema.User {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], i)
+	}
+
+	for i := len(turns) - 1; i >= 0; i-- {
+		turn := turns[i]
+		cost := 0
+		for _, idx := range turn {
+			cost += blocks[idx].tokens
+		}
+
+		if cost <= budget {
+			for _, idx := range turn {
+				keep[idx] = true
+			}
+			budget -= cost
+			continue
+		}
+
+		if i == len(turns)-1 {
+			trimLatestTurn(blocks, input, turn, keep, budget)
+		}
+		break
+	}
+
+	output := make([]*schema.Message, 0, len(input))
+	for i, b := range blocks {
+		if keep[i] {
+			output = append(output, input[b.start:b.end]...)
+		}
+	}
+
+	return output, nil
+}
+
+// MessageModifier returns the Trimmer as a message modifier, e.g. to be used as react.AgentConfig.MessageModifier.
+// The messages are passed through untrimmed if the Tokenizer fails.
+func (t *Trimmer) MessageModifier() func(ctx context.Context, input []*schema.Message) []*schema.Message {
+	return func(ctx context.Context, input []*schema.Message) []*schema.Message {
+		output, err := t.Trim(ctx, input)
+		if err != nil {
+			return input
+		}
+		return output
+	}
+}
+
+// Lambda returns the Trimmer as a Lambda node, taking and returning []*schema.Message.
+func (t *Trimmer) Lambda(opts ...compose.LambdaOpt) *compose.Lambda {
+	return compose.InvokableLambda(t.Trim, opts...)
+}
+
+type block struct {
+	start, end int
+	tokens     int
+	system     bool
+}
+
+// skipNilMessages returns input without the nil messages.
+func skipNilMessages(input []*schema.Message) []*schema.Message {
+	ret := make([]*schema.Message, 0, len(input))
+	for _, msg := range input {
+		if msg != nil {
+			ret = append(ret, msg)
+		}
+	}
+	return ret
+}
+
+// splitBlocks splits input into the units which are kept or dropped as a whole.
+func splitBlocks(input []*schema.Message) []block {
+	var blocks []block
+	for i := 0; i < len(input); {
+		msg := input[i]
+		b := block{start: i, end: i + 1, system: msg != nil && msg.Role == schema.System}
+		if msg != nil && msg.Role == schema.Assistant && len(msg.ToolCalls) > 0 {
+			for b.end < len(input) && input[b.end] != nil && input[b.end].Role == schema.Tool {
+				b.end++
+			}
+		}
+		blocks = append(blocks, b)
+		i = b.end
+	}
+	return blocks
+}
+
+// trimLatestTurn keeps the latest block of the turn, then the user message starting it,
+// then as many of the latest blocks in between as the budget allows.
+func trimLatestTurn(blocks []block, input []*schema.Message, turn []int, keep []bool, budget int) {
+	last := turn[len(turn)-1]
+	keep[last] = true
+	budget -= blocks[last].tokens
+
+	first := turn[0]
+	if first != last && input[blocks[first].start].Role == schema.User && blocks[first].tokens <= budget {
+		keep[first] = true
+		budget -= blocks[first].tokens
+		turn = turn[1:]
+	}
+
+	for j := len(turn) - 2; j >= 0; j-- {
+		idx := turn[j]
+		if blocks[idx].tokens > budget {
+			break
+		}
+		keep[idx] = true
+		budget -= blocks[idx].tokens
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package window
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// perMessageTokenizer counts each message as 10 tokens.
+type perMessageTokenizer struct{}
+
+func (perMessageTokenizer) CountTokens(_ context.Context, msgs []*schema.Message) (int, error) {
+	return len(msgs) * 10, nil
+}
+
+func TestHeuristicTokenizer(t *testing.T) {
+	ctx := context.Background()
+	tk := NewHeuristicTokenizer()
+
+	n, err := tk.CountTokens(ctx, []*schema.Message{schema.UserMessage("12345678")})
+	assert.NoError(t, err)
+	assert.Equal(t, 4+2, n)
+
+	n, err = tk.CountTokens(ctx, []*schema.Message{schema.UserMessage("你好")})
+	assert.NoError(t, err)
+	assert.Equal(t, 4+2, n)
+
+	n, err = tk.CountTokens(ctx, []*schema.Message{{
+		Role: schema.User,
+		MultiContent: []schema.ChatMessagePart{
+			{Type: schema.ChatMessagePartTypeText, Text: "abcd"},
+			{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "https://a.b/c.png"}},
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, 4+1+512, n)
+
+	n, err = tk.CountTokens(ctx, []*schema.Message{
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "1234", Function: schema.FunctionCall{Name: "get", Arguments: `{"a":1}`}}}),
+		schema.ToolMessage("ok", "1234"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, (4+1+1+2)+(4+1+1), n)
+}
+
+func TestTrimmer(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewTrimmer(ctx, &TrimmerConfig{})
+	assert.Error(t, err)
+
+	toolCall := func(id string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: "tool"}}})
+	}
+
+	input := []*schema.Message{
+		schema.SystemMessage("system"),
+		schema.UserMessage("q1"),
+		toolCall("1"),
+		schema.ToolMessage("r1", "1"),
+		schema.AssistantMessage("a1", nil),
+		schema.UserMessage("q2"),
+		toolCall("2"),
+		schema.ToolMessage("r2", "2"),
+		schema.AssistantMessage("a2", nil),
+	}
+
+	newTrimmer := func(maxTokens int) *Trimmer {
+		tr, err := NewTrimmer(ctx, &TrimmerConfig{MaxTokens: maxTokens, Tokenizer: perMessageTokenizer{}})
+		assert.NoError(t, err)
+		return tr
+	}
+
+	t.Run("within budget", func(t *testing.T) {
+		output, err := newTrimmer(90).Trim(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, output)
+	})
+
+	t.Run("drop oldest turn", func(t *testing.T) {
+		output, err := newTrimmer(80).Trim(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[0], input[5], input[6], input[7], input[8]}, output)
+	})
+
+	t.Run("trim latest turn", func(t *testing.T) {
+		// the tool call and its result are dropped together
+		output, err := newTrimmer(40).Trim(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[0], input[5], input[8]}, output)
+
+		output, err = newTrimmer(30).Trim(ctx, input[:8])
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[0], input[6], input[7]}, output)
+	})
+
+	t.Run("latest message always kept", func(t *testing.T) {
+		output, err := newTrimmer(5).Trim(ctx, input)
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[0], input[8]}, output)
+	})
+
+	t.Run("nil messages", func(t *testing.T) {
+		withNil := []*schema.Message{nil, input[0], input[1], input[2], nil, input[3], input[4], input[5], input[6], nil, input[7], input[8]}
+		output, err := newTrimmer(80).Trim(ctx, withNil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[0], input[5], input[6], input[7], input[8]}, output)
+
+		output, err = newTrimmer(5).Trim(ctx, []*schema.Message{input[1], nil})
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{input[1]}, output)
+	})
+
+	t.Run("message modifier", func(t *testing.T) {
+		output := newTrimmer(80).MessageModifier()(ctx, input)
+		assert.Len(t, output, 5)
+	})
+
+	t.Run("lambda", func(t *testing.T) {
+		chain := compose.NewChain[[]*schema.Message, []*schema.Message]()
+		chain.AppendLambda(newTrimmer(80).Lambda())
+		r, err := chain.Compile(ctx)
+		assert.NoError(t, err)
+
+		output, err := r.Invoke(ctx, input)
+		assert.NoError(t, err)
+		assert.Len(t, output, 5)
+	})
+}
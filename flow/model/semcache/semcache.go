@@ -0,0 +1,376 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package semcache provides a semantic response cache for chat models.
+// The conversation is normalized and embedded, and a past response whose conversation is similar enough
+// is returned without calling the model.
+package semcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/internal/ctxutil"
+	"github.com/cloudwego/eino/schema"
+)
+
+const defaultThreshold = 0.95
+
+// Config is the config for NewChatModel.
+type Config struct {
+	// Model is the chat model whose responses are cached.
+	Model model.ChatModel
+	// Embedder embeds the normalized conversation.
+	Embedder embedding.Embedder
+	// Threshold is the min cosine similarity for a cached response to be served.
+	// Optional. Default is 0.95.
+	Threshold float64
+	// TTL is how long a cached response is served.
+	// Optional. By default, cached responses never expire.
+	TTL time.Duration
+	// Store is the vector backend of the cache.
+	// Optional. Default is an unbounded MemoryStore.
+	Store Store
+	// Normalize builds the text to be embedded from the conversation.
+	// Optional. By default, the role, the content and the tool calls of each message are used,
+	// with the content lower-cased and the white spaces collapsed.
+	Normalize func(input []*schema.Message) string
+	// ReplayChunkSize is the number of runes of content in each chunk when a cached response is replayed as a stream.
+	// Optional. By default, the cached response is replayed as a single chunk.
+	ReplayChunkSize int
+	// Now returns the current time.
+	// Optional. Default is time.Now.
+	Now func() time.Time
+	// ScopeKey returns the key of the options of the request which are not common options,
+	// e.g. the impl-specific options wrapped by model.WrapImplSpecificOptFn, read by model.GetImplSpecificOptions,
+	// which is added to the scope of the cached responses, as the cache can't tell them apart by itself.
+	// Optional. By default, the impl-specific options are not part of the scope, so set it
+	// if the model is called with any of them affecting the responses, e.g. the reasoning effort.
+	ScopeKey func(opts []model.Option) string
+}
+
+// ChatModel is a model.ChatModel that serves semantically similar requests from the cache.
+// Responses are scoped by the bound tools, the common model options and the key by Config.ScopeKey,
+// so that a response is never served to a request with different tools or options,
+// e.g. a tool call to a model that can't call the tool.
+// Failures of the cache, such as embedding errors, don't fail the request, the model is called instead.
+type ChatModel struct {
+	model           model.ChatModel
+	embedder        embedding.Embedder
+	threshold       float64
+	ttl             time.Duration
+	store           Store
+	normalize       func(input []*schema.Message) string
+	replayChunkSize int
+	now             func() time.Time
+	scopeKey        func(opts []model.Option) string
+
+	mu    sync.RWMutex
+	tools []*schema.ToolInfo
+}
+
+// NewChatModel creates a ChatModel caching the responses of config.Model.
+func NewChatModel(_ context.Context, config *Config) (*ChatModel, error) {
+	if config == nil || config.Model == nil {
+		return nil, errors.New("model is required")
+	}
+	if config.Embedder == nil {
+		return nil, errors.New("embedder is required")
+	}
+
+	m := &ChatModel{
+		model:           config.Model,
+		embedder:        config.Embedder,
+		threshold:       config.Threshold,
+		ttl:             config.TTL,
+		store:           config.Store,
+		normalize:       config.Normalize,
+		replayChunkSize: config.ReplayChunkSize,
+		now:             config.Now,
+		scopeKey:        config.ScopeKey,
+	}
+	if m.threshold <= 0 {
+		m.threshold = defaultThreshold
+	}
+	if m.store == nil {
+		m.store = NewMemoryStore(0)
+	}
+	if m.normalize == nil {
+		m.normalize = defaultNormalize
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+
+	return m, nil
+}
+
+// Generate returns the cached response if there's a similar one, otherwise calls the model and caches its response.
+func (m *ChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req := m.newRequest(ctx, input, opts)
+	if entry := m.lookup(ctx, req); entry != nil {
+		return copyMessage(entry.Response), nil
+	}
+
+	msg, err := m.model.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.save(ctx, req, msg)
+
+	return msg, nil
+}
+
+// Stream replays the cached response as a stream if there's a similar one,
+// otherwise calls the model and caches the concatenated response once the stream is fully received,
+// with a context not canceled along with ctx, so Store.Put must not block forever.
+func (m *ChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	req := m.newRequest(ctx, input, opts)
+	if entry := m.lookup(ctx, req); entry != nil {
+		return schema.StreamReaderFromArray(m.replayChunks(entry.Response)), nil
+	}
+
+	sr, err := m.model.Stream(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if req.vector == nil {
+		return sr, nil
+	}
+
+	// the response is saved after the stream ends, when the context of the caller may be canceled
+	saveCtx := ctxutil.WithoutCancel(ctx)
+	srs := sr.Copy(2)
+	go func() {
+		if msg, ok := concatStream(srs[1]); ok {
+			m.save(saveCtx, req, msg)
+		}
+	}()
+
+	return srs[0], nil
+}
+
+// BindTools binds the tools to the model, the tools are part of the scope of the cached responses.
+func (m *ChatModel) BindTools(tools []*schema.ToolInfo) error {
+	if err := m.model.BindTools(tools); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.tools = tools
+	m.mu.Unlock()
+
+	return nil
+}
+
+type request struct {
+	scope  string
+	text   string
+	vector []float64
+}
+
+// newRequest embeds the conversation, a nil vector means the request can't be cached.
+func (m *ChatModel) newRequest(ctx context.Context, input []*schema.Message, opts []model.Option) *request {
+	req := &request{text: m.normalize(input)}
+
+	scope, err := m.scope(opts)
+	if err != nil {
+		return req
+	}
+	req.scope = scope
+
+	vectors, err := m.embedder.EmbedStrings(ctx, []string{req.text})
+	if err != nil || len(vectors) != 1 {
+		return req
+	}
+	req.vector = vectors[0]
+
+	return req
+}
+
+func (m *ChatModel) lookup(ctx context.Context, req *request) *Entry {
+	if req.vector == nil {
+		return nil
+	}
+
+	entry, _, err := m.store.Search(ctx, req.scope, req.vector, m.threshold, m.now())
+	if err != nil || entry == nil || entry.Response == nil {
+		return nil
+	}
+
+	return entry
+}
+
+func (m *ChatModel) save(ctx context.Context, req *request, msg *schema.Message) {
+	if req.vector == nil || msg == nil {
+		return
+	}
+
+	now := m.now()
+	entry := &Entry{
+		Scope:     req.scope,
+		Text:      req.text,
+		Vector:    req.vector,
+		Response:  copyMessage(msg),
+		CreatedAt: now,
+	}
+	if m.ttl > 0 {
+		entry.ExpiresAt = now.Add(m.ttl)
+	}
+
+	_ = m.store.Put(ctx, entry)
+}
+
+type scopeKey struct {
+	Tools       []*schema.ToolInfo `json:"tools,omitempty"`
+	ToolChoice  *schema.ToolChoice `json:"tool_choice,omitempty"`
+	Model       *string            `json:"model,omitempty"`
+	Temperature *float32           `json:"temperature,omitempty"`
+	TopP        *float32           `json:"top_p,omitempty"`
+	MaxTokens   *int               `json:"max_tokens,omitempty"`
+	Stop        []string           `json:"stop,omitempty"`
+	Custom      string             `json:"custom,omitempty"`
+}
+
+// scope identifies the tools, the common options and the key by ScopeKey of the request, tools passed as options take
+// precedence over the bound tools, the same as how chat models treat them.
+func (m *ChatModel) scope(opts []model.Option) (string, error) {
+	m.mu.RLock()
+	options := model.GetCommonOptions(&model.Options{Tools: m.tools}, opts...)
+	m.mu.RUnlock()
+
+	var custom string
+	if m.scopeKey != nil {
+		custom = m.scopeKey(opts)
+	}
+
+	b, err := json.Marshal(&scopeKey{
+		Tools:       options.Tools,
+		ToolChoice:  options.ToolChoice,
+		Model:       options.Model,
+		Temperature: options.Temperature,
+		TopP:        options.TopP,
+		MaxTokens:   options.MaxTokens,
+		Stop:        options.Stop,
+		Custom:      custom,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache scope: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayChunks splits the response into chunks, the content is split by ReplayChunkSize and the other fields
+// are carried by the last chunk, so that concatenating the chunks gives back the response.
+func (m *ChatModel) replayChunks(msg *schema.Message) []*schema.Message {
+	msg = copyMessage(msg)
+
+	content := []rune(msg.Content)
+	if m.replayChunkSize <= 0 || len(content) <= m.replayChunkSize {
+		return []*schema.Message{msg}
+	}
+
+	var chunks []*schema.Message
+	for len(content) > m.replayChunkSize {
+		chunks = append(chunks, &schema.Message{
+			Role:    msg.Role,
+			Content: string(content[:m.replayChunkSize]),
+		})
+		content = content[m.replayChunkSize:]
+	}
+
+	msg.Content = string(content)
+
+	return append(chunks, msg)
+}
+
+func concatStream(sr *schema.StreamReader[*schema.Message]) (*schema.Message, bool) {
+	defer sr.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) == 0 {
+		return nil, false
+	}
+
+	msg, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		return nil, false
+	}
+
+	return msg, true
+}
+
+func copyMessage(msg *schema.Message) *schema.Message {
+	cp := *msg
+	if msg.ToolCalls != nil {
+		cp.ToolCalls = make([]schema.ToolCall, len(msg.ToolCalls))
+		copy(cp.ToolCalls, msg.ToolCalls)
+	}
+	if msg.ResponseMeta != nil {
+		meta := *msg.ResponseMeta
+		cp.ResponseMeta = &meta
+	}
+
+	return &cp
+}
+
+func defaultNormalize(input []*schema.Message) string {
+	var sb strings.Builder
+	for _, msg := range input {
+		if msg == nil {
+			continue
+		}
+
+		sb.WriteString(string(msg.Role))
+		sb.WriteString(": ")
+		sb.WriteString(strings.ToLower(strings.Join(strings.Fields(msg.Content), " ")))
+		for _, tc := range msg.ToolCalls {
+			sb.WriteString(" [")
+			sb.WriteString(tc.Function.Name)
+			sb.WriteString(" ")
+			sb.WriteString(tc.Function.Arguments)
+			sb.WriteString("]")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,351 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package semcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+var keywords = []string{"weather", "stock", "paris", "london"}
+
+// keywordEmbedder embeds the texts by the occurrences of keywords.
+type keywordEmbedder struct {
+	err error
+}
+
+func (k *keywordEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+
+	ret := make([][]float64, len(texts))
+	for i, text := range texts {
+		ret[i] = make([]float64, len(keywords))
+		for j, kw := range keywords {
+			ret[i][j] = float64(strings.Count(text, kw))
+		}
+	}
+	return ret, nil
+}
+
+// countingModel answers with the last user message and counts the calls.
+type countingModel struct {
+	calls int32
+	tools []*schema.ToolInfo
+}
+
+func (c *countingModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	n := atomic.AddInt32(&c.calls, 1)
+	return schema.AssistantMessage("answer to "+input[len(input)-1].Content+" #"+string(rune('0'+n)), nil), nil
+}
+
+func (c *countingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	msg, _ := c.Generate(ctx, input, opts...)
+	half := len(msg.Content) / 2
+	return schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage(msg.Content[:half], nil),
+		schema.AssistantMessage(msg.Content[half:], nil),
+	}), nil
+}
+
+func (c *countingModel) BindTools(tools []*schema.ToolInfo) error {
+	c.tools = tools
+	return nil
+}
+
+func readAll(t *testing.T, sr *schema.StreamReader[*schema.Message]) (*schema.Message, int) {
+	defer sr.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+
+	msg, err := schema.ConcatMessages(chunks)
+	assert.NoError(t, err)
+	return msg, len(chunks)
+}
+
+func TestChatModelGenerate(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewChatModel(ctx, &Config{Embedder: &keywordEmbedder{}})
+	assert.Error(t, err)
+	_, err = NewChatModel(ctx, &Config{Model: &countingModel{}})
+	assert.Error(t, err)
+
+	cm := &countingModel{}
+	m, err := NewChatModel(ctx, &Config{Model: cm, Embedder: &keywordEmbedder{}})
+	assert.NoError(t, err)
+
+	out, err := m.Generate(ctx, []*schema.Message{schema.UserMessage("weather in Paris?")})
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to weather in Paris? #1", out.Content)
+
+	// similar conversation, served from the cache
+	out, err = m.Generate(ctx, []*schema.Message{schema.UserMessage("What's the   WEATHER in paris")})
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to weather in Paris? #1", out.Content)
+	assert.Equal(t, int32(1), cm.calls)
+
+	// the cached response is not shared with the caller
+	out.Content = "changed"
+	out, err = m.Generate(ctx, []*schema.Message{schema.UserMessage("weather in paris")})
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to weather in Paris? #1", out.Content)
+
+	// below the threshold
+	out, err = m.Generate(ctx, []*schema.Message{schema.UserMessage("weather in London?")})
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to weather in London? #2", out.Content)
+	assert.Equal(t, int32(2), cm.calls)
+}
+
+func TestChatModelScope(t *testing.T) {
+	ctx := context.Background()
+
+	cm := &countingModel{}
+	m, err := NewChatModel(ctx, &Config{Model: cm, Embedder: &keywordEmbedder{}})
+	assert.NoError(t, err)
+
+	input := []*schema.Message{schema.UserMessage("stock price")}
+
+	_, err = m.Generate(ctx, input)
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, model.WithTemperature(0.5))
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, model.WithTemperature(0.5))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), cm.calls)
+
+	tools := []*schema.ToolInfo{{Name: "get_stock", Desc: "get the stock price"}}
+	assert.NoError(t, m.BindTools(tools))
+	assert.Equal(t, tools, cm.tools)
+
+	_, err = m.Generate(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), cm.calls)
+
+	// tools passed as options take precedence over the bound tools
+	_, err = m.Generate(ctx, input, model.WithTools(nil))
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, model.WithTools(tools))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), cm.calls)
+}
+
+type effortOptions struct {
+	effort string
+}
+
+func withEffort(effort string) model.Option {
+	return model.WrapImplSpecificOptFn(func(o *effortOptions) {
+		o.effort = effort
+	})
+}
+
+func TestChatModelScopeKey(t *testing.T) {
+	ctx := context.Background()
+
+	input := []*schema.Message{schema.UserMessage("stock price")}
+
+	// the impl-specific options are not part of the scope by default
+	cm := &countingModel{}
+	m, err := NewChatModel(ctx, &Config{Model: cm, Embedder: &keywordEmbedder{}})
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, withEffort("low"))
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, withEffort("high"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), cm.calls)
+
+	cm = &countingModel{}
+	m, err = NewChatModel(ctx, &Config{
+		Model:    cm,
+		Embedder: &keywordEmbedder{},
+		ScopeKey: func(opts []model.Option) string {
+			return model.GetImplSpecificOptions(&effortOptions{}, opts...).effort
+		},
+	})
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, withEffort("low"))
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, withEffort("high"))
+	assert.NoError(t, err)
+	_, err = m.Generate(ctx, input, withEffort("low"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), cm.calls)
+}
+
+func TestChatModelTTL(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Unix(1000, 0)
+	cm := &countingModel{}
+	store := NewMemoryStore(0)
+	m, err := NewChatModel(ctx, &Config{
+		Model:    cm,
+		Embedder: &keywordEmbedder{},
+		TTL:      time.Minute,
+		Store:    store,
+		Now:      func() time.Time { return now },
+	})
+	assert.NoError(t, err)
+
+	input := []*schema.Message{schema.UserMessage("weather")}
+	_, err = m.Generate(ctx, input)
+	assert.NoError(t, err)
+
+	now = now.Add(59 * time.Second)
+	_, err = m.Generate(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), cm.calls)
+
+	now = now.Add(time.Second)
+	out, err := m.Generate(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to weather #2", out.Content)
+	assert.Equal(t, int32(2), cm.calls)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestChatModelStream(t *testing.T) {
+	ctx := context.Background()
+
+	cm := &countingModel{}
+	store := NewMemoryStore(0)
+	m, err := NewChatModel(ctx, &Config{Model: cm, Embedder: &keywordEmbedder{}, Store: store, ReplayChunkSize: 4})
+	assert.NoError(t, err)
+
+	input := []*schema.Message{schema.UserMessage("stock")}
+	sr, err := m.Stream(ctx, input)
+	assert.NoError(t, err)
+	out, _ := readAll(t, sr)
+	assert.Equal(t, "answer to stock #1", out.Content)
+
+	// the response is cached in background once the stream ends
+	assert.Eventually(t, func() bool { return store.Len() == 1 }, time.Second, 10*time.Millisecond)
+
+	sr, err = m.Stream(ctx, input)
+	assert.NoError(t, err)
+	out, n := readAll(t, sr)
+	assert.Equal(t, "answer to stock #1", out.Content)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, int32(1), cm.calls)
+
+	// responses cached by Generate are replayed by Stream as well
+	gen, err := m.Generate(ctx, []*schema.Message{schema.UserMessage("weather")})
+	assert.NoError(t, err)
+	sr, err = m.Stream(ctx, []*schema.Message{schema.UserMessage("weather")})
+	assert.NoError(t, err)
+	out, _ = readAll(t, sr)
+	assert.Equal(t, gen.Content, out.Content)
+	assert.Equal(t, int32(2), cm.calls)
+}
+
+// ctxStore fails to put the entries with a canceled context.
+type ctxStore struct {
+	*MemoryStore
+}
+
+func (s *ctxStore) Put(ctx context.Context, entry *Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Put(ctx, entry)
+}
+
+func TestChatModelStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := &ctxStore{MemoryStore: NewMemoryStore(0)}
+	m, err := NewChatModel(ctx, &Config{Model: &countingModel{}, Embedder: &keywordEmbedder{}, Store: store})
+	assert.NoError(t, err)
+
+	// the caller is done before the response is saved
+	cancel()
+	sr, err := m.Stream(ctx, []*schema.Message{schema.UserMessage("stock")})
+	assert.NoError(t, err)
+	out, _ := readAll(t, sr)
+	assert.Equal(t, "answer to stock #1", out.Content)
+
+	assert.Eventually(t, func() bool { return store.Len() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestChatModelEmbeddingError(t *testing.T) {
+	ctx := context.Background()
+
+	cm := &countingModel{}
+	store := NewMemoryStore(0)
+	m, err := NewChatModel(ctx, &Config{Model: cm, Embedder: &keywordEmbedder{err: errors.New("embedding failed")}, Store: store})
+	assert.NoError(t, err)
+
+	input := []*schema.Message{schema.UserMessage("weather")}
+	for i := 0; i < 2; i++ {
+		_, err = m.Generate(ctx, input)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), cm.calls)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	ctx := context.Background()
+
+	store := NewMemoryStore(2)
+	base := time.Unix(0, 0)
+	for i, scope := range []string{"a", "b", "a"} {
+		assert.NoError(t, store.Put(ctx, &Entry{
+			Scope:     scope,
+			Vector:    []float64{1, float64(i)},
+			Response:  schema.AssistantMessage(string(rune('0'+i)), nil),
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+		}))
+	}
+	assert.Equal(t, 2, store.Len())
+
+	entry, score, err := store.Search(ctx, "a", []float64{1, 0}, 0.4, base)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "2", entry.Response.Content)
+		assert.InDelta(t, 0.447, score, 0.001)
+	}
+
+	entry, _, err = store.Search(ctx, "b", []float64{1, 1}, 0.99, base)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "1", entry.Response.Content)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package semcache
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Entry is a cached response, together with the embedding of the conversation it answers.
+type Entry struct {
+	// Scope identifies the bound tools and the model options the response was generated with,
+	// entries are only matched within the same scope.
+	Scope string
+	// Text is the normalized conversation.
+	Text string
+	// Vector is the embedding of Text.
+	Vector []float64
+	// Response is the message generated by the model.
+	Response *schema.Message
+	// CreatedAt is the time the entry is created.
+	CreatedAt time.Time
+	// ExpiresAt is the time after which the entry is no longer served, zero means never.
+	ExpiresAt time.Time
+}
+
+func (e *Entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Store is the vector backend of the cache.
+type Store interface {
+	// Search returns the most similar entry in the scope whose similarity to the vector is no less than minScore,
+	// and its similarity. Expired entries must not be returned. A nil entry means no match.
+	Search(ctx context.Context, scope string, vector []float64, minScore float64, now time.Time) (*Entry, float64, error)
+	// Put adds an entry to the store.
+	Put(ctx context.Context, entry *Entry) error
+}
+
+// MemoryStore is an in-memory Store, which searches the entries of the scope by cosine similarity.
+type MemoryStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	scopes  map[string][]*Entry
+	entries int
+}
+
+// NewMemoryStore creates a MemoryStore keeping at most maxEntries entries, the oldest entries are evicted first.
+// A non-positive maxEntries means no limit.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		scopes:     make(map[string][]*Entry),
+	}
+}
+
+// Search implements Store, expired entries in the scope are removed along the way.
+func (s *MemoryStore) Search(_ context.Context, scope string, vector []float64, minScore float64, now time.Time) (*Entry, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		best      *Entry
+		bestScore float64
+		alive     = s.scopes[scope][:0]
+	)
+	for _, e := range s.scopes[scope] {
+		if e.expired(now) {
+			s.entries--
+			continue
+		}
+		alive = append(alive, e)
+
+		score := cosineSimilarity(vector, e.Vector)
+		if score >= minScore && (best == nil || score > bestScore) {
+			best, bestScore = e, score
+		}
+	}
+
+	if len(alive) == 0 {
+		delete(s.scopes, scope)
+	} else {
+		s.scopes[scope] = alive
+	}
+
+	return best, bestScore, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scopes[entry.Scope] = append(s.scopes[entry.Scope], entry)
+	s.entries++
+
+	for s.maxEntries > 0 && s.entries > s.maxEntries {
+		s.evictOldest()
+	}
+
+	return nil
+}
+
+// Len returns the number of entries in the store, including the expired ones not yet removed.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entries
+}
+
+func (s *MemoryStore) evictOldest() {
+	var (
+		oldestScope string
+		oldest      *Entry
+	)
+	for scope, entries := range s.scopes {
+		if len(entries) > 0 && (oldest == nil || entries[0].CreatedAt.Before(oldest.CreatedAt)) {
+			oldestScope, oldest = scope, entries[0]
+		}
+	}
+	if oldest == nil {
+		return
+	}
+
+	if rest := s.scopes[oldestScope][1:]; len(rest) == 0 {
+		delete(s.scopes, oldestScope)
+	} else {
+		s.scopes[oldestScope] = rest
+	}
+	s.entries--
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pii
+
+import (
+	"context"
+	"regexp"
+)
+
+// Entity types of the built-in detectors.
+const (
+	EntityEmail      = "EMAIL"
+	EntityPhone      = "PHONE"
+	EntityIDNumber   = "ID_NUMBER"
+	EntityCreditCard = "CREDIT_CARD"
+)
+
+// Entity is a piece of personal information detected in the text.
+type Entity struct {
+	// Type is the type of the entity, e.g. EntityEmail, which names the placeholders.
+	Type string
+	// Start and End are the byte offsets of the entity in the text.
+	Start, End int
+}
+
+// Detector detects the entities in the text, e.g. by regular expressions or by an NER service.
+type Detector interface {
+	Detect(ctx context.Context, text string) ([]Entity, error)
+}
+
+// RegexDetector detects the entities matching Pattern, e.g. custom entities like employee numbers.
+type RegexDetector struct {
+	// Type is the type of the entities detected.
+	Type string
+	// Pattern matches the entities.
+	Pattern *regexp.Regexp
+	// Validate filters the matches, e.g. by checksum.
+	// Optional. By default, all the matches are entities.
+	Validate func(match string) bool
+}
+
+// Detect returns the matches of Pattern.
+func (d *RegexDetector) Detect(_ context.Context, text string) ([]Entity, error) {
+	var entities []Entity
+	for _, loc := range d.Pattern.FindAllStringIndex(text, -1) {
+		if d.Validate != nil && !d.Validate(text[loc[0]:loc[1]]) {
+			continue
+		}
+		entities = append(entities, Entity{Type: d.Type, Start: loc[0], End: loc[1]})
+	}
+	return entities, nil
+}
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	idNumberPattern   = regexp.MustCompile(`\b(?:\d{17}[\dXx]|\d{3}-\d{2}-\d{4})\b`)
+	creditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+// phonePattern matches the phone numbers in the common formats only, instead of any run of digits.
+var phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s-]?(?:\(\d{1,4}\)[\s-]?|\d{1,4}[\s-])\d{3,4}[\s-]?\d{4}` + // with a country code
+	`|\(\d{3}\)\s?\d{3}[\s.-]\d{4}|\b\d{3}[\s.-]\d{3}[\s.-]\d{4}` + // the US format
+	`|\b1[3-9]\d[\s-]?\d{4}[\s-]?\d{4})\b`) // the mobile numbers of China
+
+// NewEmailDetector detects email addresses.
+func NewEmailDetector() Detector {
+	return &RegexDetector{Type: EntityEmail, Pattern: emailPattern}
+}
+
+// NewPhoneDetector detects phone numbers with a country code, e.g. +1 (555) 123-4567 or +86 138 1234 5678,
+// in the US format, e.g. (555) 123-4567 or 555-123-4567, or the mobile numbers of China, e.g. 13812345678.
+// A bare run of digits in other formats is not taken as a phone number, e.g. an order number.
+func NewPhoneDetector() Detector {
+	return &RegexDetector{Type: EntityPhone, Pattern: phonePattern}
+}
+
+// NewIDNumberDetector detects the 18 digit resident identity card numbers of China and the social security numbers of the US.
+func NewIDNumberDetector() Detector {
+	return &RegexDetector{Type: EntityIDNumber, Pattern: idNumberPattern}
+}
+
+// NewCreditCardDetector detects the credit card numbers of 13 to 19 digits passing the Luhn check,
+// optionally separated by spaces or dashes.
+func NewCreditCardDetector() Detector {
+	return &RegexDetector{Type: EntityCreditCard, Pattern: creditCardPattern, Validate: luhn}
+}
+
+// DefaultDetectors returns the built-in detectors. The detectors earlier in the list take precedence
+// when the entities detected overlap at the same start, e.g. a credit card number is not taken as a phone number.
+func DefaultDetectors() []Detector {
+	return []Detector{NewEmailDetector(), NewIDNumberDetector(), NewCreditCardDetector(), NewPhoneDetector()}
+}
+
+func luhn(number string) bool {
+	var sum, digits int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
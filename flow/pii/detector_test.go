@@ -0,0 +1,51 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pii
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectors(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		detector Detector
+		text     string
+		want     []string
+	}{
+		{NewEmailDetector(), "mail alice.w@example.com or bob+news@mail.example.org.", []string{"alice.w@example.com", "bob+news@mail.example.org"}},
+		{NewPhoneDetector(), "call +1 (555) 123-4567, 13812345678 or +86 138 1234 5678", []string{"+1 (555) 123-4567", "13812345678", "+86 138 1234 5678"}},
+		{NewPhoneDetector(), "or (555) 123-4567, 555.123.4567, not order 12345678, 1234567 or 20240101123", []string{"(555) 123-4567", "555.123.4567"}},
+		{NewIDNumberDetector(), "id 11010519491231002X, ssn 078-05-1120, not 1234567", []string{"11010519491231002X", "078-05-1120"}},
+		{NewCreditCardDetector(), "card 4111 1111 1111 1111, 5500-0000-0000-0004, not 4111 1111 1111 1112", []string{"4111 1111 1111 1111", "5500-0000-0000-0004"}},
+	}
+
+	for _, c := range cases {
+		entities, err := c.detector.Detect(ctx, c.text)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, e := range entities {
+			got = append(got, c.text[e.Start:e.End])
+		}
+		assert.Equal(t, c.want, got, c.text)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Mapping maps the placeholders to the original entities in a run, so that the output can be restored.
+// The same entity gets the same placeholder in the run. It's safe for concurrent use.
+type Mapping struct {
+	mu            sync.Mutex
+	byEntity      map[string]string // entity type and value -> placeholder
+	byPlaceholder map[string]string // placeholder -> value
+	counts        map[string]int    // entity type -> number of different entities
+}
+
+// NewMapping creates an empty Mapping.
+func NewMapping() *Mapping {
+	return &Mapping{
+		byEntity:      make(map[string]string),
+		byPlaceholder: make(map[string]string),
+		counts:        make(map[string]int),
+	}
+}
+
+// ErrNoMapping is returned when the messages are redacted without the Mapping set by WithMapping,
+// which is required to restore the output.
+var ErrNoMapping = errors.New("pii mapping is not found in the context, call pii.WithMapping first")
+
+type mappingKey struct{}
+
+// WithMapping returns a context carrying a new Mapping, which is shared by the redactions in the context,
+// e.g. the rounds of an agent run using Redactor.MessageModifier, to restore the output of the run afterwards.
+// e.g.
+//
+//	ctx, mapping := pii.WithMapping(ctx)
+//	out, err := agent.Generate(ctx, input)
+//	if err != nil {...}
+//	out = mapping.RestoreMessage(out)
+func WithMapping(ctx context.Context) (context.Context, *Mapping) {
+	m := NewMapping()
+	return context.WithValue(ctx, mappingKey{}, m), m
+}
+
+// MappingFromContext returns the Mapping set by WithMapping, or nil.
+func MappingFromContext(ctx context.Context) *Mapping {
+	m, _ := ctx.Value(mappingKey{}).(*Mapping)
+	return m
+}
+
+func (m *Mapping) placeholderOf(entityType, value string, format func(entityType string, n int) string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := entityType + "\x00" + value
+	if p, ok := m.byEntity[key]; ok {
+		return p
+	}
+
+	m.counts[entityType]++
+	p := format(entityType, m.counts[entityType])
+	m.byEntity[key] = p
+	m.byPlaceholder[p] = value
+	return p
+}
+
+// Len returns the number of different entities in the Mapping.
+func (m *Mapping) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.byPlaceholder)
+}
+
+// Restore replaces the placeholders in text with the original entities.
+func (m *Mapping) Restore(text string) string {
+	m.mu.Lock()
+	pairs := make([]string, 0, 2*len(m.byPlaceholder))
+	for p, v := range m.byPlaceholder {
+		pairs = append(pairs, p, v)
+	}
+	m.mu.Unlock()
+
+	if len(pairs) == 0 {
+		return text
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// RestoreMessage returns a copy of msg with the placeholders in the content, the text parts and the tool call arguments restored.
+// The chunks of a streaming output should be concatenated before restored, since a placeholder may be split across chunks.
+func (m *Mapping) RestoreMessage(msg *schema.Message) *schema.Message {
+	if msg == nil {
+		return nil
+	}
+	return mapMessage(msg, m.Restore)
+}
+
+func defaultPlaceholder(entityType string, n int) string {
+	return fmt.Sprintf("[%s_%d]", entityType, n)
+}
+
+// mapMessage returns a copy of msg with the text mapped by f.
+func mapMessage(msg *schema.Message, f func(string) string) *schema.Message {
+	out := *msg
+	out.Content = f(msg.Content)
+
+	if len(msg.MultiContent) > 0 {
+		out.MultiContent = make([]schema.ChatMessagePart, len(msg.MultiContent))
+		for i, part := range msg.MultiContent {
+			if part.Type == schema.ChatMessagePartTypeText {
+				part.Text = f(part.Text)
+			}
+			out.MultiContent[i] = part
+		}
+	}
+
+	if len(msg.ToolCalls) > 0 {
+		out.ToolCalls = make([]schema.ToolCall, len(msg.ToolCalls))
+		for i, tc := range msg.ToolCalls {
+			tc.Function.Arguments = f(tc.Function.Arguments)
+			out.ToolCalls[i] = tc
+		}
+	}
+
+	return &out
+}
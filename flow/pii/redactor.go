@@ -0,0 +1,228 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package pii masks the personal information, e.g. emails, phone numbers, ID numbers and credit card numbers,
+// in the documents before indexing, and in the messages before sending them to the external models,
+// with reversible placeholders, so that the output of the models can be restored afterwards.
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// MetaKeyRedactions is the key of Document.MetaData, recording the number of entities redacted of each type,
+// with map[string]int as the value.
+const MetaKeyRedactions = "_pii_redactions"
+
+// Config is the config for NewRedactor.
+type Config struct {
+	// Detectors detect the entities to redact, the earlier ones take precedence when the entities overlap at the same start.
+	// Optional. Default is DefaultDetectors().
+	Detectors []Detector
+	// Placeholder formats the placeholder of the nth different entity of the type in a run, which must be unique.
+	// Optional. Default is like [EMAIL_1].
+	Placeholder func(entityType string, n int) string
+}
+
+// Redactor replaces the entities detected with placeholders, recorded in the Mapping of the context set by WithMapping.
+// It's a document.Transformer, and a message pre-processor by RedactMessages, MessageModifier or Lambda.
+// The Mapping is required to redact the messages, since the output of the models can't be restored without it,
+// while the documents are redacted with a new Mapping on each call if there is none.
+type Redactor struct {
+	detectors   []Detector
+	placeholder func(entityType string, n int) string
+}
+
+var _ document.Transformer = (*Redactor)(nil)
+
+// NewRedactor creates a Redactor.
+func NewRedactor(_ context.Context, config *Config) (*Redactor, error) {
+	if config == nil {
+		config = &Config{}
+	}
+
+	detectors := config.Detectors
+	if len(detectors) == 0 {
+		detectors = DefaultDetectors()
+	}
+	for _, d := range detectors {
+		if d == nil {
+			return nil, errors.New("pii detector is nil")
+		}
+	}
+
+	placeholder := config.Placeholder
+	if placeholder == nil {
+		placeholder = defaultPlaceholder
+	}
+
+	return &Redactor{detectors: detectors, placeholder: placeholder}, nil
+}
+
+// Transform redacts the content of the documents, and records the number of entities redacted of each type
+// in Document.MetaData with MetaKeyRedactions. The documents are copied instead of modified.
+func (r *Redactor) Transform(ctx context.Context, src []*schema.Document, _ ...document.TransformerOption) ([]*schema.Document, error) {
+	m := r.mapping(ctx)
+
+	output := make([]*schema.Document, 0, len(src))
+	for _, doc := range src {
+		if doc == nil {
+			continue
+		}
+
+		stats := make(map[string]int)
+		content, err := r.redact(ctx, m, doc.Content, stats, false)
+		if err != nil {
+			return nil, err
+		}
+
+		metaData := make(map[string]any, len(doc.MetaData)+1)
+		for k, v := range doc.MetaData {
+			metaData[k] = v
+		}
+		metaData[MetaKeyRedactions] = stats
+
+		output = append(output, &schema.Document{ID: doc.ID, Content: content, MetaData: metaData})
+	}
+
+	return output, nil
+}
+
+// GetType returns the type of the transformer.
+func (r *Redactor) GetType() string {
+	return "PIIRedactor"
+}
+
+// RedactMessages returns copies of the messages, with the entities in the content, the text parts and the tool call arguments redacted.
+// It returns ErrNoMapping if the context has no Mapping set by WithMapping.
+func (r *Redactor) RedactMessages(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
+	m := MappingFromContext(ctx)
+	if m == nil {
+		return nil, ErrNoMapping
+	}
+	return r.redactMessages(ctx, m, input, false)
+}
+
+// MessageModifier returns the Redactor as a message modifier, e.g. to be used as react.AgentConfig.MessageModifier.
+// If a detector fails, the messages are redacted by the other detectors.
+// The agent MUST be called with the context returned by WithMapping. As the message modifier can't return an error,
+// without the Mapping, the messages are still redacted, but the placeholders in the output can't be restored.
+func (r *Redactor) MessageModifier() func(ctx context.Context, input []*schema.Message) []*schema.Message {
+	return func(ctx context.Context, input []*schema.Message) []*schema.Message {
+		output, _ := r.redactMessages(ctx, r.mapping(ctx), input, true)
+		return output
+	}
+}
+
+// Lambda returns the Redactor as a Lambda node, taking and returning []*schema.Message.
+// The graph MUST be called with the context returned by WithMapping, or the node fails with ErrNoMapping.
+func (r *Redactor) Lambda(opts ...compose.LambdaOpt) *compose.Lambda {
+	return compose.InvokableLambda(r.RedactMessages, opts...)
+}
+
+func (r *Redactor) redactMessages(ctx context.Context, m *Mapping, input []*schema.Message, ignoreErr bool) ([]*schema.Message, error) {
+
+	var err error
+	redact := func(text string) string {
+		if err != nil || len(text) == 0 {
+			return text
+		}
+		var redacted string
+		redacted, err = r.redact(ctx, m, text, nil, ignoreErr)
+		return redacted
+	}
+
+	output := make([]*schema.Message, len(input))
+	for i, msg := range input {
+		if msg == nil {
+			continue
+		}
+		if output[i] = mapMessage(msg, redact); err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+func (r *Redactor) mapping(ctx context.Context) *Mapping {
+	if m := MappingFromContext(ctx); m != nil {
+		return m
+	}
+	return NewMapping()
+}
+
+// redact replaces the entities in text with placeholders, and counts them in stats if it's not nil.
+func (r *Redactor) redact(ctx context.Context, m *Mapping, text string, stats map[string]int, ignoreErr bool) (string, error) {
+	type detected struct {
+		Entity
+		priority int
+	}
+
+	var entities []detected
+	for i, d := range r.detectors {
+		found, err := d.Detect(ctx, text)
+		if err != nil {
+			if ignoreErr {
+				continue
+			}
+			return "", fmt.Errorf("failed to detect pii: %w", err)
+		}
+		for _, e := range found {
+			if e.Start >= 0 && e.Start < e.End && e.End <= len(text) {
+				entities = append(entities, detected{Entity: e, priority: i})
+			}
+		}
+	}
+	if len(entities) == 0 {
+		return text, nil
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		if entities[i].Start != entities[j].Start {
+			return entities[i].Start < entities[j].Start
+		}
+		if entities[i].priority != entities[j].priority {
+			return entities[i].priority < entities[j].priority
+		}
+		return entities[i].End > entities[j].End
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, e := range entities {
+		if e.Start < last { // overlaps the entity redacted
+			continue
+		}
+		sb.WriteString(text[last:e.Start])
+		sb.WriteString(m.placeholderOf(e.Type, text[e.Start:e.End], r.placeholder))
+		last = e.End
+		if stats != nil {
+			stats[e.Type]++
+		}
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String(), nil
+}
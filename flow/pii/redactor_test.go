@@ -0,0 +1,132 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+type failingDetector struct{}
+
+func (failingDetector) Detect(_ context.Context, _ string) ([]Entity, error) {
+	return nil, errors.New("ner service unavailable")
+}
+
+func TestRedactorTransform(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRedactor(ctx, &Config{Detectors: append(DefaultDetectors(),
+		&RegexDetector{Type: "EMPLOYEE", Pattern: regexp.MustCompile(`\bEMP-\d{4}\b`)})})
+	assert.NoError(t, err)
+
+	ctx, mapping := WithMapping(ctx)
+	src := []*schema.Document{
+		{ID: "1", Content: "EMP-0042 pays with 4111 1111 1111 1111, mail alice@example.com", MetaData: map[string]any{"source": "hr"}},
+		{ID: "2", Content: "alice@example.com called from 13812345678"},
+	}
+	docs, err := r.Transform(ctx, src)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "[EMPLOYEE_1] pays with [CREDIT_CARD_1], mail [EMAIL_1]", docs[0].Content)
+	assert.Equal(t, map[string]any{"source": "hr", MetaKeyRedactions: map[string]int{"EMPLOYEE": 1, "CREDIT_CARD": 1, "EMAIL": 1}}, docs[0].MetaData)
+	assert.Equal(t, "[EMAIL_1] called from [PHONE_1]", docs[1].Content)
+	assert.Equal(t, map[string]int{"EMAIL": 1, "PHONE": 1}, docs[1].MetaData[MetaKeyRedactions])
+
+	// the source documents are not modified
+	assert.Equal(t, "alice@example.com called from 13812345678", src[1].Content)
+	assert.Nil(t, src[1].MetaData)
+
+	assert.Equal(t, 4, mapping.Len())
+	assert.Equal(t, src[0].Content, mapping.Restore(docs[0].Content))
+
+	r, err = NewRedactor(ctx, &Config{Detectors: []Detector{failingDetector{}}})
+	assert.NoError(t, err)
+	_, err = r.Transform(ctx, src)
+	assert.ErrorContains(t, err, "ner service unavailable")
+}
+
+func TestRedactorMessages(t *testing.T) {
+	r, err := NewRedactor(context.Background(), &Config{
+		Detectors:   []Detector{NewEmailDetector(), failingDetector{}},
+		Placeholder: func(entityType string, n int) string { return fmt.Sprintf("<%s#%d>", entityType, n) },
+	})
+	assert.NoError(t, err)
+
+	ctx, mapping := WithMapping(context.Background())
+	input := []*schema.Message{
+		schema.SystemMessage("you are a helpful assistant"),
+		{
+			Role: schema.User,
+			MultiContent: []schema.ChatMessagePart{
+				{Type: schema.ChatMessagePartTypeText, Text: "write to bob@example.com"},
+				{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "https://example.com/a.png"}},
+			},
+		},
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "send", Arguments: `{"to": "bob@example.com"}`}}}),
+	}
+
+	_, err = r.RedactMessages(ctx, input)
+	assert.ErrorContains(t, err, "ner service unavailable")
+
+	// the message modifier skips the failing detector
+	output := r.MessageModifier()(ctx, input)
+	assert.Equal(t, "you are a helpful assistant", output[0].Content)
+	assert.Equal(t, "write to <EMAIL#1>", output[1].MultiContent[0].Text)
+	assert.Equal(t, input[1].MultiContent[1], output[1].MultiContent[1])
+	assert.Equal(t, `{"to": "<EMAIL#1>"}`, output[2].ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "write to bob@example.com", input[1].MultiContent[0].Text)
+
+	// the output of the model is restored by the mapping of the run
+	answer := mapping.RestoreMessage(schema.AssistantMessage("sent to <EMAIL#1>", nil))
+	assert.Equal(t, "sent to bob@example.com", answer.Content)
+}
+
+func TestRedactorWithoutMapping(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRedactor(ctx, nil)
+	assert.NoError(t, err)
+
+	input := []*schema.Message{schema.UserMessage("write to bob@example.com")}
+
+	_, err = r.RedactMessages(ctx, input)
+	assert.ErrorIs(t, err, ErrNoMapping)
+
+	chain := compose.NewChain[[]*schema.Message, []*schema.Message]()
+	chain.AppendLambda(r.Lambda())
+	runnable, err := chain.Compile(ctx)
+	assert.NoError(t, err)
+	_, err = runnable.Invoke(ctx, input)
+	assert.ErrorIs(t, err, ErrNoMapping)
+
+	mappingCtx, mapping := WithMapping(ctx)
+	output, err := runnable.Invoke(mappingCtx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "write to [EMAIL_1]", output[0].Content)
+	assert.Equal(t, 1, mapping.Len())
+
+	// the message modifier still redacts the messages, though the output can't be restored
+	output = r.MessageModifier()(ctx, input)
+	assert.Equal(t, "write to [EMAIL_1]", output[0].Content)
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package httpserve exposes a compiled compose.Runnable as an http.Handler.
+package httpserve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+const (
+	// DefaultCheckPointIDHeader is the default request header carrying the checkpoint id.
+	DefaultCheckPointIDHeader = "X-Eino-Checkpoint-ID"
+
+	defaultMaxRequestBodyBytes = 4 << 20
+)
+
+// Server-Sent Events names used by the stream mode.
+const (
+	// EventChunk carries one chunk of the runnable output.
+	EventChunk = "chunk"
+	// EventDone is the terminal event of a successful run.
+	EventDone = "done"
+	// EventInterrupt is the terminal event of an interrupted run, its data is a Response without output.
+	EventInterrupt = "interrupt"
+	// EventError is the terminal event of a failed run, its data is a Response with Error set.
+	EventError = "error"
+)
+
+// Config is the config for the runnable http handler.
+type Config struct {
+	// CheckPointIDHeader is the request header carrying the checkpoint id used to persist and resume a run.
+	// The runnable must be compiled with compose.WithCheckPointStore for the checkpoint id to take effect.
+	// Optional. Default is DefaultCheckPointIDHeader.
+	CheckPointIDHeader string
+	// GenCheckPointID generates a checkpoint id for requests that don't carry one,
+	// so that an interrupted run can be resumed with the id returned in the response.
+	// Optional. By default, requests without checkpoint id run without checkpoint.
+	GenCheckPointID func(r *http.Request) string
+	// CallOptions returns the call options for a single request, e.g. compose.WithCallbacks for request scoped tracing.
+	// Returning an error rejects the request with http.StatusBadRequest.
+	// Optional.
+	CallOptions func(r *http.Request) ([]compose.Option, error)
+	// MaxRequestBodyBytes limits the size of the request body.
+	// Optional. Default is 4MB.
+	MaxRequestBodyBytes int64
+}
+
+// Response is the JSON response of invoke mode, it's also the data of the terminal interrupt and error events in stream mode.
+type Response[O any] struct {
+	Output       O                      `json:"output,omitempty"`
+	Interrupt    *compose.InterruptInfo `json:"interrupt,omitempty"`
+	CheckPointID string                 `json:"checkpoint_id,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// NewHandler creates an http.Handler that serves the runnable.
+// The request body is the JSON encoded input of the runnable, an empty body means the zero value of I,
+// which is useful when resuming an interrupted run from checkpoint.
+// Requests with 'Accept: text/event-stream' or query 'stream=true' are served by Runnable.Stream as Server-Sent Events,
+// with one EventChunk per output chunk followed by one terminal EventDone, EventInterrupt or EventError.
+// Other requests are served by Runnable.Invoke, and answered with a JSON encoded Response.
+// e.g.
+//
+//	r, err := graph.Compile(ctx, compose.WithCheckPointStore(store))
+//	if err != nil {...}
+//	http.Handle("/agent", httpserve.NewHandler(r, &httpserve.Config{}))
+func NewHandler[I, O any](r compose.Runnable[I, O], config *Config) http.Handler {
+	if config == nil {
+		config = &Config{}
+	}
+
+	h := &handler[I, O]{
+		r:                   r,
+		checkPointIDHeader:  config.CheckPointIDHeader,
+		genCheckPointID:     config.GenCheckPointID,
+		callOptions:         config.CallOptions,
+		maxRequestBodyBytes: config.MaxRequestBodyBytes,
+	}
+
+	if len(h.checkPointIDHeader) == 0 {
+		h.checkPointIDHeader = DefaultCheckPointIDHeader
+	}
+
+	if h.maxRequestBodyBytes <= 0 {
+		h.maxRequestBodyBytes = defaultMaxRequestBodyBytes
+	}
+
+	return h
+}
+
+type handler[I, O any] struct {
+	r compose.Runnable[I, O]
+
+	checkPointIDHeader  string
+	genCheckPointID     func(r *http.Request) string
+	callOptions         func(r *http.Request) ([]compose.Option, error)
+	maxRequestBodyBytes int64
+}
+
+func (h *handler[I, O]) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, &Response[O]{Error: "method not allowed"})
+		return
+	}
+
+	input, err := h.decodeInput(w, req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &Response[O]{Error: err.Error()})
+		return
+	}
+
+	var opts []compose.Option
+	if h.callOptions != nil {
+		opts, err = h.callOptions(req)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, &Response[O]{Error: err.Error()})
+			return
+		}
+	}
+
+	checkPointID := req.Header.Get(h.checkPointIDHeader)
+	if len(checkPointID) == 0 && h.genCheckPointID != nil {
+		checkPointID = h.genCheckPointID(req)
+	}
+	if len(checkPointID) > 0 {
+		opts = append(opts, compose.WithCheckPointID(checkPointID))
+		w.Header().Set(h.checkPointIDHeader, checkPointID)
+	}
+
+	if isStreamRequest(req) {
+		h.serveStream(req.Context(), w, input, checkPointID, opts...)
+		return
+	}
+
+	h.serveInvoke(req.Context(), w, input, checkPointID, opts...)
+}
+
+func (h *handler[I, O]) decodeInput(w http.ResponseWriter, req *http.Request) (I, error) {
+	var input I
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, h.maxRequestBodyBytes))
+	if err != nil {
+		return input, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return input, nil
+	}
+
+	if err = sonic.Unmarshal(body, &input); err != nil {
+		return input, fmt.Errorf("failed to unmarshal request body: %w", err)
+	}
+
+	return input, nil
+}
+
+func (h *handler[I, O]) serveInvoke(ctx context.Context, w http.ResponseWriter, input I, checkPointID string, opts ...compose.Option) {
+	output, err := h.r.Invoke(ctx, input, opts...)
+	if err != nil {
+		if info, ok := compose.ExtractInterruptInfo(err); ok {
+			writeJSON(w, http.StatusOK, &Response[O]{Interrupt: info, CheckPointID: checkPointID})
+			return
+		}
+
+		writeJSON(w, http.StatusInternalServerError, &Response[O]{Error: err.Error(), CheckPointID: checkPointID})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &Response[O]{Output: output, CheckPointID: checkPointID})
+}
+
+func (h *handler[I, O]) serveStream(ctx context.Context, w http.ResponseWriter, input I, checkPointID string, opts ...compose.Option) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	sw := newEventWriter(w)
+
+	sr, err := h.r.Stream(ctx, input, opts...)
+	if err != nil {
+		_ = sw.writeTerminal(err, checkPointID)
+		return
+	}
+	defer sr.Close()
+
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			_ = sw.write(EventDone, []byte("{}"))
+			return
+		}
+		if err != nil {
+			_ = sw.writeTerminal(err, checkPointID)
+			return
+		}
+
+		data, err := sonic.Marshal(chunk)
+		if err != nil {
+			_ = sw.writeTerminal(fmt.Errorf("failed to marshal output chunk: %w", err), checkPointID)
+			return
+		}
+
+		if err = sw.write(EventChunk, data); err != nil {
+			return // client has gone away
+		}
+	}
+}
+
+func isStreamRequest(req *http.Request) bool {
+	if strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+
+	return req.URL.Query().Get("stream") == "true"
+}
+
+func writeJSON[O any](w http.ResponseWriter, status int, resp *Response[O]) {
+	data, err := sonic.Marshal(resp)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = sonic.Marshal(&Response[O]{Error: fmt.Sprintf("failed to marshal response: %v", err)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+type eventWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func newEventWriter(w http.ResponseWriter) *eventWriter {
+	flusher, _ := w.(http.Flusher)
+	return &eventWriter{w: w, flusher: flusher}
+}
+
+func (e *eventWriter) write(event string, data []byte) error {
+	if _, err := fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+
+	return nil
+}
+
+// writeTerminal writes the terminal event of a failed or interrupted run.
+func (e *eventWriter) writeTerminal(err error, checkPointID string) error {
+	event := EventError
+	resp := &Response[any]{CheckPointID: checkPointID}
+	if info, ok := compose.ExtractInterruptInfo(err); ok {
+		event = EventInterrupt
+		resp.Interrupt = info
+	} else {
+		resp.Error = err.Error()
+	}
+
+	data, mErr := sonic.Marshal(resp)
+	if mErr != nil {
+		event = EventError
+		data, _ = sonic.Marshal(&Response[any]{Error: fmt.Sprintf("failed to marshal response: %v", mErr)})
+	}
+
+	return e.write(event, data)
+}
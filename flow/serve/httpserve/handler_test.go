@@ -0,0 +1,232 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpserve
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+type inMemoryStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (i *inMemoryStore) Get(_ context.Context, checkPointID string) ([]byte, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	v, ok := i.m[checkPointID]
+	return v, ok, nil
+}
+
+func (i *inMemoryStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.m[checkPointID] = checkPoint
+	return nil
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func readEvents(t *testing.T, resp *http.Response) []sseEvent {
+	var (
+		events []sseEvent
+		cur    sseEvent
+	)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			cur.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, cur)
+			cur = sseEvent{}
+		}
+	}
+	assert.NoError(t, scanner.Err())
+
+	return events
+}
+
+func newUpperRunnable(t *testing.T) compose.Runnable[string, string] {
+	g := compose.NewGraph[string, string]()
+	err := g.AddLambdaNode("upper", compose.StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+		return schema.StreamReaderFromArray(strings.Split(strings.ToUpper(input), " ")), nil
+	}))
+	assert.NoError(t, err)
+	assert.NoError(t, g.AddEdge(compose.START, "upper"))
+	assert.NoError(t, g.AddEdge("upper", compose.END))
+
+	r, err := g.Compile(context.Background())
+	assert.NoError(t, err)
+	return r
+}
+
+func TestInvoke(t *testing.T) {
+	var started int
+	cb := callbacks.NewHandlerBuilder().OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+		started++
+		return ctx
+	}).Build()
+
+	srv := httptest.NewServer(NewHandler(newUpperRunnable(t), &Config{
+		CallOptions: func(r *http.Request) ([]compose.Option, error) {
+			return []compose.Option{compose.WithCallbacks(cb)}, nil
+		},
+	}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`"hello world"`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out Response[string]
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&out))
+	assert.Equal(t, "HELLOWORLD", out.Output)
+	assert.Empty(t, out.Error)
+	assert.True(t, started > 0)
+
+	resp2, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp2.StatusCode)
+
+	resp3, err := http.Post(srv.URL, "application/json", strings.NewReader(`{`))
+	assert.NoError(t, err)
+	defer resp3.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp3.StatusCode)
+}
+
+func TestStream(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(newUpperRunnable(t), nil))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`"a b c"`))
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := readEvents(t, resp)
+	assert.Equal(t, []sseEvent{
+		{name: EventChunk, data: `"A"`},
+		{name: EventChunk, data: `"B"`},
+		{name: EventChunk, data: `"C"`},
+		{name: EventDone, data: `{}`},
+	}, events)
+}
+
+func TestInterruptAndResume(t *testing.T) {
+	ctx := context.Background()
+
+	g := compose.NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("1", compose.InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "1", nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("2", compose.InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "2", nil
+	})))
+	assert.NoError(t, g.AddEdge(compose.START, "1"))
+	assert.NoError(t, g.AddEdge("1", "2"))
+	assert.NoError(t, g.AddEdge("2", compose.END))
+
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(&inMemoryStore{m: map[string][]byte{}}),
+		compose.WithInterruptBeforeNodes([]string{"2"}))
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(r, &Config{
+		GenCheckPointID: func(r *http.Request) string { return "generated" },
+	}))
+	defer srv.Close()
+
+	// invoke: interrupted before node 2, the generated checkpoint id is returned
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`"start"`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "generated", resp.Header.Get(DefaultCheckPointIDHeader))
+
+	var out Response[string]
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&out))
+	assert.Equal(t, "generated", out.CheckPointID)
+	if assert.NotNil(t, out.Interrupt) {
+		assert.Equal(t, []string{"2"}, out.Interrupt.BeforeNodes)
+	}
+
+	// resume with the checkpoint id and an empty body
+	req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set(DefaultCheckPointIDHeader, "generated")
+	resp2, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+
+	out = Response[string]{}
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp2.Body).Decode(&out))
+	assert.Nil(t, out.Interrupt)
+	assert.Equal(t, "start12", out.Output)
+
+	// stream: interrupt is the terminal event
+	req, err = http.NewRequest(http.MethodPost, srv.URL+"?stream=true", strings.NewReader(`"start"`))
+	assert.NoError(t, err)
+	req.Header.Set(DefaultCheckPointIDHeader, "stream")
+	resp3, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp3.Body.Close()
+
+	events := readEvents(t, resp3)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, EventInterrupt, events[0].name)
+		assert.Contains(t, events[0].data, `"checkpoint_id":"stream"`)
+	}
+
+	req, err = http.NewRequest(http.MethodPost, srv.URL+"?stream=true", nil)
+	assert.NoError(t, err)
+	req.Header.Set(DefaultCheckPointIDHeader, "stream")
+	resp4, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp4.Body.Close()
+
+	events = readEvents(t, resp4)
+	assert.Equal(t, []sseEvent{
+		{name: EventChunk, data: `"start12"`},
+		{name: EventDone, data: `{}`},
+	}, events)
+}
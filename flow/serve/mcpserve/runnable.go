@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcpserve
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+const stringRunnableInputKey = "input"
+
+// NewStringRunnableTool publishes a Runnable[string, string] as a tool with a single string argument named "input".
+// The tool is both invokable and streamable, so the streamed output can be sent as progress notifications.
+func NewStringRunnableTool(name, desc string, r compose.Runnable[string, string], opts ...compose.Option) tool.BaseTool {
+	return &stringRunnableTool{
+		info: &schema.ToolInfo{
+			Name: name,
+			Desc: desc,
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				stringRunnableInputKey: {
+					Type:     schema.String,
+					Desc:     "the input of " + name,
+					Required: true,
+				},
+			}),
+		},
+		r:    r,
+		opts: opts,
+	}
+}
+
+type stringRunnableTool struct {
+	info *schema.ToolInfo
+	r    compose.Runnable[string, string]
+	opts []compose.Option
+}
+
+func (s *stringRunnableTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return s.info, nil
+}
+
+func (s *stringRunnableTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	input, err := s.input(argumentsInJSON)
+	if err != nil {
+		return "", err
+	}
+
+	return s.r.Invoke(ctx, input, s.opts...)
+}
+
+func (s *stringRunnableTool) StreamableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (*schema.StreamReader[string], error) {
+	input, err := s.input(argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.r.Stream(ctx, input, s.opts...)
+}
+
+func (s *stringRunnableTool) input(argumentsInJSON string) (string, error) {
+	args := map[string]string{}
+	if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+		return "", &tool.InvalidArgumentsError{Err: fmt.Errorf("failed to unmarshal arguments of %s: %w", s.info.Name, err)}
+	}
+
+	return args[stringRunnableInputKey], nil
+}
+
+// NewMapRunnableTool publishes a Runnable[map[string]any, X] as a tool described by info,
+// the arguments object is passed to the runnable as it is, and the output is returned as it is if X is string,
+// or marshaled to JSON otherwise.
+func NewMapRunnableTool[X any](info *schema.ToolInfo, r compose.Runnable[map[string]any, X], opts ...compose.Option) tool.BaseTool {
+	return &mapRunnableTool[X]{
+		info: info,
+		r:    r,
+		opts: opts,
+	}
+}
+
+type mapRunnableTool[X any] struct {
+	info *schema.ToolInfo
+	r    compose.Runnable[map[string]any, X]
+	opts []compose.Option
+}
+
+func (m *mapRunnableTool[X]) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return m.info, nil
+}
+
+func (m *mapRunnableTool[X]) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	input := map[string]any{}
+	if err := sonic.UnmarshalString(argumentsInJSON, &input); err != nil {
+		return "", &tool.InvalidArgumentsError{Err: fmt.Errorf("failed to unmarshal arguments of %s: %w", m.info.Name, err)}
+	}
+
+	output, err := m.r.Invoke(ctx, input, m.opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if str, ok := any(output).(string); ok {
+		return str, nil
+	}
+
+	return sonic.MarshalString(output)
+}
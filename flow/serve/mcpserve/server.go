@@ -0,0 +1,454 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mcpserve publishes eino tools to Model Context Protocol (MCP) clients.
+package mcpserve
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/internal/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	defaultServerName    = "eino"
+	defaultServerVersion = "v0.0.1"
+
+	defaultMaxRequestBodyBytes = 4 << 20
+)
+
+// Config is the config for the MCP server.
+type Config struct {
+	// Name and Version identify the server to clients.
+	// Optional. Default is "eino".
+	Name    string
+	Version string
+	// Instructions tells the clients how to use the server.
+	// Optional.
+	Instructions string
+
+	// Tools are the tools published by the server, each must implement tool.InvokableTool or tool.StreamableTool.
+	Tools []tool.BaseTool
+
+	// StreamProgress sends each chunk of a streamable tool as a progress notification,
+	// when the client asks for progress by setting a progress token in the tools/call request.
+	// The final result always holds the aggregated output.
+	// Optional. Default is false, which only aggregates the streamed output.
+	StreamProgress bool
+}
+
+// Server is an MCP server publishing eino tools.
+// It speaks the stdio transport through ServeStdio, and the streamable HTTP transport as an http.Handler.
+type Server struct {
+	name         string
+	version      string
+	instructions string
+
+	streamProgress bool
+
+	tools     map[string]tool.BaseTool
+	toolInfos []*mcp.Tool
+}
+
+// NewServer creates an MCP server, the tools/list response is built from the Info of the tools.
+// e.g.
+//
+//	srv, err := mcpserve.NewServer(ctx, &mcpserve.Config{Tools: []tool.BaseTool{searchTool}})
+//	if err != nil {...}
+//	http.Handle("/mcp", srv)
+//	// or, for a server launched by the client as a subprocess:
+//	err = srv.ServeStdio(ctx, os.Stdin, os.Stdout)
+func NewServer(ctx context.Context, config *Config) (*Server, error) {
+	if config == nil {
+		return nil, errors.New("mcp server config is nil")
+	}
+
+	s := &Server{
+		name:           config.Name,
+		version:        config.Version,
+		instructions:   config.Instructions,
+		streamProgress: config.StreamProgress,
+		tools:          make(map[string]tool.BaseTool, len(config.Tools)),
+		toolInfos:      make([]*mcp.Tool, 0, len(config.Tools)),
+	}
+
+	if len(s.name) == 0 {
+		s.name = defaultServerName
+	}
+	if len(s.version) == 0 {
+		s.version = defaultServerVersion
+	}
+
+	for idx, t := range config.Tools {
+		_, invokable := t.(tool.InvokableTool)
+		_, streamable := t.(tool.StreamableTool)
+		if !invokable && !streamable {
+			return nil, fmt.Errorf("tool at idx= %d is not invokable or streamable", idx)
+		}
+
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tool info at idx= %d: %w", idx, err)
+		}
+
+		if _, ok := s.tools[info.Name]; ok {
+			return nil, fmt.Errorf("duplicate tool name: %s", info.Name)
+		}
+
+		inputSchema, err := toInputSchema(info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert params of tool %s: %w", info.Name, err)
+		}
+
+		s.tools[info.Name] = t
+		s.toolInfos = append(s.toolInfos, &mcp.Tool{
+			Name:        info.Name,
+			Description: info.Desc,
+			InputSchema: inputSchema,
+		})
+	}
+
+	return s, nil
+}
+
+func toInputSchema(info *schema.ToolInfo) ([]byte, error) {
+	sc, err := info.ToOpenAPIV3()
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return []byte(`{"type":"object"}`), nil
+	}
+
+	return sonic.Marshal(sc)
+}
+
+// notifier sends a notification to the client within the handling of a request.
+type notifier func(msg *mcp.Message) error
+
+// handle handles one message from client, and returns the response, which is nil for notifications and responses.
+func (s *Server) handle(ctx context.Context, msg *mcp.Message, notify notifier) *mcp.Message {
+	if !msg.IsRequest() {
+		return nil
+	}
+
+	var (
+		result any
+		rpcErr *mcp.Error
+	)
+
+	switch msg.Method {
+	case mcp.MethodInitialize:
+		result = &mcp.InitializeResult{
+			ProtocolVersion: mcp.ProtocolVersion,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      mcp.Implementation{Name: s.name, Version: s.version},
+			Instructions:    s.instructions,
+		}
+	case mcp.MethodPing:
+		result = struct{}{}
+	case mcp.MethodToolsList:
+		result = &mcp.ListToolsResult{Tools: s.toolInfos}
+	case mcp.MethodToolsCall:
+		result, rpcErr = s.callTool(ctx, msg.Params, notify)
+	default:
+		rpcErr = &mcp.Error{Code: mcp.CodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	resp := &mcp.Message{JSONRPC: mcp.JSONRPCVersion, ID: msg.ID}
+	if rpcErr != nil {
+		resp.Error = rpcErr
+		return resp
+	}
+
+	data, err := sonic.Marshal(result)
+	if err != nil {
+		resp.Error = &mcp.Error{Code: mcp.CodeInternalError, Message: fmt.Sprintf("failed to marshal result: %v", err)}
+		return resp
+	}
+	resp.Result = data
+
+	return resp
+}
+
+func (s *Server) callTool(ctx context.Context, rawParams []byte, notify notifier) (*mcp.CallToolResult, *mcp.Error) {
+	params := &mcp.CallToolParams{}
+	if err := sonic.Unmarshal(rawParams, params); err != nil {
+		return nil, &mcp.Error{Code: mcp.CodeInvalidParams, Message: fmt.Sprintf("invalid tools/call params: %v", err)}
+	}
+
+	t, ok := s.tools[params.Name]
+	if !ok {
+		return nil, &mcp.Error{Code: mcp.CodeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	arguments := string(params.Arguments)
+	if len(arguments) == 0 || arguments == "null" {
+		arguments = "{}"
+	}
+
+	var progressToken []byte
+	if params.Meta != nil && len(params.Meta.ProgressToken) > 0 && s.streamProgress && notify != nil {
+		progressToken = params.Meta.ProgressToken
+	}
+
+	output, err := s.runTool(ctx, t, arguments, progressToken, notify)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []*mcp.Content{{Type: "text", Text: err.Error()}},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{Content: []*mcp.Content{{Type: "text", Text: output}}}, nil
+}
+
+// runTool prefers InvokableRun, unless progress is asked and the tool is streamable.
+func (s *Server) runTool(ctx context.Context, t tool.BaseTool, arguments string, progressToken []byte, notify notifier) (string, error) {
+	it, invokable := t.(tool.InvokableTool)
+	st, streamable := t.(tool.StreamableTool)
+
+	if invokable && (!streamable || len(progressToken) == 0) {
+		return it.InvokableRun(ctx, arguments)
+	}
+
+	sr, err := st.StreamableRun(ctx, arguments)
+	if err != nil {
+		return "", err
+	}
+	defer sr.Close()
+
+	var sb strings.Builder
+	for i := 1; ; i++ {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(chunk)
+
+		if len(progressToken) == 0 {
+			continue
+		}
+
+		params, err := sonic.Marshal(&mcp.ProgressParams{
+			ProgressToken: progressToken,
+			Progress:      float64(i),
+			Message:       chunk,
+		})
+		if err != nil {
+			return "", err
+		}
+		_ = notify(&mcp.Message{JSONRPC: mcp.JSONRPCVersion, Method: mcp.NotifyProgress, Params: params})
+	}
+}
+
+// ServeStdio serves newline delimited JSON-RPC messages read from in, writing the responses to out,
+// until in reaches EOF or ctx is done. Requests are handled concurrently.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	var (
+		writeMu sync.Mutex
+		wg      sync.WaitGroup
+	)
+
+	write := func(msg *mcp.Message) error {
+		data, err := sonic.Marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err = out.Write(append(data, '\n'))
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case line := <-lines:
+			msg := &mcp.Message{}
+			if err := sonic.Unmarshal(line, msg); err != nil {
+				_ = write(&mcp.Message{
+					JSONRPC: mcp.JSONRPCVersion,
+					ID:      []byte("null"),
+					Error:   &mcp.Error{Code: mcp.CodeParseError, Message: err.Error()},
+				})
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if resp := s.handle(ctx, msg, write); resp != nil {
+					_ = write(resp)
+				}
+			}()
+		}
+	}
+}
+
+// ServeHTTP serves the streamable HTTP transport, every client message is POSTed as a single JSON-RPC message.
+// Responses are sent as JSON, or as Server-Sent Events when the client accepts them and asks for progress of a streamable tool.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, defaultMaxRequestBodyBytes))
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, mcp.CodeInvalidRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	msg := &mcp.Message{}
+	if err = sonic.Unmarshal(body, msg); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, mcp.CodeParseError, err.Error())
+		return
+	}
+
+	if !msg.IsRequest() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if msg.Method == mcp.MethodInitialize {
+		w.Header().Set(mcp.SessionIDHeader, newSessionID())
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") && s.wantsProgress(msg) {
+		s.serveEventStream(w, r, msg)
+		return
+	}
+
+	data, err := sonic.Marshal(s.handle(r.Context(), msg, nil))
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, mcp.CodeInternalError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) wantsProgress(msg *mcp.Message) bool {
+	if !s.streamProgress || msg.Method != mcp.MethodToolsCall {
+		return false
+	}
+
+	params := &mcp.CallToolParams{}
+	if err := sonic.Unmarshal(msg.Params, params); err != nil {
+		return false
+	}
+
+	return params.Meta != nil && len(params.Meta.ProgressToken) > 0
+}
+
+func (s *Server) serveEventStream(w http.ResponseWriter, r *http.Request, msg *mcp.Message) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	write := func(m *mcp.Message) error {
+		data, err := sonic.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	_ = write(s.handle(r.Context(), msg, write))
+}
+
+func writeHTTPError(w http.ResponseWriter, status, code int, message string) {
+	data, _ := sonic.Marshal(&mcp.Message{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      []byte("null"),
+		Error:   &mcp.Error{Code: code, Message: message},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
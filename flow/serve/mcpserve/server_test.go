@@ -0,0 +1,191 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcpserve
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/tool"
+	mcptool "github.com/cloudwego/eino/components/tool/mcp"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/internal/mcp"
+	"github.com/cloudwego/eino/schema"
+)
+
+type addInput struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func newTestServer(t *testing.T) *Server {
+	ctx := context.Background()
+
+	add, err := utils.InferTool("add", "add two numbers", func(ctx context.Context, in *addInput) (int, error) {
+		if in.A < 0 {
+			return 0, errors.New("negative number")
+		}
+		return in.A + in.B, nil
+	})
+	assert.NoError(t, err)
+
+	lambda := compose.StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+		return schema.StreamReaderFromArray(strings.Split(strings.ToUpper(input), "")), nil
+	})
+	r, err := compose.NewChain[string, string]().AppendLambda(lambda).Compile(ctx)
+	assert.NoError(t, err)
+
+	mr, err := compose.NewChain[map[string]any, map[string]any]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in map[string]any) (map[string]any, error) {
+			return map[string]any{"echo": in["text"]}, nil
+		})).Compile(ctx)
+	assert.NoError(t, err)
+
+	srv, err := NewServer(ctx, &Config{
+		Name:           "test",
+		StreamProgress: true,
+		Tools: []tool.BaseTool{
+			add,
+			NewStringRunnableTool("upper", "upper case the input", r),
+			NewMapRunnableTool(&schema.ToolInfo{Name: "echo", Desc: "echo text"}, mr),
+		},
+	})
+	assert.NoError(t, err)
+
+	return srv
+}
+
+func testWithClient(t *testing.T, transport mcptool.Transport) {
+	ctx := context.Background()
+
+	cli, err := mcptool.NewClient(ctx, &mcptool.ClientConfig{Transport: transport})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", cli.ServerName())
+	defer cli.Close()
+
+	tools, err := mcptool.GetTools(ctx, &mcptool.Config{Client: cli})
+	assert.NoError(t, err)
+	assert.Len(t, tools, 3)
+
+	info, err := tools[0].Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "add", info.Name)
+	sc, err := info.ToOpenAPIV3()
+	assert.NoError(t, err)
+	assert.Contains(t, sc.Properties, "a")
+
+	out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"a":1,"b":2}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", out)
+
+	_, err = tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"a":-1,"b":2}`)
+	var toolErr *mcptool.ToolError
+	assert.ErrorAs(t, err, &toolErr)
+	assert.Contains(t, toolErr.Content, "negative number")
+
+	out, err = tools[1].(tool.InvokableTool).InvokableRun(ctx, `{"input":"abc"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABC", out)
+
+	out, err = tools[2].(tool.InvokableTool).InvokableRun(ctx, `{"text":"hi"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"echo":"hi"}`, out)
+}
+
+func TestServeStdio(t *testing.T) {
+	srv := newTestServer(t)
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.ServeStdio(context.Background(), serverR, serverW)
+		_ = serverW.Close()
+	}()
+
+	testWithClient(t, mcptool.NewIOTransport(clientR, clientW))
+	assert.NoError(t, <-done)
+}
+
+func TestServeHTTP(t *testing.T) {
+	httpSrv := httptest.NewServer(newTestServer(t))
+	defer httpSrv.Close()
+
+	transport, err := mcptool.NewHTTPTransport(&mcptool.HTTPTransportConfig{Endpoint: httpSrv.URL})
+	assert.NoError(t, err)
+	testWithClient(t, transport)
+}
+
+func TestProgressNotifications(t *testing.T) {
+	httpSrv := httptest.NewServer(newTestServer(t))
+	defer httpSrv.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"upper","arguments":{"input":"ab"},"_meta":{"progressToken":"tk"}}}`
+	req, err := http.NewRequest(http.MethodPost, httpSrv.URL, strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var msgs []*mcp.Message
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data := strings.TrimPrefix(scanner.Text(), "data: "); data != scanner.Text() {
+			msg := &mcp.Message{}
+			assert.NoError(t, sonic.UnmarshalString(data, msg))
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if !assert.Len(t, msgs, 3) {
+		return
+	}
+
+	progress := &mcp.ProgressParams{}
+	assert.Equal(t, mcp.NotifyProgress, msgs[0].Method)
+	assert.NoError(t, sonic.Unmarshal(msgs[0].Params, progress))
+	assert.Equal(t, `"tk"`, string(progress.ProgressToken))
+	assert.Equal(t, "A", progress.Message)
+
+	assert.True(t, msgs[2].IsResponse())
+	result := &mcp.CallToolResult{}
+	assert.NoError(t, sonic.Unmarshal(msgs[2].Result, result))
+	assert.Equal(t, "AB", result.Content[0].Text)
+
+	// unknown tool is a jsonrpc error
+	resp2, err := http.Post(httpSrv.URL, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing"}}`))
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+	msg := &mcp.Message{}
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp2.Body).Decode(msg))
+	assert.Equal(t, mcp.CodeInvalidParams, msg.Error.Code)
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToSchemaMessages converts the messages of a chat completions request to schema messages.
+func ToSchemaMessages(msgs []*ChatMessage) ([]*schema.Message, error) {
+	ret := make([]*schema.Message, 0, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+
+		var role schema.RoleType
+		switch msg.Role {
+		case "system", "developer":
+			role = schema.System
+		case "user":
+			role = schema.User
+		case "assistant":
+			role = schema.Assistant
+		case "tool":
+			role = schema.Tool
+		default:
+			return nil, fmt.Errorf("unsupported role of message at index %d: %q", i, msg.Role)
+		}
+
+		m := &schema.Message{
+			Role:       role,
+			Content:    msg.Content.Text,
+			Name:       msg.Name,
+			ToolCallID: msg.ToolCallID,
+		}
+
+		for _, part := range msg.Content.Parts {
+			if part == nil {
+				return nil, fmt.Errorf("content part of message at index %d is null", i)
+			}
+			switch part.Type {
+			case "text":
+				m.MultiContent = append(m.MultiContent, schema.ChatMessagePart{
+					Type: schema.ChatMessagePartTypeText,
+					Text: part.Text,
+				})
+			case "image_url":
+				if part.ImageURL == nil {
+					return nil, fmt.Errorf("image_url content part of message at index %d has no image_url", i)
+				}
+				m.MultiContent = append(m.MultiContent, schema.ChatMessagePart{
+					Type: schema.ChatMessagePartTypeImageURL,
+					ImageURL: &schema.ChatMessageImageURL{
+						URL:    part.ImageURL.URL,
+						Detail: schema.ImageURLDetail(part.ImageURL.Detail),
+					},
+				})
+			default:
+				return nil, fmt.Errorf("unsupported content part type of message at index %d: %q", i, part.Type)
+			}
+		}
+
+		for _, tc := range msg.ToolCalls {
+			if tc == nil || tc.Function == nil {
+				continue
+			}
+			typ := tc.Type
+			if len(typ) == 0 {
+				typ = "function"
+			}
+			m.ToolCalls = append(m.ToolCalls, schema.ToolCall{
+				Index: tc.Index,
+				ID:    tc.ID,
+				Type:  typ,
+				Function: schema.FunctionCall{
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				},
+			})
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret, nil
+}
+
+// FromSchemaMessage converts a schema message to a message of chat completions response.
+func FromSchemaMessage(msg *schema.Message) *ChatMessage {
+	if msg == nil {
+		return nil
+	}
+
+	ret := &ChatMessage{
+		Role:       string(msg.Role),
+		Content:    MessageContent{Text: msg.Content},
+		Name:       msg.Name,
+		ToolCallID: msg.ToolCallID,
+	}
+
+	for i := range msg.ToolCalls {
+		ret.ToolCalls = append(ret.ToolCalls, toWireToolCall(msg.ToolCalls[i]))
+	}
+
+	return ret
+}
+
+func toWireToolCall(tc schema.ToolCall) *ToolCall {
+	typ := tc.Type
+	if len(typ) == 0 {
+		typ = "function"
+	}
+
+	return &ToolCall{
+		Index: tc.Index,
+		ID:    tc.ID,
+		Type:  typ,
+		Function: &FunctionCall{
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		},
+	}
+}
+
+// ToToolInfos converts the tools of a chat completions request to schema tool infos.
+func ToToolInfos(tools []*Tool) ([]*schema.ToolInfo, error) {
+	ret := make([]*schema.ToolInfo, 0, len(tools))
+	for _, t := range tools {
+		if t == nil || t.Function == nil {
+			continue
+		}
+
+		info := &schema.ToolInfo{
+			Name: t.Function.Name,
+			Desc: t.Function.Description,
+		}
+
+		if len(t.Function.Parameters) > 0 {
+			sc := &openapi3.Schema{}
+			if err := sonic.Unmarshal(t.Function.Parameters, sc); err != nil {
+				return nil, fmt.Errorf("invalid parameters of tool %s: %w", t.Function.Name, err)
+			}
+			info.ParamsOneOf = schema.NewParamsOneOfByOpenAPIV3(sc)
+		}
+
+		ret = append(ret, info)
+	}
+
+	return ret, nil
+}
+
+func toModelOptions(req *ChatCompletionRequest, ignoreTools bool) ([]model.Option, error) {
+	var opts []model.Option
+
+	if req.Temperature != nil {
+		opts = append(opts, model.WithTemperature(*req.Temperature))
+	}
+	if req.TopP != nil {
+		opts = append(opts, model.WithTopP(*req.TopP))
+	}
+	if req.MaxTokens != nil {
+		opts = append(opts, model.WithMaxTokens(*req.MaxTokens))
+	}
+	if len(req.Stop) > 0 {
+		opts = append(opts, model.WithStop(req.Stop))
+	}
+
+	if ignoreTools {
+		return opts, nil
+	}
+
+	tools, err := ToToolInfos(req.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	toolChoice, forcedName, err := parseToolChoice(req.ToolChoice)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(forcedName) > 0 {
+		var forced []*schema.ToolInfo
+		for _, t := range tools {
+			if t.Name == forcedName {
+				forced = append(forced, t)
+			}
+		}
+		if len(forced) == 0 {
+			return nil, fmt.Errorf("tool_choice refers to unknown tool: %s", forcedName)
+		}
+		tools = forced
+	}
+
+	if len(tools) > 0 {
+		opts = append(opts, model.WithTools(tools))
+	}
+	if toolChoice != nil {
+		opts = append(opts, model.WithToolChoice(*toolChoice))
+	}
+
+	return opts, nil
+}
+
+// parseToolChoice parses tool_choice, which is either "none", "auto", "required" or a named function.
+func parseToolChoice(raw []byte) (choice *schema.ToolChoice, forcedName string, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, "", nil
+	}
+
+	var str string
+	if err = sonic.Unmarshal(raw, &str); err == nil {
+		var tc schema.ToolChoice
+		switch str {
+		case "none":
+			tc = schema.ToolChoiceForbidden
+		case "auto":
+			tc = schema.ToolChoiceAllowed
+		case "required":
+			tc = schema.ToolChoiceForced
+		default:
+			return nil, "", fmt.Errorf("unsupported tool_choice: %q", str)
+		}
+		return &tc, "", nil
+	}
+
+	var named struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err = sonic.Unmarshal(raw, &named); err != nil {
+		return nil, "", fmt.Errorf("invalid tool_choice: %w", err)
+	}
+	if len(named.Function.Name) == 0 {
+		return nil, "", fmt.Errorf("invalid tool_choice: function name is empty")
+	}
+
+	tc := schema.ToolChoiceForced
+	return &tc, named.Function.Name, nil
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openai exposes a chat Runnable, such as a compiled react agent or host multi-agent graph,
+// behind the OpenAI compatible chat completions protocol.
+package openai
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	objectChatCompletion      = "chat.completion"
+	objectChatCompletionChunk = "chat.completion.chunk"
+
+	finishReasonStop      = "stop"
+	finishReasonToolCalls = "tool_calls"
+
+	defaultMaxRequestBodyBytes = 4 << 20
+)
+
+// Config is the config for the chat completions handler.
+type Config struct {
+	// Model is the model name reported in responses, when the request doesn't specify one.
+	// Optional.
+	Model string
+	// ForwardModelTo is the paths of the chat model nodes that the model of the request is passed to as model.WithModel.
+	// Optional. By default, the model of the request is regarded as the name of the served runnable,
+	// which is only echoed in responses and not passed to any chat model.
+	ForwardModelTo []*compose.NodePath
+	// CallOptions returns extra call options for a single request, e.g. compose.WithCallbacks.
+	// Returning an error rejects the request with http.StatusBadRequest.
+	// Optional.
+	CallOptions func(r *http.Request, req *ChatCompletionRequest) ([]compose.Option, error)
+	// IgnoreRequestTools drops the tools and tool_choice of requests instead of passing them to the chat models,
+	// which is usually wanted when the runnable is an agent that binds its own tools.
+	// Optional. Default is false.
+	IgnoreRequestTools bool
+	// MaxRequestBodyBytes limits the size of the request body.
+	// Optional. Default is 4MB.
+	MaxRequestBodyBytes int64
+}
+
+// NewHandler creates an http.Handler speaking the OpenAI chat completions protocol in front of the runnable.
+// It should be mounted on the '/v1/chat/completions' path.
+// The request messages are converted to the runnable input,
+// and tools, tool_choice, temperature, top_p, max_tokens and stop are passed to every chat model in the runnable
+// as model.Option through compose.WithChatModelOption, while model is only passed to the nodes in Config.ForwardModelTo.
+// Stream requests are served by Runnable.Stream, with one 'chat.completion.chunk' event per message chunk, followed by 'data: [DONE]'.
+// e.g.
+//
+//	agent, err := react.NewAgent(ctx, config)
+//	if err != nil {...}
+//	agentGraph, agentOpts := agent.ExportGraph()
+//	g := compose.NewGraph[[]*schema.Message, *schema.Message]()
+//	_ = g.AddGraphNode("agent", agentGraph, agentOpts...)
+//	_ = g.AddEdge(compose.START, "agent")
+//	_ = g.AddEdge("agent", compose.END)
+//	r, err := g.Compile(ctx)
+//	if err != nil {...}
+//	http.Handle("/v1/chat/completions", openai.NewHandler(r, &openai.Config{Model: "my-agent", IgnoreRequestTools: true}))
+func NewHandler(r compose.Runnable[[]*schema.Message, *schema.Message], config *Config) http.Handler {
+	if config == nil {
+		config = &Config{}
+	}
+
+	h := &handler{
+		r:                   r,
+		model:               config.Model,
+		forwardModelTo:      config.ForwardModelTo,
+		callOptions:         config.CallOptions,
+		ignoreRequestTools:  config.IgnoreRequestTools,
+		maxRequestBodyBytes: config.MaxRequestBodyBytes,
+	}
+
+	if h.maxRequestBodyBytes <= 0 {
+		h.maxRequestBodyBytes = defaultMaxRequestBodyBytes
+	}
+
+	return h
+}
+
+type handler struct {
+	r compose.Runnable[[]*schema.Message, *schema.Message]
+
+	model               string
+	forwardModelTo      []*compose.NodePath
+	callOptions         func(r *http.Request, req *ChatCompletionRequest) ([]compose.Option, error)
+	ignoreRequestTools  bool
+	maxRequestBodyBytes int64
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxRequestBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	req := &ChatCompletionRequest{}
+	if err = sonic.Unmarshal(body, req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("failed to unmarshal request body: %v", err))
+		return
+	}
+
+	input, err := ToSchemaMessages(req.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	opts, err := h.buildCallOptions(r, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	modelName := req.Model
+	if len(modelName) == 0 {
+		modelName = h.model
+	}
+
+	if req.Stream {
+		h.serveStream(r.Context(), w, req, modelName, input, opts...)
+		return
+	}
+
+	h.serveInvoke(r.Context(), w, modelName, input, opts...)
+}
+
+func (h *handler) buildCallOptions(r *http.Request, req *ChatCompletionRequest) ([]compose.Option, error) {
+	var opts []compose.Option
+
+	modelOpts, err := toModelOptions(req, h.ignoreRequestTools)
+	if err != nil {
+		return nil, err
+	}
+	if len(modelOpts) > 0 {
+		opts = append(opts, compose.WithChatModelOption(modelOpts...))
+	}
+	if len(req.Model) > 0 && len(h.forwardModelTo) > 0 {
+		opts = append(opts, compose.WithChatModelOption(model.WithModel(req.Model)).DesignateNodeWithPath(h.forwardModelTo...))
+	}
+
+	if h.callOptions != nil {
+		extra, err := h.callOptions(r, req)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, extra...)
+	}
+
+	return opts, nil
+}
+
+func (h *handler) serveInvoke(ctx context.Context, w http.ResponseWriter, modelName string,
+	input []*schema.Message, opts ...compose.Option) {
+
+	output, err := h.r.Invoke(ctx, input, opts...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	finishReason := finishReasonStop
+	if len(output.ToolCalls) > 0 {
+		finishReason = finishReasonToolCalls
+	}
+	if output.ResponseMeta != nil && len(output.ResponseMeta.FinishReason) > 0 {
+		finishReason = output.ResponseMeta.FinishReason
+	}
+
+	resp := &ChatCompletion{
+		ID:      newCompletionID(),
+		Object:  objectChatCompletion,
+		Created: time.Now().Unix(),
+		Model:   modelName,
+		Choices: []*Choice{{
+			Index:        0,
+			Message:      FromSchemaMessage(output),
+			FinishReason: finishReason,
+		}},
+		Usage: toUsage(output.ResponseMeta),
+	}
+
+	data, err := sonic.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", fmt.Sprintf("failed to marshal response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (h *handler) serveStream(ctx context.Context, w http.ResponseWriter, req *ChatCompletionRequest, modelName string,
+	input []*schema.Message, opts ...compose.Option) {
+
+	sr, err := h.r.Stream(ctx, input, opts...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	defer sr.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	write := func(v any) error {
+		data, err := sonic.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	var (
+		id           = newCompletionID()
+		created      = time.Now().Unix()
+		conv         = &chunkConverter{}
+		usage        *Usage
+		finishReason string
+	)
+
+	newChunk := func() *ChatCompletionChunk {
+		return &ChatCompletionChunk{
+			ID:      id,
+			Object:  objectChatCompletionChunk,
+			Created: created,
+			Model:   modelName,
+		}
+	}
+
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = write(&ErrorResponse{Error: &ErrorDetail{Message: err.Error(), Type: "server_error"}})
+			return
+		}
+		if msg == nil {
+			continue
+		}
+
+		if u := toUsage(msg.ResponseMeta); u != nil {
+			usage = u
+		}
+		if msg.ResponseMeta != nil && len(msg.ResponseMeta.FinishReason) > 0 {
+			finishReason = msg.ResponseMeta.FinishReason
+		}
+
+		delta := conv.convert(msg)
+		if delta == nil {
+			continue
+		}
+
+		chunk := newChunk()
+		chunk.Choices = []*ChunkChoice{{Index: 0, Delta: delta}}
+		if err = write(chunk); err != nil {
+			return // client has gone away
+		}
+	}
+
+	if len(finishReason) == 0 {
+		finishReason = finishReasonStop
+		if conv.hasToolCalls {
+			finishReason = finishReasonToolCalls
+		}
+	}
+
+	chunk := newChunk()
+	chunk.Choices = []*ChunkChoice{{Index: 0, Delta: &Delta{}, FinishReason: &finishReason}}
+	if err = write(chunk); err != nil {
+		return
+	}
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		chunk = newChunk()
+		chunk.Choices = []*ChunkChoice{}
+		chunk.Usage = usage
+		if chunk.Usage == nil {
+			chunk.Usage = &Usage{}
+		}
+		if err = write(chunk); err != nil {
+			return
+		}
+	}
+
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// chunkConverter converts message chunks to deltas, the role is only sent with the first delta.
+type chunkConverter struct {
+	roleSent     bool
+	hasToolCalls bool
+}
+
+func (c *chunkConverter) convert(msg *schema.Message) *Delta {
+	delta := &Delta{
+		Content: msg.Content,
+	}
+
+	for i := range msg.ToolCalls {
+		tc := toWireToolCall(msg.ToolCalls[i])
+		if tc.Index == nil {
+			index := i
+			tc.Index = &index
+		}
+		delta.ToolCalls = append(delta.ToolCalls, tc)
+	}
+
+	if len(delta.Content) == 0 && len(delta.ToolCalls) == 0 && c.roleSent {
+		return nil
+	}
+
+	if len(delta.ToolCalls) > 0 {
+		c.hasToolCalls = true
+	}
+
+	if !c.roleSent {
+		delta.Role = string(schema.Assistant)
+		c.roleSent = true
+	}
+
+	return delta
+}
+
+func toUsage(meta *schema.ResponseMeta) *Usage {
+	if meta == nil || meta.Usage == nil {
+		return nil
+	}
+
+	return &Usage{
+		PromptTokens:     meta.Usage.PromptTokens,
+		CompletionTokens: meta.Usage.CompletionTokens,
+		TotalTokens:      meta.Usage.TotalTokens,
+	}
+}
+
+func newCompletionID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
+
+func writeError(w http.ResponseWriter, status int, typ, message string) {
+	data, _ := sonic.Marshal(&ErrorResponse{Error: &ErrorDetail{Message: message, Type: typ}})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
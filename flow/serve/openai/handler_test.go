@@ -0,0 +1,237 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+type fakeChatModel struct {
+	lastInput   []*schema.Message
+	lastOptions *model.Options
+	output      []*schema.Message
+}
+
+func (f *fakeChatModel) Generate(_ context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	f.lastInput = input
+	f.lastOptions = model.GetCommonOptions(nil, opts...)
+	return schema.ConcatMessages(f.output)
+}
+
+func (f *fakeChatModel) Stream(_ context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	f.lastInput = input
+	f.lastOptions = model.GetCommonOptions(nil, opts...)
+	return schema.StreamReaderFromArray(f.output), nil
+}
+
+func (f *fakeChatModel) BindTools(_ []*schema.ToolInfo) error {
+	return nil
+}
+
+func newRunnable(t *testing.T, cm model.ChatModel) compose.Runnable[[]*schema.Message, *schema.Message] {
+	r, err := compose.NewChain[[]*schema.Message, *schema.Message]().AppendChatModel(cm).Compile(context.Background())
+	assert.NoError(t, err)
+	return r
+}
+
+func TestChatCompletions(t *testing.T) {
+	cm := &fakeChatModel{
+		output: []*schema.Message{{
+			Role:    schema.Assistant,
+			Content: "hello",
+			ResponseMeta: &schema.ResponseMeta{
+				Usage: &schema.TokenUsage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4},
+			},
+		}},
+	}
+	srv := httptest.NewServer(NewHandler(newRunnable(t, cm), &Config{Model: "agent"}))
+	defer srv.Close()
+
+	body := `{
+		"messages": [
+			{"role": "system", "content": "be nice"},
+			{"role": "user", "content": [{"type": "text", "text": "hi"}, {"type": "image_url", "image_url": {"url": "http://img"}}]},
+			{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "search", "arguments": "{}"}}]},
+			{"role": "tool", "content": "result", "tool_call_id": "call_1"}
+		],
+		"tools": [{"type": "function", "function": {"name": "search", "description": "search the web", "parameters": {"type": "object", "properties": {"q": {"type": "string"}}}}}],
+		"tool_choice": "auto",
+		"temperature": 0.5,
+		"max_tokens": 100,
+		"stop": "END"
+	}`
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	out := &ChatCompletion{}
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp.Body).Decode(out))
+	assert.Equal(t, "chat.completion", out.Object)
+	assert.Equal(t, "agent", out.Model)
+	assert.Equal(t, "hello", out.Choices[0].Message.Content.Text)
+	assert.Equal(t, "stop", out.Choices[0].FinishReason)
+	assert.Equal(t, &Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4}, out.Usage)
+
+	assert.Len(t, cm.lastInput, 4)
+	assert.Equal(t, schema.System, cm.lastInput[0].Role)
+	assert.Len(t, cm.lastInput[1].MultiContent, 2)
+	assert.Equal(t, "http://img", cm.lastInput[1].MultiContent[1].ImageURL.URL)
+	assert.Equal(t, "search", cm.lastInput[2].ToolCalls[0].Function.Name)
+	assert.Equal(t, "call_1", cm.lastInput[3].ToolCallID)
+
+	assert.Equal(t, float32(0.5), *cm.lastOptions.Temperature)
+	assert.Equal(t, 100, *cm.lastOptions.MaxTokens)
+	assert.Equal(t, []string{"END"}, cm.lastOptions.Stop)
+	assert.Equal(t, schema.ToolChoiceAllowed, *cm.lastOptions.ToolChoice)
+	if assert.Len(t, cm.lastOptions.Tools, 1) {
+		sc, err := cm.lastOptions.Tools[0].ToOpenAPIV3()
+		assert.NoError(t, err)
+		assert.Contains(t, sc.Properties, "q")
+	}
+}
+
+func TestChatCompletionsStream(t *testing.T) {
+	idx := 0
+	cm := &fakeChatModel{
+		output: []*schema.Message{
+			{Role: schema.Assistant, Content: "let me check"},
+			{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, ID: "call_1", Function: schema.FunctionCall{Name: "search"}}}},
+			{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, Function: schema.FunctionCall{Arguments: `{"q":"eino"}`}}}},
+			{Role: schema.Assistant, ResponseMeta: &schema.ResponseMeta{Usage: &schema.TokenUsage{TotalTokens: 7}}},
+		},
+	}
+	srv := httptest.NewServer(NewHandler(newRunnable(t, cm), &Config{IgnoreRequestTools: true}))
+	defer srv.Close()
+
+	body := `{"model": "m", "stream": true, "stream_options": {"include_usage": true},
+		"messages": [{"role": "user", "content": "hi"}],
+		"tools": [{"type": "function", "function": {"name": "ignored"}}]}`
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Nil(t, cm.lastOptions.Tools)
+	assert.Nil(t, cm.lastOptions.Model)
+
+	var chunks []*ChatCompletionChunk
+	done := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+		chunk := &ChatCompletionChunk{}
+		assert.NoError(t, sonic.UnmarshalString(data, chunk))
+		chunks = append(chunks, chunk)
+	}
+	assert.True(t, done)
+
+	if !assert.Len(t, chunks, 5) {
+		return
+	}
+	assert.Equal(t, "chat.completion.chunk", chunks[0].Object)
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+	assert.Equal(t, "let me check", chunks[0].Choices[0].Delta.Content)
+	assert.Empty(t, chunks[1].Choices[0].Delta.Role)
+	assert.Equal(t, "call_1", chunks[1].Choices[0].Delta.ToolCalls[0].ID)
+	assert.Equal(t, 0, *chunks[1].Choices[0].Delta.ToolCalls[0].Index)
+	assert.Equal(t, `{"q":"eino"}`, chunks[2].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "tool_calls", *chunks[3].Choices[0].FinishReason)
+	assert.Empty(t, chunks[4].Choices)
+	assert.Equal(t, 7, chunks[4].Usage.TotalTokens)
+}
+
+func TestChatCompletionsForwardModel(t *testing.T) {
+	ctx := context.Background()
+
+	forwarded := &fakeChatModel{output: []*schema.Message{schema.UserMessage("hello")}}
+	other := &fakeChatModel{output: []*schema.Message{schema.AssistantMessage("hello", nil)}}
+	g := compose.NewGraph[[]*schema.Message, *schema.Message]()
+	assert.NoError(t, g.AddChatModelNode("forwarded", forwarded))
+	assert.NoError(t, g.AddLambdaNode("to_messages", compose.InvokableLambda(
+		func(_ context.Context, msg *schema.Message) ([]*schema.Message, error) {
+			return []*schema.Message{msg}, nil
+		})))
+	assert.NoError(t, g.AddChatModelNode("other", other))
+	assert.NoError(t, g.AddEdge(compose.START, "forwarded"))
+	assert.NoError(t, g.AddEdge("forwarded", "to_messages"))
+	assert.NoError(t, g.AddEdge("to_messages", "other"))
+	assert.NoError(t, g.AddEdge("other", compose.END))
+	r, err := g.Compile(ctx)
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(r, &Config{ForwardModelTo: []*compose.NodePath{compose.NewNodePath("forwarded")}}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json",
+		strings.NewReader(`{"model": "gpt-x", "temperature": 0.5, "messages": [{"role": "user", "content": "hi"}]}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	out := &ChatCompletion{}
+	assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp.Body).Decode(out))
+	assert.Equal(t, "gpt-x", out.Model)
+
+	if assert.NotNil(t, forwarded.lastOptions.Model) {
+		assert.Equal(t, "gpt-x", *forwarded.lastOptions.Model)
+	}
+	assert.Nil(t, other.lastOptions.Model)
+	assert.Equal(t, float32(0.5), *other.lastOptions.Temperature)
+}
+
+func TestChatCompletionsBadRequest(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(newRunnable(t, &fakeChatModel{}), nil))
+	defer srv.Close()
+
+	for _, body := range []string{
+		`{`,
+		`{"messages": [{"role": "alien", "content": "hi"}]}`,
+		`{"messages": [{"role": "user", "content": [null]}]}`,
+		`{"messages": [{"role": "user", "content": "hi"}], "tool_choice": {"type": "function", "function": {"name": "missing"}}}`,
+	} {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		out := &ErrorResponse{}
+		assert.NoError(t, sonic.ConfigDefault.NewDecoder(resp.Body).Decode(out))
+		assert.Equal(t, "invalid_request_error", out.Error.Type)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+)
+
+// ChatCompletionRequest is the request body of the chat completions endpoint.
+type ChatCompletionRequest struct {
+	Model         string            `json:"model"`
+	Messages      []*ChatMessage    `json:"messages"`
+	Tools         []*Tool           `json:"tools,omitempty"`
+	ToolChoice    json.RawMessage   `json:"tool_choice,omitempty"`
+	Temperature   *float32          `json:"temperature,omitempty"`
+	TopP          *float32          `json:"top_p,omitempty"`
+	MaxTokens     *int              `json:"max_tokens,omitempty"`
+	Stop          StringOrSlice     `json:"stop,omitempty"`
+	Stream        bool              `json:"stream,omitempty"`
+	StreamOptions *StreamOptions    `json:"stream_options,omitempty"`
+	User          string            `json:"user,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// StreamOptions is the options for stream response.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// ChatMessage is a message of the chat completions protocol.
+type ChatMessage struct {
+	Role       string         `json:"role"`
+	Content    MessageContent `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []*ToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// MessageContent is either a plain string or a list of content parts.
+type MessageContent struct {
+	Text  string
+	Parts []*ContentPart
+}
+
+// MarshalJSON encodes the content as a string, unless it has content parts.
+func (c MessageContent) MarshalJSON() ([]byte, error) {
+	if len(c.Parts) > 0 {
+		return sonic.Marshal(c.Parts)
+	}
+	return sonic.Marshal(c.Text)
+}
+
+// UnmarshalJSON decodes the content from a string, a list of content parts or null.
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	switch data[0] {
+	case '"':
+		return sonic.Unmarshal(data, &c.Text)
+	case '[':
+		return sonic.Unmarshal(data, &c.Parts)
+	default:
+		return fmt.Errorf("unexpected message content: %s", data)
+	}
+}
+
+// ContentPart is a part of a multi-modal message content.
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL is the image of an image_url content part.
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Tool is a tool the model may call.
+type Tool struct {
+	Type     string       `json:"type"`
+	Function *FunctionDef `json:"function"`
+}
+
+// FunctionDef is the definition of a function tool, Parameters is a JSON schema object.
+type FunctionDef struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall is a tool call made by the model.
+type ToolCall struct {
+	Index    *int          `json:"index,omitempty"`
+	ID       string        `json:"id,omitempty"`
+	Type     string        `json:"type,omitempty"`
+	Function *FunctionCall `json:"function,omitempty"`
+}
+
+// FunctionCall is the function name and JSON arguments of a tool call.
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// StringOrSlice is either a single string or a list of strings.
+type StringOrSlice []string
+
+// UnmarshalJSON decodes a single string or a list of strings.
+func (s *StringOrSlice) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if data[0] == '"' {
+		var str string
+		if err := sonic.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		*s = []string{str}
+		return nil
+	}
+
+	var strs []string
+	if err := sonic.Unmarshal(data, &strs); err != nil {
+		return err
+	}
+	*s = strs
+	return nil
+}
+
+// ChatCompletion is the response body of a non-stream request.
+type ChatCompletion struct {
+	ID      string    `json:"id"`
+	Object  string    `json:"object"`
+	Created int64     `json:"created"`
+	Model   string    `json:"model"`
+	Choices []*Choice `json:"choices"`
+	Usage   *Usage    `json:"usage,omitempty"`
+}
+
+// Choice is a completion choice.
+type Choice struct {
+	Index        int          `json:"index"`
+	Message      *ChatMessage `json:"message"`
+	FinishReason string       `json:"finish_reason"`
+}
+
+// ChatCompletionChunk is one server-sent event of a stream request.
+type ChatCompletionChunk struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []*ChunkChoice `json:"choices"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// ChunkChoice is the choice of a completion chunk.
+type ChunkChoice struct {
+	Index        int     `json:"index"`
+	Delta        *Delta  `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// Delta is the incremental message of a completion chunk.
+type Delta struct {
+	Role      string      `json:"role,omitempty"`
+	Content   string      `json:"content,omitempty"`
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Usage is the token usage of a completion.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ErrorResponse is the response body of a failed request.
+type ErrorResponse struct {
+	Error *ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes the error of a failed request.
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}